@@ -21,8 +21,10 @@ var (
 const (
 	TRANSACTION_PREFIX      = "invoke"
 	DECLARE_PREFIX          = "declare"
+	DEPLOY_ACCOUNT_PREFIX   = "deploy_account"
 	EXECUTE_SELECTOR        = "__execute__"
 	CONTRACT_ADDRESS_PREFIX = "STARKNET_CONTRACT_ADDRESS"
+	UDC_ADDRESS             = "0x41a78e741e5af2fec34b695679bc6891742439f7afb8484ecd7766661ad02bf"
 )
 
 type account interface {
@@ -32,7 +34,10 @@ type account interface {
 	EstimateFee(ctx context.Context, calls []types.FunctionCall, details types.ExecuteDetails) (*types.FeeEstimate, error)
 	Execute(ctx context.Context, calls []types.FunctionCall, details types.ExecuteDetails) (*types.AddInvokeTransactionOutput, error)
 	Declare(ctx context.Context, classHash string, contract rpc.DeprecatedContractClass, details types.ExecuteDetails) (types.AddDeclareResponse, error)
+	DeclareV2(ctx context.Context, contract rpc.ContractClass, casm rpc.CasmClass, details types.ExecuteDetails) (types.AddDeclareResponse, error)
 	Deploy(ctx context.Context, classHash string, details types.ExecuteDetails) (*types.AddDeployResponse, error)
+	DeployWithOptions(ctx context.Context, classHash *felt.Felt, opts types.DeployOptions, details types.ExecuteDetails) (*types.AddDeployResponse, error)
+	DeployAccount(ctx context.Context, classHash, salt *felt.Felt, constructorCalldata []*felt.Felt, details types.ExecuteDetails) (*types.AddDeployResponse, error)
 }
 
 var _ account = &Account{}
@@ -55,14 +60,22 @@ type Account struct {
 	chainId        string
 	AccountAddress *felt.Felt
 	sender         *felt.Felt
-	ks             Keystore
+	signer         Signer
 	version        uint64
 	plugin         AccountPlugin
+	nonces         *NonceManager
+	feeStrategy    FeeStrategy
+	encodeCalls    CallEncoder
+	offline        *offlineNonce
 }
 
 type AccountOption struct {
 	AccountPlugin AccountPlugin
 	version       uint64
+	nonceManager  bool
+	feeStrategy   FeeStrategy
+	callEncoder   CallEncoder
+	signer        Signer
 }
 
 type AccountOptionFunc func(*felt.Felt, *felt.Felt) (AccountOption, error)
@@ -79,9 +92,60 @@ func AccountVersion1(*felt.Felt, *felt.Felt) (AccountOption, error) {
 	}, nil
 }
 
+// AccountVersion3 makes the account send version 3 transactions, which pay
+// the fee in STRK within the resource bounds of types.ExecuteDetails. Only the
+// RPC provider supports them.
+func AccountVersion3(*felt.Felt, *felt.Felt) (AccountOption, error) {
+	return AccountOption{
+		version: uint64(3),
+	}, nil
+}
+
+// AccountNonceManager makes the account manage its nonces locally so it can
+// send several transactions in parallel. See NonceManager.
+func AccountNonceManager(*felt.Felt, *felt.Felt) (AccountOption, error) {
+	return AccountOption{
+		nonceManager: true,
+	}, nil
+}
+
+// AccountCallEncoder sets how the calls of Execute are encoded in the
+// __execute__ calldata. The default is EncodeCallsCairo0; use
+// EncodeCallsCairo1 for Cairo 1 accounts.
+func AccountCallEncoder(encoder CallEncoder) AccountOptionFunc {
+	return func(*felt.Felt, *felt.Felt) (AccountOption, error) {
+		if encoder == nil {
+			return AccountOption{}, errors.New("nil call encoder")
+		}
+		return AccountOption{
+			callEncoder: encoder,
+		}, nil
+	}
+}
+
+// AccountSigner makes the account sign its transactions with signer instead
+// of the keystore, e.g. to send the multiple signatures of a multisig account.
+func AccountSigner(signer Signer) AccountOptionFunc {
+	return func(*felt.Felt, *felt.Felt) (AccountOption, error) {
+		if signer == nil {
+			return AccountOption{}, errors.New("nil signer")
+		}
+		return AccountOption{
+			signer: signer,
+		}, nil
+	}
+}
+
 func newAccount(sender, address *felt.Felt, ks Keystore, options ...AccountOptionFunc) (*Account, error) {
 	var accountPlugin AccountPlugin
 	version := uint64(0)
+	nonceManager := false
+	feeStrategy := FeeMultiplier(2, nil)
+	encodeCalls := CallEncoder(EncodeCallsCairo0)
+	var signer Signer
+	if ks != nil {
+		signer = KeystoreSigner(ks)
+	}
 	for _, o := range options {
 		opt, err := o(sender, address)
 		if err != nil {
@@ -90,6 +154,18 @@ func newAccount(sender, address *felt.Felt, ks Keystore, options ...AccountOptio
 		if opt.version != 0 {
 			version = opt.version
 		}
+		if opt.nonceManager {
+			nonceManager = true
+		}
+		if opt.feeStrategy != nil {
+			feeStrategy = opt.feeStrategy
+		}
+		if opt.callEncoder != nil {
+			encodeCalls = opt.callEncoder
+		}
+		if opt.signer != nil {
+			signer = opt.signer
+		}
 		if opt.AccountPlugin != nil {
 			if accountPlugin != nil {
 				return nil, errors.New("multiple plugins not supported")
@@ -97,13 +173,21 @@ func newAccount(sender, address *felt.Felt, ks Keystore, options ...AccountOptio
 			accountPlugin = opt.AccountPlugin
 		}
 	}
-	return &Account{
+	account := &Account{
 		AccountAddress: address,
 		version:        version,
 		plugin:         accountPlugin,
-		ks:             ks,
+		signer:         signer,
 		sender:         sender,
-	}, nil
+		feeStrategy:    feeStrategy,
+		encodeCalls:    encodeCalls,
+	}
+	if nonceManager {
+		account.nonces = NewNonceManager(func(ctx context.Context) (*big.Int, error) {
+			return account.nonce(ctx, "pending")
+		})
+	}
+	return account, nil
 }
 
 func setAccountProvider(account *Account, provider interface{}) error {
@@ -145,6 +229,16 @@ func NewGatewayAccount(sender, address *felt.Felt, ks Keystore, provider *gatewa
 	return account, nil
 }
 
+// SignTypedData signs the message of td for the account, e.g. to log in to a
+// dapp. The signature is checked off-chain with VerifyTypedData.
+func (account *Account) SignTypedData(ctx context.Context, td *TypedData) ([]*felt.Felt, error) {
+	hash, err := td.GetMessageHash(account.AccountAddress.BigInt(big.NewInt(0)), td.Message, Curve)
+	if err != nil {
+		return nil, err
+	}
+	return account.signRequest(ctx, SignRequest{Hash: hash, TypedData: td})
+}
+
 func (account *Account) Call(ctx context.Context, call rpc.FunctionCall) ([]*felt.Felt, error) {
 	switch account.provider {
 	case ProviderRPC:
@@ -172,77 +266,51 @@ func (account *Account) Call(ctx context.Context, call rpc.FunctionCall) ([]*fel
 }
 
 func (account *Account) TransactionHash(calls []types.FunctionCall, details types.ExecuteDetails) (*big.Int, error) {
-
-	var callArray []*big.Int
-	switch account.version {
-	case 1:
-		callArray = fmtCalldata(calls)
-	default:
+	if account.version != 1 {
 		return nil, fmt.Errorf("version %d unsupported", account.version)
 	}
-	cdHash, err := Curve.ComputeHashOnElements(callArray)
-	if err != nil {
-		return nil, err
-	}
-
-	var multiHashData []*big.Int
-	switch account.version {
-	case 1:
-		multiHashData = []*big.Int{
-			types.UTF8StrToBig(TRANSACTION_PREFIX),
-			big.NewInt(int64(account.version)),
-			account.AccountAddress.BigInt(big.NewInt(0)),
-			big.NewInt(0),
-			cdHash,
-			details.MaxFee,
-			types.UTF8StrToBig(account.chainId),
-			details.Nonce,
-		}
-	default:
-		return nil, fmt.Errorf("version %d unsupported", account.version)
-	}
-	return Curve.ComputeHashOnElements(multiHashData)
+	return hashInvoke(big.NewInt(1), account.AccountAddress, account.chainId, account.encodeCalls(calls), details.MaxFee, details.Nonce)
 }
 
 func (account *Account) estimateFeeHash(calls []types.FunctionCall, details types.ExecuteDetails, version *big.Int) (*big.Int, error) {
-	var callArray []*big.Int
-	switch account.version {
-	case 1:
-		callArray = fmtCalldata(calls)
-	default:
+	if account.version != 1 {
 		return nil, fmt.Errorf("version %d unsupported", account.version)
 	}
-	cdHash, err := Curve.ComputeHashOnElements(callArray)
+	return hashInvoke(version, account.AccountAddress, account.chainId, account.encodeCalls(calls), details.MaxFee, details.Nonce)
+}
+
+// hashInvoke computes the hash of an INVOKE v1 transaction of sender sending
+// calldata to its __execute__ entry point. version is 1 or its query version.
+func hashInvoke(version *big.Int, sender *felt.Felt, chainID string, calldata []*big.Int, maxFee, nonce *big.Int) (*big.Int, error) {
+	cdHash, err := Curve.ComputeHashOnElements(calldata)
 	if err != nil {
 		return nil, err
 	}
-	var multiHashData []*big.Int
-	switch account.version {
-	case 1:
-		multiHashData = []*big.Int{
-			types.UTF8StrToBig(TRANSACTION_PREFIX),
-			version,
-			account.AccountAddress.BigInt(big.NewInt(0)),
-			big.NewInt(0),
-			cdHash,
-			details.MaxFee,
-			types.UTF8StrToBig(account.chainId),
-			details.Nonce,
-		}
-	default:
-		return nil, fmt.Errorf("version %d unsupported", account.version)
-	}
-	return Curve.ComputeHashOnElements(multiHashData)
+	return Curve.ComputeHashOnElements([]*big.Int{
+		types.UTF8StrToBig(TRANSACTION_PREFIX),
+		version,
+		sender.BigInt(big.NewInt(0)),
+		big.NewInt(0),
+		cdHash,
+		maxFee,
+		types.UTF8StrToBig(chainID),
+		nonce,
+	})
 }
 
 func (account *Account) Nonce(ctx context.Context) (*big.Int, error) {
+	return account.nonce(ctx, "latest")
+}
+
+// nonce returns the account nonce at the block tag.
+func (account *Account) nonce(ctx context.Context, tag string) (*big.Int, error) {
 	switch account.version {
-	case 1:
+	case 1, 3:
 		switch account.provider {
 		case ProviderRPC:
 			nonce, err := account.rpc.Nonce(
 				ctx,
-				rpc.WithBlockTag("latest"),
+				rpc.WithBlockTag(tag),
 				account.AccountAddress,
 			)
 			if err != nil {
@@ -254,20 +322,96 @@ func (account *Account) Nonce(ctx context.Context) (*big.Int, error) {
 			}
 			return n, nil
 		case ProviderGateway:
-			return account.sequencer.Nonce(ctx, account.AccountAddress.String(), "latest")
+			return account.sequencer.Nonce(ctx, account.AccountAddress.String(), tag)
 		}
 	}
 	return nil, fmt.Errorf("version %d unsupported", account.version)
 }
 
+// NonceManager returns the manager of the account nonces, or nil when the
+// account was not created with AccountNonceManager.
+func (account *Account) NonceManager() *NonceManager {
+	return account.nonces
+}
+
+// currentNonce returns the nonce of the next transaction without reserving
+// it, e.g. to estimate its fee.
+func (account *Account) currentNonce(ctx context.Context) (*big.Int, error) {
+	if account.nonces != nil {
+		return account.nonces.Peek(ctx)
+	}
+	return account.Nonce(ctx)
+}
+
+// reserveNonce returns the nonce to send a transaction with, or nil when the
+// account nonce should be read from the node. release must be called with the
+// outcome of the transaction, the errors of the request sending it marked with
+// sendError, and returns the error for the caller. A managed nonce is
+// confirmed, resynced when the node rejected the transaction or the request
+// sending it failed, or put back for reuse when the transaction failed before
+// being sent.
+func (account *Account) reserveNonce(ctx context.Context, details types.ExecuteDetails) (*big.Int, func(error) error, error) {
+	if details.Nonce != nil || account.nonces == nil {
+		return details.Nonce, unwrapSentError, nil
+	}
+	nonce, err := account.nonces.Next(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	return nonce, func(err error) error {
+		var sent sentError
+		switch {
+		case err == nil:
+			account.nonces.Confirm(nonce)
+		case isNonceRejection(err), errors.As(err, &sent):
+			// the transaction may have reached the node
+			account.nonces.Fail(nonce)
+		default:
+			account.nonces.Release(nonce)
+		}
+		return unwrapSentError(err)
+	}, nil
+}
+
+// signRequest signs the hash of req with the signer of the account. The
+// signer gets the transaction of the hash when it implements RequestSigner.
+func (account *Account) signRequest(ctx context.Context, req SignRequest) ([]*felt.Felt, error) {
+	if account.signer == nil {
+		return nil, errors.New("account has no signer")
+	}
+	req.ID = account.sender.String()
+	req.SenderAddress = account.AccountAddress
+	req.ChainID = account.chainId
+	return requestSignature(ctx, account.signer, req)
+}
+
+// bigSignature converts a signature to the big integers of the gateway types.
+func bigSignature(signature []*felt.Felt) types.Signature {
+	values := make(types.Signature, len(signature))
+	for i, value := range signature {
+		values[i] = value.BigInt(big.NewInt(0))
+	}
+	return values
+}
+
+// decimalSignature converts a signature to the decimal strings of the gateway
+// requests.
+func decimalSignature(signature []*felt.Felt) []string {
+	values := make([]string, len(signature))
+	for i, value := range signature {
+		values[i] = value.Text(10)
+	}
+	return values
+}
+
 func (account *Account) prepFunctionInvokeRPC(ctx context.Context, messageType string, calls []types.FunctionCall, details types.ExecuteDetails) (*rpc.BroadcastedInvokeV1Transaction, error) {
-	if messageType != "invoke" && messageType != "estimate" {
+	if messageType != "invoke" && messageType != "estimate" && messageType != "simulate" {
 		return nil, errors.New("unsupported message type")
 	}
 	nonce := details.Nonce
 	var err error
 	if details.Nonce == nil {
-		nonce, err = account.Nonce(ctx)
+		nonce, err = account.currentNonce(ctx)
 		if err != nil {
 			return nil, err
 		}
@@ -301,11 +445,10 @@ func (account *Account) prepFunctionInvokeRPC(ctx context.Context, messageType s
 		if err != nil {
 			return nil, err
 		}
-	case "estimate":
-		if account.version == 1 {
-			// version, _ = big.NewInt(0).SetString("0x100000000000000000000000000000001", 0)
-			version = rpc.TransactionV1
-		}
+	case "estimate", "simulate":
+		// the estimate is signed with the query version so it can not be
+		// replayed as an invoke transaction
+		version = rpc.TransactionV1WithQueryBit
 		versionBig, err := version.BigInt()
 		if err != nil {
 			return nil, err
@@ -322,40 +465,38 @@ func (account *Account) prepFunctionInvokeRPC(ctx context.Context, messageType s
 			return nil, err
 		}
 	}
-	s1, s2, err := account.ks.Sign(ctx, account.sender.String(), txHash)
+	calldataFelt, err := utils.HexArrToFelt(fmtCalldataStrings(account.encodeCalls(calls)))
+	if err != nil {
+		return nil, err
+	}
+	signature, err := account.signRequest(ctx, SignRequest{
+		Hash:     txHash,
+		Type:     rpc.TransactionType_Invoke,
+		Version:  version,
+		Calls:    calls,
+		Calldata: calldataFelt,
+		MaxFee:   maxFee,
+		Nonce:    nonce,
+	})
 	if err != nil {
 		return nil, err
 	}
 
 	switch account.version {
 	case 1:
-		calldata := fmtCalldataStrings(calls)
-
 		maxFeeFelt, err := new(felt.Felt).SetString(maxFee.String())
 		if err != nil {
 			return nil, err
 		}
-		calldataFelt, err := utils.HexArrToFelt(calldata)
-		if err != nil {
-			return nil, err
-		}
 		nonceFelt, err := utils.BigIntToFelt(nonce)
 		if err != nil {
 			return nil, err
 		}
-		s1Felt, err := utils.BigIntToFelt(s1)
-		if err != nil {
-			return nil, err
-		}
-		s2Felt, err := utils.BigIntToFelt(s2)
-		if err != nil {
-			return nil, err
-		}
 		return &rpc.BroadcastedInvokeV1Transaction{
 			BroadcastedTxnCommonProperties: rpc.BroadcastedTxnCommonProperties{
 				MaxFee:    maxFeeFelt,
 				Version:   version,
-				Signature: []*felt.Felt{s1Felt, s2Felt},
+				Signature: signature,
 				Nonce:     nonceFelt,
 			},
 			SenderAddress: account.AccountAddress,
@@ -372,7 +513,7 @@ func (account *Account) prepFunctionInvoke(ctx context.Context, messageType stri
 	nonce := details.Nonce
 	var err error
 	if details.Nonce == nil {
-		nonce, err = account.Nonce(ctx)
+		nonce, err = account.currentNonce(ctx)
 		if err != nil {
 			return nil, err
 		}
@@ -388,8 +529,7 @@ func (account *Account) prepFunctionInvoke(ctx context.Context, messageType stri
 		}
 		calls = append([]types.FunctionCall{call}, calls...)
 	}
-	// version, _ := big.NewInt(0).SetString("0x100000000000000000000000000000000", 0)
-	version, _ := big.NewInt(0).SetString("0x0", 0)
+	version := big.NewInt(0)
 	var txHash *big.Int
 	switch messageType {
 	case "invoke":
@@ -405,9 +545,12 @@ func (account *Account) prepFunctionInvoke(ctx context.Context, messageType stri
 			return nil, err
 		}
 	case "estimate":
-		if account.version == 1 {
-			// version, _ = big.NewInt(0).SetString("0x100000000000000000000000000000001", 0)
-			version, _ = big.NewInt(0).SetString("0x1", 0)
+		// the estimate is signed with the query version so it can not be
+		// replayed as an invoke transaction
+		queryVersion := rpc.TransactionV1WithQueryBit
+		version, err = queryVersion.BigInt()
+		if err != nil {
+			return nil, err
 		}
 		txHash, err = account.estimateFeeHash(
 			calls,
@@ -421,18 +564,30 @@ func (account *Account) prepFunctionInvoke(ctx context.Context, messageType stri
 			return nil, err
 		}
 	}
-	s1, s2, err := account.ks.Sign(ctx, account.sender.String(), txHash)
+	calldata := fmtCalldataStrings(account.encodeCalls(calls))
+	calldataFelt, err := utils.HexArrToFelt(calldata)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := account.signRequest(ctx, SignRequest{
+		Hash:     txHash,
+		Type:     rpc.TransactionType_Invoke,
+		Version:  rpc.TransactionVersion("0x" + version.Text(16)),
+		Calls:    calls,
+		Calldata: calldataFelt,
+		MaxFee:   maxFee,
+		Nonce:    nonce,
+	})
 	if err != nil {
 		return nil, err
 	}
 
 	switch account.version {
 	case 1:
-		calldata := fmtCalldataStrings(calls)
 		return &types.FunctionInvoke{
 			MaxFee:        maxFee,
 			Version:       version,
-			Signature:     types.Signature{s1, s2},
+			Signature:     bigSignature(signature),
 			SenderAddress: account.AccountAddress,
 			Calldata:      calldata,
 			Nonce:         nonce,
@@ -445,16 +600,16 @@ func (account *Account) EstimateFee(ctx context.Context, calls []types.FunctionC
 
 	switch account.provider {
 	case ProviderRPC:
-		call, err := account.prepFunctionInvokeRPC(ctx, "estimate", calls, details)
-		if err != nil {
-			return nil, err
-		}
 		switch account.version {
 		case 1:
+			call, err := account.prepFunctionInvokeRPC(ctx, "estimate", calls, details)
+			if err != nil {
+				return nil, err
+			}
 			estimates, err := account.rpc.EstimateFee(ctx, []rpc.BroadcastedTransaction{rpc.BroadcastedInvokeV1Transaction{
 				BroadcastedTxnCommonProperties: rpc.BroadcastedTxnCommonProperties{
 					MaxFee:    call.MaxFee,
-					Version:   rpc.TransactionV1,
+					Version:   call.Version,
 					Signature: call.Signature,
 					Nonce:     call.Nonce,
 					Type:      "INVOKE",
@@ -465,7 +620,25 @@ func (account *Account) EstimateFee(ctx context.Context, calls []types.FunctionC
 			if err != nil {
 				return nil, err
 			}
-			return &types.FeeEstimate{types.NumAsHex(estimates[0].GasConsumed), types.NumAsHex(estimates[0].GasPrice), types.NumAsHex(estimates[0].OverallFee)}, nil
+			return &types.FeeEstimate{
+				GasConsumed: types.NumAsHex(estimates[0].GasConsumed),
+				GasPrice:    types.NumAsHex(estimates[0].GasPrice),
+				OverallFee:  types.NumAsHex(estimates[0].OverallFee),
+			}, nil
+		case 3:
+			tx, err := account.prepInvokeV3(ctx, calls, details, true)
+			if err != nil {
+				return nil, err
+			}
+			estimates, err := account.rpc.EstimateFee(ctx, []rpc.BroadcastedTransaction{*tx}, rpc.WithBlockTag("latest"))
+			if err != nil {
+				return nil, err
+			}
+			return &types.FeeEstimate{
+				GasConsumed: types.NumAsHex(estimates[0].GasConsumed),
+				GasPrice:    types.NumAsHex(estimates[0].GasPrice),
+				OverallFee:  types.NumAsHex(estimates[0].OverallFee),
+			}, nil
 		}
 	case ProviderGateway:
 		call, err := account.prepFunctionInvoke(ctx, "estimate", calls, details)
@@ -478,18 +651,33 @@ func (account *Account) EstimateFee(ctx context.Context, calls []types.FunctionC
 }
 
 func (account *Account) Execute(ctx context.Context, calls []types.FunctionCall, details types.ExecuteDetails) (*types.AddInvokeTransactionOutput, error) {
+	nonce, release, err := account.reserveNonce(ctx, details)
+	if err != nil {
+		return nil, err
+	}
+	details.Nonce = nonce
+	output, err := account.execute(ctx, calls, details)
+	return output, release(err)
+}
+
+func (account *Account) execute(ctx context.Context, calls []types.FunctionCall, details types.ExecuteDetails) (*types.AddInvokeTransactionOutput, error) {
+	if account.version == 3 {
+		return account.executeV3(ctx, calls, details)
+	}
 	maxFee := details.MaxFee
+	feeReason := "max fee set in the execute details"
 	if maxFee == nil {
-		estimate, err := account.EstimateFee(ctx, calls, details)
+		var err error
+		maxFee, feeReason, err = account.feeStrategy.MaxFee(ctx, FeeRequest{
+			Account: account,
+			Version: account.version,
+			Estimate: func(ctx context.Context) (*types.FeeEstimate, error) {
+				return account.EstimateFee(ctx, calls, details)
+			},
+		})
 		if err != nil {
 			return nil, err
 		}
-		fmt.Printf("fee %+v\n", estimate)
-		v, ok := big.NewInt(0).SetString(string(estimate.OverallFee), 0)
-		if !ok {
-			return nil, errors.New("could not match OverallFee to big.Int")
-		}
-		maxFee = v.Mul(v, big.NewInt(2))
 	}
 	details.MaxFee = maxFee
 
@@ -513,131 +701,342 @@ func (account *Account) Execute(ctx context.Context, calls []types.FunctionCall,
 				Calldata:      call.Calldata,
 			})
 			if err != nil {
-				return nil, err
+				return nil, sendError(err)
 			}
-			return &types.AddInvokeTransactionOutput{resp.TransactionHash}, nil
+			return &types.AddInvokeTransactionOutput{
+				TransactionHash: resp.TransactionHash,
+				MaxFee:          maxFee,
+				FeeReason:       feeReason,
+			}, nil
 		}
 	case ProviderGateway:
 		call, err := account.prepFunctionInvoke(ctx, "invoke", calls, details)
 		if err != nil {
 			return nil, err
 		}
-		return account.sequencer.Invoke(
+		output, err := account.sequencer.Invoke(
 			context.Background(),
 			*call,
 		)
+		if err != nil {
+			return nil, sendError(err)
+		}
+		output.MaxFee = maxFee
+		output.FeeReason = feeReason
+		return output, nil
 	}
 	return nil, ErrUnsupportedAccount
 }
 
+// declareErrorCodes maps the RPC errors of starknet_addDeclareTransaction to
+// the StarknetErrorCode returned by the gateway for the same failure.
+var declareErrorCodes = map[*rpc.RPCError]string{
+	rpc.ErrClassAlreadyDeclared:            "CLASS_ALREADY_DECLARED",
+	rpc.ErrCompilationFailed:               "COMPILATION_FAILED",
+	rpc.ErrCompiledClassHashMismatch:       "INVALID_COMPILED_CLASS_HASH",
+	rpc.ErrInsufficientAccountBalance:      "INSUFFICIENT_ACCOUNT_BALANCE",
+	rpc.ErrInsufficientMaxFee:              "INSUFFICIENT_MAX_FEE",
+	rpc.ErrInvalidTransactionNonce:         "INVALID_TRANSACTION_NONCE",
+	rpc.ErrValidationFailure:               "VALIDATE_FAILURE",
+	rpc.ErrNonAccount:                      "NON_ACCOUNT",
+	rpc.ErrDuplicateTx:                     "DUPLICATED_TRANSACTION",
+	rpc.ErrContractClassSizeTooLarge:       "CONTRACT_CLASS_OBJECT_SIZE_TOO_LARGE",
+	rpc.ErrUnsupportedTxVersion:            "INVALID_TRANSACTION_VERSION",
+	rpc.ErrUnsupportedContractClassVersion: "INVALID_CONTRACT_CLASS_VERSION",
+}
+
+// declareErrorCode returns the code matching a declare error, or an empty
+// string when the error is not a known declare failure.
+func declareErrorCode(err error) string {
+	var rpcErr *rpc.RPCError
+	if !errors.As(err, &rpcErr) {
+		return ""
+	}
+	return declareErrorCodes[rpcErr]
+}
+
+// declareHash computes the hash of a DECLARE transaction. compiledClassHash
+// is only part of the hash from version 2.
+func (account *Account) declareHash(version uint64, classHash, compiledClassHash, maxFee, nonce *big.Int) (*big.Int, error) {
+	return hashDeclare(version, account.AccountAddress, account.chainId, classHash, compiledClassHash, maxFee, nonce)
+}
+
+// hashDeclare computes the hash of a DECLARE v1 or v2 transaction of sender.
+func hashDeclare(version uint64, sender *felt.Felt, chainID string, classHash, compiledClassHash, maxFee, nonce *big.Int) (*big.Int, error) {
+	calldataHash, err := Curve.ComputeHashOnElements([]*big.Int{classHash})
+	if err != nil {
+		return nil, err
+	}
+	multiHashData := []*big.Int{
+		types.UTF8StrToBig(DECLARE_PREFIX),
+		new(big.Int).SetUint64(version),
+		sender.BigInt(big.NewInt(0)),
+		big.NewInt(0),
+		calldataHash,
+		maxFee,
+		types.UTF8StrToBig(chainID),
+		nonce,
+	}
+	switch version {
+	case 1:
+	case 2:
+		multiHashData = append(multiHashData, compiledClassHash)
+	default:
+		return nil, fmt.Errorf("version %d unsupported", version)
+	}
+	return Curve.ComputeHashOnElements(multiHashData)
+}
+
 func (account *Account) Declare(ctx context.Context, classHash string, contract rpc.DeprecatedContractClass, details types.ExecuteDetails) (types.AddDeclareResponse, error) {
-	switch account.provider {
-	case ProviderRPC:
-		panic("unsupported")
-	case ProviderGateway:
-		version := big.NewInt(1)
-		nonce := details.Nonce
-		var err error
-		if details.Nonce == nil {
-			nonce, err = account.Nonce(ctx)
-			if err != nil {
-				return types.AddDeclareResponse{}, err
-			}
-		}
-		// TODO: use max fee estimation instead
-		maxFee := MAX_FEE
-		if details.MaxFee != nil {
-			maxFee = details.MaxFee
-		}
+	nonce, release, err := account.reserveNonce(ctx, details)
+	if err != nil {
+		return types.AddDeclareResponse{}, err
+	}
+	details.Nonce = nonce
+	resp, err := account.declare(ctx, classHash, contract, details)
+	return resp, release(err)
+}
 
-		// TODO: extract as declareHash
-		hash, _ := big.NewInt(0).SetString(classHash, 0)
-		calldataHash, err := Curve.ComputeHashOnElements([]*big.Int{hash})
+func (account *Account) declare(ctx context.Context, classHash string, contract rpc.DeprecatedContractClass, details types.ExecuteDetails) (types.AddDeclareResponse, error) {
+	if account.provider != ProviderRPC && account.provider != ProviderGateway {
+		return types.AddDeclareResponse{}, ErrUnsupportedAccount
+	}
+	nonce := details.Nonce
+	var err error
+	if details.Nonce == nil {
+		nonce, err = account.currentNonce(ctx)
 		if err != nil {
 			return types.AddDeclareResponse{}, err
 		}
-		var multiHashData []*big.Int
-		switch account.version {
-		case 1:
-			multiHashData = []*big.Int{
-				types.UTF8StrToBig(DECLARE_PREFIX),
-				version,
-				account.AccountAddress.BigInt(big.NewInt(0)),
-				big.NewInt(0),
-				calldataHash,
-				maxFee,
-				types.UTF8StrToBig(account.chainId),
-				nonce, // TODO: also include compiledClassHash for cairo 1.0?
-			}
-		default:
-			return types.AddDeclareResponse{}, fmt.Errorf("version %d unsupported", account.version)
-		}
-		txHash, err := Curve.ComputeHashOnElements(multiHashData)
-		// TODO: end extract as declareHash
+	}
+	// TODO: use max fee estimation instead
+	maxFee := MAX_FEE
+	if details.MaxFee != nil {
+		maxFee = details.MaxFee
+	}
+	hash, ok := big.NewInt(0).SetString(classHash, 0)
+	if !ok {
+		return types.AddDeclareResponse{}, fmt.Errorf("invalid class hash %q", classHash)
+	}
+	if account.version != 1 {
+		return types.AddDeclareResponse{}, fmt.Errorf("version %d unsupported", account.version)
+	}
+	txHash, err := account.declareHash(1, hash, nil, maxFee, nonce)
+	if err != nil {
+		return types.AddDeclareResponse{}, err
+	}
+	classHashFelt, err := utils.BigIntToFelt(hash)
+	if err != nil {
+		return types.AddDeclareResponse{}, err
+	}
+	signature, err := account.signRequest(ctx, SignRequest{
+		Hash:      txHash,
+		Type:      rpc.TransactionType_Declare,
+		Version:   rpc.TransactionV1,
+		ClassHash: classHashFelt,
+		MaxFee:    maxFee,
+		Nonce:     nonce,
+	})
+	if err != nil {
+		return types.AddDeclareResponse{}, err
+	}
+
+	switch account.provider {
+	case ProviderRPC:
+		maxFeeFelt, err := utils.BigIntToFelt(maxFee)
 		if err != nil {
 			return types.AddDeclareResponse{}, err
 		}
-
-		s1, s2, err := account.ks.Sign(ctx, account.sender.String(), txHash)
+		nonceFelt, err := utils.BigIntToFelt(nonce)
 		if err != nil {
 			return types.AddDeclareResponse{}, err
 		}
-		signature := []string{}
-		signature = append(signature, s1.String())
-		signature = append(signature, s2.String())
-
+		resp, err := account.rpc.AddDeclareTransaction(ctx, rpc.BroadcastedDeclareTransactionV1{
+			BroadcastedTxnCommonProperties: rpc.BroadcastedTxnCommonProperties{
+				MaxFee:    maxFeeFelt,
+				Version:   rpc.TransactionV1,
+				Signature: signature,
+				Nonce:     nonceFelt,
+				Type:      "DECLARE",
+			},
+			ContractClass: contract,
+			SenderAddress: account.AccountAddress,
+		})
+		if err != nil {
+			return types.AddDeclareResponse{
+				Code:      declareErrorCode(err),
+				ClassHash: fmt.Sprintf("0x%x", hash),
+			}, sendError(err)
+		}
+		return types.AddDeclareResponse{
+			Code:            "TRANSACTION_RECEIVED",
+			TransactionHash: resp.TransactionHash.String(),
+			ClassHash:       resp.ClassHash.String(),
+		}, nil
+	default:
 		request := gateway.DeclareRequest{
 			SenderAddress: account.AccountAddress,
-			Version:       fmt.Sprintf("0x%x", version),
+			Version:       "0x1",
 			MaxFee:        fmt.Sprintf("0x%x", maxFee),
 			Nonce:         fmt.Sprintf("0x%x", nonce),
-			Signature:     signature,
+			Signature:     decimalSignature(signature),
 			ContractClass: contract,
 			Type:          "DECLARE",
 		}
-		return account.sequencer.Declare(ctx, contract, request)
+		resp, err := account.sequencer.Declare(ctx, contract, request)
+		return resp, sendError(err)
 	}
-	return types.AddDeclareResponse{}, ErrUnsupportedAccount
 }
 
-// Deploys a declared contract using the UDC.
-// TODO: use types.DeployRequest{} as input for salt + calldata (remove contract_definition)
-func (account *Account) Deploy(ctx context.Context, classHash string, details types.ExecuteDetails) (*types.AddDeployResponse, error) {
-	// TODO: allow passing salt in
-	salt, err := Curve.GetRandomPrivateKey()
+// DeclareV2 declares a Cairo 1 (Sierra) class with a DECLARE v2 transaction,
+// or a DECLARE v3 transaction with AccountVersion3. The class hash and the
+// compiled class hash are computed from contract and its compiled form casm.
+// Only the RPC provider supports it.
+func (account *Account) DeclareV2(ctx context.Context, contract rpc.ContractClass, casm rpc.CasmClass, details types.ExecuteDetails) (types.AddDeclareResponse, error) {
+	nonce, release, err := account.reserveNonce(ctx, details)
 	if err != nil {
-		return nil, err
+		return types.AddDeclareResponse{}, err
 	}
+	details.Nonce = nonce
+	resp, err := account.declareV2(ctx, contract, casm, details)
+	return resp, release(err)
+}
 
-	unique := true
-	calldata := []string{}
-
-	uniqueInt := big.NewInt(0)
-	if unique {
-		uniqueInt = big.NewInt(1)
+func (account *Account) declareV2(ctx context.Context, contract rpc.ContractClass, casm rpc.CasmClass, details types.ExecuteDetails) (types.AddDeclareResponse, error) {
+	if account.provider != ProviderRPC {
+		return types.AddDeclareResponse{}, ErrUnsupportedAccount
+	}
+	var tx rpc.BroadcastedDeclareTransaction
+	var classHash *felt.Felt
+	var err error
+	switch account.version {
+	case 1:
+		tx, classHash, err = account.signDeclareV2(ctx, contract, casm, details)
+	case 3:
+		tx, classHash, err = account.signDeclareV3(ctx, contract, casm, details)
+	default:
+		return types.AddDeclareResponse{}, fmt.Errorf("version %d unsupported", account.version)
+	}
+	if err != nil {
+		return types.AddDeclareResponse{}, err
+	}
+	resp, err := account.rpc.AddDeclareTransaction(ctx, tx)
+	if err != nil {
+		return types.AddDeclareResponse{
+			Code:      declareErrorCode(err),
+			ClassHash: classHash.String(),
+		}, sendError(err)
 	}
+	return types.AddDeclareResponse{
+		Code:            "TRANSACTION_RECEIVED",
+		TransactionHash: resp.TransactionHash.String(),
+		ClassHash:       resp.ClassHash.String(),
+	}, nil
+}
 
-	deployerAddress, err := utils.HexToFelt("0x41a78e741e5af2fec34b695679bc6891742439f7afb8484ecd7766661ad02bf") // UDC
+// signDeclareV2 returns the signed DECLARE v2 transaction of contract and its
+// class hash.
+func (account *Account) signDeclareV2(ctx context.Context, contract rpc.ContractClass, casm rpc.CasmClass, details types.ExecuteDetails) (rpc.BroadcastedDeclareTransactionV2, *felt.Felt, error) {
+	nonce := details.Nonce
+	var err error
+	if details.Nonce == nil {
+		nonce, err = account.currentNonce(ctx)
+		if err != nil {
+			return rpc.BroadcastedDeclareTransactionV2{}, nil, err
+		}
+	}
+	// TODO: use max fee estimation instead
+	maxFee := MAX_FEE
+	if details.MaxFee != nil {
+		maxFee = details.MaxFee
+	}
+	classHash := ClassHash(contract)
+	compiledClassHash, err := CompiledClassHash(casm)
 	if err != nil {
-		return nil, err
+		return rpc.BroadcastedDeclareTransactionV2{}, nil, err
 	}
-	classhHashFelt, err := utils.HexToFelt(classHash)
+	txHash, err := account.declareHash(
+		2,
+		classHash.BigInt(big.NewInt(0)),
+		compiledClassHash.BigInt(big.NewInt(0)),
+		maxFee,
+		nonce,
+	)
 	if err != nil {
-		return nil, err
+		return rpc.BroadcastedDeclareTransactionV2{}, nil, err
 	}
-	saltFelt, err := utils.BigIntToFelt(salt)
+	signature, err := account.signRequest(ctx, SignRequest{
+		Hash:              txHash,
+		Type:              rpc.TransactionType_Declare,
+		Version:           rpc.TransactionV2,
+		ClassHash:         classHash,
+		CompiledClassHash: compiledClassHash,
+		MaxFee:            maxFee,
+		Nonce:             nonce,
+	})
 	if err != nil {
-		return nil, err
+		return rpc.BroadcastedDeclareTransactionV2{}, nil, err
 	}
-	uniqueIntFelt, err := utils.BigIntToFelt(uniqueInt)
+	maxFeeFelt, err := utils.BigIntToFelt(maxFee)
 	if err != nil {
-		return nil, err
+		return rpc.BroadcastedDeclareTransactionV2{}, nil, err
 	}
-	lenCalldataFelt, err := utils.HexToFelt(fmt.Sprintf("0x%x", len(calldata)))
+	nonceFelt, err := utils.BigIntToFelt(nonce)
+	if err != nil {
+		return rpc.BroadcastedDeclareTransactionV2{}, nil, err
+	}
+	return rpc.BroadcastedDeclareTransactionV2{
+		BroadcastedTxnCommonProperties: rpc.BroadcastedTxnCommonProperties{
+			MaxFee:    maxFeeFelt,
+			Version:   rpc.TransactionV2,
+			Signature: signature,
+			Nonce:     nonceFelt,
+			Type:      "DECLARE",
+		},
+		ContractClass:     contract,
+		SenderAddress:     account.AccountAddress,
+		CompiledClassHash: compiledClassHash,
+	}, classHash, nil
+}
+
+// Deploys a declared contract using the UDC, with a random salt, unique set
+// and no constructor calldata.
+func (account *Account) Deploy(ctx context.Context, classHash string, details types.ExecuteDetails) (*types.AddDeployResponse, error) {
+	classHashFelt, err := utils.HexToFelt(classHash)
 	if err != nil {
 		return nil, err
 	}
-	calldataFelt, err := utils.HexArrToFelt(calldata)
+	return account.DeployWithOptions(ctx, classHashFelt, types.DeployOptions{Unique: true}, details)
+}
+
+// DeployWithOptions deploys a declared contract with the UDC, or with
+// opts.DeployerAddress when set, and returns the resulting contract address.
+func (account *Account) DeployWithOptions(ctx context.Context, classHash *felt.Felt, opts types.DeployOptions, details types.ExecuteDetails) (*types.AddDeployResponse, error) {
+	salt := opts.Salt
+	if salt == nil {
+		random, err := Curve.GetRandomPrivateKey()
+		if err != nil {
+			return nil, err
+		}
+		salt, err = utils.BigIntToFelt(random)
+		if err != nil {
+			return nil, err
+		}
+	}
+	deployerAddress := opts.DeployerAddress
+	if deployerAddress == nil {
+		var err error
+		deployerAddress, err = utils.HexToFelt(UDC_ADDRESS)
+		if err != nil {
+			return nil, err
+		}
+	}
+	unique := new(felt.Felt)
+	if opts.Unique {
+		unique.SetUint64(1)
+	}
+
+	contractAddress, err := account.udcContractAddress(deployerAddress, classHash, salt, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -646,52 +1045,189 @@ func (account *Account) Deploy(ctx context.Context, classHash string, details ty
 			ContractAddress:    deployerAddress,
 			EntryPointSelector: types.GetSelectorFromNameFelt("deployContract"),
 			Calldata: append([]*felt.Felt{
-				classhHashFelt,
-				saltFelt,
-				uniqueIntFelt, // unique
-				lenCalldataFelt,
-			}, calldataFelt...),
+				classHash,
+				salt,
+				unique,
+				new(felt.Felt).SetUint64(uint64(len(opts.ConstructorCalldata))),
+			}, opts.ConstructorCalldata...),
 		},
 	}, details)
 	if err != nil {
 		return nil, err
 	}
 
-	// Calculate the resulting contract address
-	constructorCalldata := []*big.Int{}
-	for _, value := range calldata {
-		constructorCalldata = append(constructorCalldata, types.SNValToBN(value))
-	}
-	constructorCalldataHash, err := Curve.ComputeHashOnElements(constructorCalldata)
-	if err != nil {
-		return nil, err
-	}
+	return &types.AddDeployResponse{
+		TransactionHash: tx.TransactionHash.String(),
+		ContractAddress: fmt.Sprintf("0x%x", contractAddress),
+	}, nil
+}
 
-	if unique {
-		salt, err = Curve.PedersenHash([]*big.Int{
+// udcContractAddress computes the address of a contract deployed by the UDC.
+// A unique deployment salts with the account address and uses the deployer
+// address; otherwise the address is the one of a deployment from 0.
+func (account *Account) udcContractAddress(deployerAddress, classHash, salt *felt.Felt, opts types.DeployOptions) (*big.Int, error) {
+	addressSalt := salt.BigInt(big.NewInt(0))
+	deployer := big.NewInt(0)
+	if opts.Unique {
+		var err error
+		addressSalt, err = Curve.PedersenHash([]*big.Int{
 			account.AccountAddress.BigInt(big.NewInt(0)),
-			salt,
+			addressSalt,
 		})
 		if err != nil {
 			return nil, err
 		}
+		deployer = deployerAddress.BigInt(big.NewInt(0))
 	}
+	calldata := make([]*big.Int, len(opts.ConstructorCalldata))
+	for i, value := range opts.ConstructorCalldata {
+		calldata[i] = value.BigInt(big.NewInt(0))
+	}
+	return ContractAddress(deployer, addressSalt, classHash.BigInt(big.NewInt(0)), calldata)
+}
 
-	prefix := types.HexToBN("0x535441524b4e45545f434f4e54524143545f41444452455353")
+// deployAccountHash computes the hash of a DEPLOY_ACCOUNT transaction for the
+// account address.
+func (account *Account) deployAccountHash(classHash, salt *felt.Felt, constructorCalldata []*felt.Felt, maxFee, nonce *big.Int) (*big.Int, error) {
+	if account.version != 1 {
+		return nil, fmt.Errorf("version %d unsupported", account.version)
+	}
+	return hashDeployAccount(account.AccountAddress, account.chainId, classHash, salt, constructorCalldata, maxFee, nonce)
+}
 
-	contractAddress, err := Curve.ComputeHashOnElements([]*big.Int{
-		prefix,                                // CONTRACT_ADDRESS_PREFIX
-		deployerAddress.BigInt(big.NewInt(0)), // TODO: 0 if !unique
-		salt,
-		types.HexToBN(classHash),
-		constructorCalldataHash,
+// hashDeployAccount computes the hash of a DEPLOY_ACCOUNT v1 transaction
+// deploying the account at address.
+func hashDeployAccount(address *felt.Felt, chainID string, classHash, salt *felt.Felt, constructorCalldata []*felt.Felt, maxFee, nonce *big.Int) (*big.Int, error) {
+	calldata := []*big.Int{classHash.BigInt(big.NewInt(0)), salt.BigInt(big.NewInt(0))}
+	for _, value := range constructorCalldata {
+		calldata = append(calldata, value.BigInt(big.NewInt(0)))
+	}
+	calldataHash, err := Curve.ComputeHashOnElements(calldata)
+	if err != nil {
+		return nil, err
+	}
+	return Curve.ComputeHashOnElements([]*big.Int{
+		types.UTF8StrToBig(DEPLOY_ACCOUNT_PREFIX),
+		big.NewInt(1),
+		address.BigInt(big.NewInt(0)),
+		big.NewInt(0),
+		calldataHash,
+		maxFee,
+		types.UTF8StrToBig(chainID),
+		nonce,
 	})
+}
+
+// DeployAccount deploys the account itself with a DEPLOY_ACCOUNT transaction.
+// The account address must be the counterfactual address returned by
+// PrecomputeAccountAddress for the same class hash, salt and calldata, and it
+// must be funded beforehand to pay for the fee.
+func (account *Account) DeployAccount(ctx context.Context, classHash, salt *felt.Felt, constructorCalldata []*felt.Felt, details types.ExecuteDetails) (*types.AddDeployResponse, error) {
+	if account.provider != ProviderRPC && account.provider != ProviderGateway {
+		return nil, ErrUnsupportedAccount
+	}
+	address, err := PrecomputeAccountAddress(salt, classHash, constructorCalldata)
+	if err != nil {
+		return nil, err
+	}
+	if !address.Equal(account.AccountAddress) {
+		return nil, fmt.Errorf("account address %s does not match the deployed address %s", account.AccountAddress, address)
+	}
+	// the account does not exist yet, its nonce is 0
+	nonce := big.NewInt(0)
+	if details.Nonce != nil {
+		nonce = details.Nonce
+	}
+	details.Nonce = nonce
+	if account.version == 3 {
+		return account.deployAccountV3(ctx, classHash, salt, constructorCalldata, details)
+	}
+	tx, err := account.signDeployAccount(ctx, classHash, salt, constructorCalldata, details)
 	if err != nil {
 		return nil, err
 	}
 
-	return &types.AddDeployResponse{
-		TransactionHash: tx.TransactionHash.String(),
-		ContractAddress: fmt.Sprintf("0x%x", contractAddress),
+	switch account.provider {
+	case ProviderRPC:
+		resp, err := account.rpc.AddDeployAccountTransaction(ctx, tx)
+		if err != nil {
+			return nil, err
+		}
+		return &types.AddDeployResponse{
+			Code:            "TRANSACTION_RECEIVED",
+			TransactionHash: resp.TransactionHash.String(),
+			ContractAddress: resp.ContractAddress.String(),
+		}, nil
+	default:
+		resp, err := account.sequencer.DeployAccount(ctx, gatewayDeployAccountRequest(tx))
+		if err != nil {
+			return nil, err
+		}
+		return &resp, nil
+	}
+}
+
+// signDeployAccount returns the signed DEPLOY_ACCOUNT v1 transaction of the
+// account. details.Nonce must be set.
+func (account *Account) signDeployAccount(ctx context.Context, classHash, salt *felt.Felt, constructorCalldata []*felt.Felt, details types.ExecuteDetails) (rpc.BroadcastedDeployAccountTransaction, error) {
+	// TODO: use max fee estimation instead
+	maxFee := MAX_FEE
+	if details.MaxFee != nil {
+		maxFee = details.MaxFee
+	}
+	txHash, err := account.deployAccountHash(classHash, salt, constructorCalldata, maxFee, details.Nonce)
+	if err != nil {
+		return rpc.BroadcastedDeployAccountTransaction{}, err
+	}
+	signature, err := account.signRequest(ctx, SignRequest{
+		Hash:                txHash,
+		Type:                rpc.TransactionType_DeployAccount,
+		Version:             rpc.TransactionV1,
+		Calldata:            constructorCalldata,
+		ClassHash:           classHash,
+		ContractAddressSalt: salt,
+		MaxFee:              maxFee,
+		Nonce:               details.Nonce,
+	})
+	if err != nil {
+		return rpc.BroadcastedDeployAccountTransaction{}, err
+	}
+	maxFeeFelt, err := utils.BigIntToFelt(maxFee)
+	if err != nil {
+		return rpc.BroadcastedDeployAccountTransaction{}, err
+	}
+	nonceFelt, err := utils.BigIntToFelt(details.Nonce)
+	if err != nil {
+		return rpc.BroadcastedDeployAccountTransaction{}, err
+	}
+	return rpc.BroadcastedDeployAccountTransaction{
+		BroadcastedTxnCommonProperties: rpc.BroadcastedTxnCommonProperties{
+			MaxFee:    maxFeeFelt,
+			Version:   rpc.TransactionV1,
+			Signature: signature,
+			Nonce:     nonceFelt,
+			Type:      "DEPLOY_ACCOUNT",
+		},
+		ContractAddressSalt: salt,
+		ConstructorCalldata: constructorCalldata,
+		ClassHash:           classHash,
 	}, nil
 }
+
+// gatewayDeployAccountRequest converts a DEPLOY_ACCOUNT v1 transaction to its
+// gateway request.
+func gatewayDeployAccountRequest(tx rpc.BroadcastedDeployAccountTransaction) types.DeployAccountRequest {
+	calldata := make([]string, len(tx.ConstructorCalldata))
+	for i, value := range tx.ConstructorCalldata {
+		calldata[i] = value.String()
+	}
+	return types.DeployAccountRequest{
+		MaxFee:              tx.MaxFee.BigInt(big.NewInt(0)),
+		Version:             big.NewInt(1),
+		Signature:           bigSignature(tx.Signature),
+		Nonce:               tx.Nonce.BigInt(big.NewInt(0)),
+		ContractAddressSalt: tx.ContractAddressSalt.String(),
+		ConstructorCalldata: calldata,
+		ClassHash:           tx.ClassHash.String(),
+	}
+}
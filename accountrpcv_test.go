@@ -2,13 +2,17 @@ package starknetgo
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"math/big"
 	"os"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/NethermindEth/juno/core/crypto"
 	"github.com/NethermindEth/juno/core/felt"
 	rpc "github.com/sjxqqq/starknet-go/rpc"
 	"github.com/sjxqqq/starknet-go/types"
@@ -166,3 +170,506 @@ func TestRPCAccount_Execute(t *testing.T) {
 		}
 	}
 }
+
+// TestRPCAccount_ExecuteCallEncoder checks the selected call encoding is used
+// for both the signed hash and the broadcast calldata
+func TestRPCAccount_ExecuteCallEncoder(t *testing.T) {
+	type testSetType struct {
+		Options  []AccountOptionFunc
+		Expected CallEncoder
+	}
+	testSet := map[string][]testSetType{
+		"mock": {
+			{
+				Options:  []AccountOptionFunc{AccountVersion1},
+				Expected: EncodeCallsCairo0,
+			},
+			{
+				Options:  []AccountOptionFunc{AccountVersion1, AccountCallEncoder(EncodeCallsCairo1)},
+				Expected: EncodeCallsCairo1,
+			},
+		},
+	}[testEnv]
+
+	calls := []types.FunctionCall{
+		{
+			ContractAddress:    utils.TestHexToFelt(t, mockAccountAddress),
+			EntryPointSelector: types.GetSelectorFromNameFelt("increment"),
+		},
+		{
+			ContractAddress:    utils.TestHexToFelt(t, mockAccountAddress),
+			EntryPointSelector: types.GetSelectorFromNameFelt("set_count"),
+			Calldata:           []*felt.Felt{utils.TestHexToFelt(t, "0x2a")},
+		},
+	}
+	for _, test := range testSet {
+		account, mock := newMockRPCAccount(t, test.Options...)
+		mock.nonce = 3
+		_, err := account.Execute(context.Background(), calls, types.ExecuteDetails{MaxFee: big.NewInt(1000)})
+		if err != nil {
+			t.Fatal("execute should succeed, instead:", err)
+		}
+		var tx rpc.BroadcastedInvokeV1Transaction
+		mock.lastReceived(t, &tx)
+		expected := test.Expected(calls)
+		if len(tx.Calldata) != len(expected) {
+			t.Fatalf("calldata should have %d elements, instead: %d", len(expected), len(tx.Calldata))
+		}
+		for i, data := range tx.Calldata {
+			if data.BigInt(big.NewInt(0)).Cmp(expected[i]) != 0 {
+				t.Fatalf("calldata[%d] should be 0x%x, instead: %s", i, expected[i], data)
+			}
+		}
+		cdHash, err := Curve.ComputeHashOnElements(expected)
+		if err != nil {
+			t.Fatal(err)
+		}
+		hash, err := Curve.ComputeHashOnElements([]*big.Int{
+			types.UTF8StrToBig(TRANSACTION_PREFIX),
+			big.NewInt(1),
+			types.HexToBN(mockAccountAddress),
+			big.NewInt(0),
+			cdHash,
+			big.NewInt(1000),
+			types.UTF8StrToBig("SN_GOERLI"),
+			big.NewInt(3),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		verifyMockSignature(t, hash, tx.Signature)
+	}
+
+	if _, err := newAccount(nil, nil, nil, AccountCallEncoder(nil)); err == nil {
+		t.Fatal("nil call encoder should fail")
+	}
+}
+
+// TestRPCAccount_Declare tests the account Declare method with the mock node
+func TestRPCAccount_Declare(t *testing.T) {
+	type testSetType struct {
+		MockError         error
+		ExpectedCode      string
+		ExpectedError     error
+		ExpectedTxHash    string
+		ExpectedClassHash string
+	}
+	testSet := map[string][]testSetType{
+		"mock": {
+			{
+				ExpectedCode:      "TRANSACTION_RECEIVED",
+				ExpectedTxHash:    mockTransactionHash,
+				ExpectedClassHash: "0x1",
+			},
+			{
+				MockError:         rpcMockError{code: 51, message: "Class already declared"},
+				ExpectedCode:      "CLASS_ALREADY_DECLARED",
+				ExpectedError:     rpc.ErrClassAlreadyDeclared,
+				ExpectedClassHash: "0x1",
+			},
+			{
+				MockError:         rpcMockError{code: 52, message: "Invalid transaction nonce"},
+				ExpectedCode:      "INVALID_TRANSACTION_NONCE",
+				ExpectedError:     rpc.ErrInvalidTransactionNonce,
+				ExpectedClassHash: "0x1",
+			},
+		},
+	}[testEnv]
+
+	for _, test := range testSet {
+		account, mock := newMockRPCAccount(t, AccountVersion1)
+		mock.nonce = 3
+		mock.err = test.MockError
+		class := rpc.DeprecatedContractClass{Program: "H4sIAAAAAAAA/6quBQQAAP//Q7+m1wIAAAA="}
+		resp, err := account.Declare(context.Background(), "0x1", class, types.ExecuteDetails{})
+		if !errors.Is(err, test.ExpectedError) {
+			t.Fatalf("error should be %v, instead: %v", test.ExpectedError, err)
+		}
+		if resp.Code != test.ExpectedCode {
+			t.Fatalf("code should be %s, instead: %s", test.ExpectedCode, resp.Code)
+		}
+		if resp.ClassHash != test.ExpectedClassHash {
+			t.Fatalf("class hash should be %s, instead: %s", test.ExpectedClassHash, resp.ClassHash)
+		}
+		if test.MockError != nil {
+			continue
+		}
+		if resp.TransactionHash != test.ExpectedTxHash {
+			t.Fatalf("transaction hash should be %s, instead: %s", test.ExpectedTxHash, resp.TransactionHash)
+		}
+		var tx rpc.BroadcastedDeclareTransactionV1
+		mock.lastReceived(t, &tx)
+		if tx.Version != rpc.TransactionV1 || tx.Nonce.String() != "0x3" {
+			t.Fatalf("unexpected declare version %s or nonce %s", tx.Version, tx.Nonce)
+		}
+		hash, err := account.declareHash(1, big.NewInt(1), nil, tx.MaxFee.BigInt(big.NewInt(0)), big.NewInt(3))
+		if err != nil {
+			t.Fatal(err)
+		}
+		verifyMockSignature(t, hash, tx.Signature)
+	}
+}
+
+// TestRPCAccount_DeclareV2 tests the account DeclareV2 method with the mock node
+func TestRPCAccount_DeclareV2(t *testing.T) {
+	type testSetType struct {
+		ClassPath         string
+		ExpectedClassHash string
+	}
+	testSet := map[string][]testSetType{
+		"mock": {
+			{
+				ClassPath:         "./rpc/tests/0x4e70b19333ae94bd958625f7b61ce9eec631653597e68645e13780061b2136c.json",
+				ExpectedClassHash: "0x4e70b19333ae94bd958625f7b61ce9eec631653597e68645e13780061b2136c",
+			},
+		},
+	}[testEnv]
+
+	for _, test := range testSet {
+		content, err := os.ReadFile(test.ClassPath)
+		if err != nil {
+			t.Fatal("should read the class, instead:", err)
+		}
+		var class rpc.ContractClass
+		if err := json.Unmarshal(content, &class); err != nil {
+			t.Fatal("should unmarshal the class, instead:", err)
+		}
+		casm := rpc.CasmClass{Bytecode: []*felt.Felt{utils.TestHexToFelt(t, "0x1")}}
+		compiledClassHash, err := CompiledClassHash(casm)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		account, mock := newMockRPCAccount(t, AccountVersion1)
+		mock.nonce = 7
+		mock.classHash = test.ExpectedClassHash
+		resp, err := account.DeclareV2(context.Background(), class, casm, types.ExecuteDetails{})
+		if err != nil {
+			t.Fatal("declare should succeed, instead:", err)
+		}
+		if resp.Code != "TRANSACTION_RECEIVED" || resp.ClassHash != test.ExpectedClassHash {
+			t.Fatalf("unexpected response %+v", resp)
+		}
+		var tx rpc.BroadcastedDeclareTransactionV2
+		mock.lastReceived(t, &tx)
+		if tx.Version != rpc.TransactionV2 || tx.Nonce.String() != "0x7" {
+			t.Fatalf("unexpected declare version %s or nonce %s", tx.Version, tx.Nonce)
+		}
+		if !tx.CompiledClassHash.Equal(compiledClassHash) {
+			t.Fatalf("compiled class hash should be %s, instead: %s", compiledClassHash, tx.CompiledClassHash)
+		}
+		if tx.ContractClass.ABI != class.ABI {
+			t.Fatal("abi should be sent as is")
+		}
+		hash, err := account.declareHash(
+			2,
+			types.HexToBN(test.ExpectedClassHash),
+			compiledClassHash.BigInt(big.NewInt(0)),
+			tx.MaxFee.BigInt(big.NewInt(0)),
+			big.NewInt(7),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		verifyMockSignature(t, hash, tx.Signature)
+	}
+}
+
+// TestRPCAccount_DeployWithOptions tests the UDC deployment and the
+// resulting contract address with the mock node
+func TestRPCAccount_DeployWithOptions(t *testing.T) {
+	type testSetType struct {
+		Options         types.DeployOptions
+		ExpectedAddress string
+	}
+	classHash := utils.TestHexToFelt(t, "0x46f844ea1a3b3668f81d38b5c1bd55e816e0373802aefe732138628f0133486")
+	salt := utils.TestHexToFelt(t, "0x74dc2fe193daf1abd8241b63329c1123214842b96ad7fd003d25512598a956b")
+	calldata := []*felt.Felt{
+		utils.TestHexToFelt(t, "0x6d706cfbac9b8262d601c38251c5fbe0497c3a96cc91a92b08d91b61d9e70c4"),
+		utils.TestHexToFelt(t, "0x79dc0da7c54b95f10aa182ad0a46400db63156920adb65eca2654c0945a463"),
+		utils.TestHexToFelt(t, "0x2"),
+		utils.TestHexToFelt(t, "0x6658165b4984816ab189568637bedec5aa0a18305909c7f5726e4a16e3afef6"),
+		utils.TestHexToFelt(t, "0x6b648b36b074a91eee55730f5f5e075ec19c0a8f9ffb0903cefeee93b6ff328"),
+	}
+	uniqueAddress := func(deployer string) string {
+		uniqueSalt, err := Curve.PedersenHash([]*big.Int{types.HexToBN(mockAccountAddress), salt.BigInt(big.NewInt(0))})
+		if err != nil {
+			t.Fatal(err)
+		}
+		constructorCalldata := []*big.Int{}
+		for _, value := range calldata {
+			constructorCalldata = append(constructorCalldata, value.BigInt(big.NewInt(0)))
+		}
+		address, err := ContractAddress(types.HexToBN(deployer), uniqueSalt, classHash.BigInt(big.NewInt(0)), constructorCalldata)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return fmt.Sprintf("0x%x", address)
+	}
+	testSet := map[string][]testSetType{
+		"mock": {
+			{
+				// not unique, the address is the one of a deployment from 0
+				Options:         types.DeployOptions{Salt: salt, ConstructorCalldata: calldata},
+				ExpectedAddress: "0x3ec215c6c9028ff671b46a2a9814970ea23ed3c4bcc3838c6d1dcbf395263c3",
+			},
+			{
+				Options:         types.DeployOptions{Salt: salt, Unique: true, ConstructorCalldata: calldata},
+				ExpectedAddress: uniqueAddress(UDC_ADDRESS),
+			},
+			{
+				Options: types.DeployOptions{
+					Salt:                salt,
+					Unique:              true,
+					ConstructorCalldata: calldata,
+					DeployerAddress:     utils.TestHexToFelt(t, "0x1234"),
+				},
+				ExpectedAddress: uniqueAddress("0x1234"),
+			},
+		},
+	}[testEnv]
+
+	for _, test := range testSet {
+		account, mock := newMockRPCAccount(t, AccountVersion1)
+		resp, err := account.DeployWithOptions(context.Background(), classHash, test.Options, types.ExecuteDetails{
+			MaxFee: big.NewInt(1000),
+			Nonce:  big.NewInt(1),
+		})
+		if err != nil {
+			t.Fatal("deploy should succeed, instead:", err)
+		}
+		if resp.ContractAddress != test.ExpectedAddress {
+			t.Fatalf("address should be %s, instead: %s", test.ExpectedAddress, resp.ContractAddress)
+		}
+		if resp.TransactionHash != mockTransactionHash {
+			t.Fatalf("transaction hash should be %s, instead: %s", mockTransactionHash, resp.TransactionHash)
+		}
+
+		var tx rpc.BroadcastedInvokeV1Transaction
+		mock.lastReceived(t, &tx)
+		deployer := UDC_ADDRESS
+		if test.Options.DeployerAddress != nil {
+			deployer = test.Options.DeployerAddress.String()
+		}
+		if tx.Calldata[1].String() != utils.TestHexToFelt(t, deployer).String() {
+			t.Fatalf("call should target %s, instead: %s", deployer, tx.Calldata[1])
+		}
+		unique := "0x0"
+		if test.Options.Unique {
+			unique = "0x1"
+		}
+		// data is class hash, salt, unique, calldata length and calldata
+		data := tx.Calldata[len(tx.Calldata)-4-len(calldata):]
+		if !data[0].Equal(classHash) || !data[1].Equal(salt) || data[2].String() != unique || data[3].String() != "0x5" {
+			t.Fatalf("unexpected deployContract calldata %v", data)
+		}
+	}
+}
+
+// TestRPCAccount_DeployAccount tests the account DeployAccount method with the
+// mock node
+func TestRPCAccount_DeployAccount(t *testing.T) {
+	type testSetType struct {
+		ClassHash           *felt.Felt
+		Salt                *felt.Felt
+		ConstructorCalldata []*felt.Felt
+	}
+	testSet := map[string][]testSetType{
+		"mock": {
+			{
+				ClassHash:           utils.TestHexToFelt(t, "0x2794ce20e5f2ff0d40e632cb53845b9f4e526ebd8471983f7dbd355b721d5a"),
+				Salt:                utils.TestHexToFelt(t, "0x4b2a5ce2f6c9d2d3ae4f3cd1f0b7b2b6a6bd0b0ae1ec8bb0a3c6b1cfa1a7b2c"),
+				ConstructorCalldata: []*felt.Felt{utils.TestHexToFelt(t, "0x4b2a5ce2f6c9d2d3ae4f3cd1f0b7b2b6a6bd0b0ae1ec8bb0a3c6b1cfa1a7b2c")},
+			},
+		},
+	}[testEnv]
+
+	for _, test := range testSet {
+		account, mock := newMockRPCAccount(t, AccountVersion1)
+		_, err := account.DeployAccount(context.Background(), test.ClassHash, test.Salt, test.ConstructorCalldata, types.ExecuteDetails{})
+		if err == nil {
+			t.Fatal("deploy account should fail when the address does not match")
+		}
+
+		address, err := PrecomputeAccountAddress(test.Salt, test.ClassHash, test.ConstructorCalldata)
+		if err != nil {
+			t.Fatal(err)
+		}
+		account.AccountAddress = address
+		resp, err := account.DeployAccount(context.Background(), test.ClassHash, test.Salt, test.ConstructorCalldata, types.ExecuteDetails{
+			MaxFee: big.NewInt(1000),
+		})
+		if err != nil {
+			t.Fatal("deploy account should succeed, instead:", err)
+		}
+		if resp.ContractAddress != address.String() || resp.TransactionHash != mockTransactionHash {
+			t.Fatalf("unexpected response %+v", resp)
+		}
+
+		var tx rpc.BroadcastedDeployAccountTransaction
+		mock.lastReceived(t, &tx)
+		if tx.Type != "DEPLOY_ACCOUNT" || tx.Version != rpc.TransactionV1 || tx.Nonce.String() != "0x0" {
+			t.Fatalf("unexpected deploy account type %s, version %s or nonce %s", tx.Type, tx.Version, tx.Nonce)
+		}
+		hash, err := account.deployAccountHash(test.ClassHash, test.Salt, test.ConstructorCalldata, big.NewInt(1000), big.NewInt(0))
+		if err != nil {
+			t.Fatal(err)
+		}
+		hashFelt, err := utils.BigIntToFelt(hash)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// the same hash computed with the Pedersen implementation of juno
+		expected := crypto.PedersenArray(
+			new(felt.Felt).SetBytes([]byte("deploy_account")),
+			new(felt.Felt).SetUint64(1),
+			address,
+			&felt.Zero,
+			crypto.PedersenArray(append([]*felt.Felt{tx.ClassHash, tx.ContractAddressSalt}, tx.ConstructorCalldata...)...),
+			tx.MaxFee,
+			new(felt.Felt).SetBytes([]byte("SN_GOERLI")),
+			tx.Nonce,
+		)
+		if !hashFelt.Equal(expected) {
+			t.Fatalf("transaction hash should be %s, instead: %s", expected, hashFelt)
+		}
+		verifyMockSignature(t, hash, tx.Signature)
+	}
+}
+
+// TestRPCAccount_NonceManager tests parallel Execute calls on an account with
+// a nonce manager against the mock node
+func TestRPCAccount_NonceManager(t *testing.T) {
+	account, mock := newMockRPCAccount(t, AccountVersion1, AccountNonceManager)
+	mock.nonce = 10
+	call := types.FunctionCall{
+		ContractAddress:    utils.TestHexToFelt(t, mockAccountAddress),
+		EntryPointSelector: types.GetSelectorFromNameFelt("increment"),
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := account.Execute(context.Background(), []types.FunctionCall{call}, types.ExecuteDetails{MaxFee: big.NewInt(1000)})
+			if err != nil {
+				t.Error("execute should succeed, instead:", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	seen := map[string]bool{}
+	for _, received := range mock.received {
+		var tx rpc.BroadcastedInvokeV1Transaction
+		if err := json.Unmarshal(received, &tx); err != nil {
+			t.Fatal(err)
+		}
+		seen[tx.Nonce.String()] = true
+	}
+	for i := 10; i < 20; i++ {
+		if !seen[fmt.Sprintf("0x%x", i)] {
+			t.Fatalf("nonce 0x%x should have been sent, instead: %v", i, seen)
+		}
+	}
+	if len(mock.nonceTags) != 1 || mock.nonceTags[0] != "pending" {
+		t.Fatalf("pending nonce should be read once, instead: %v", mock.nonceTags)
+	}
+	if inFlight := account.NonceManager().InFlight(); len(inFlight) != 0 {
+		t.Fatalf("no nonce should be in flight, instead: %v", inFlight)
+	}
+
+	// a nonce error resyncs the manager with the node
+	mock.err = rpcMockError{code: 52, message: "Invalid transaction nonce"}
+	_, err := account.Execute(context.Background(), []types.FunctionCall{call}, types.ExecuteDetails{MaxFee: big.NewInt(1000)})
+	if !errors.Is(err, rpc.ErrInvalidTransactionNonce) {
+		t.Fatalf("error should be %v, instead: %v", rpc.ErrInvalidTransactionNonce, err)
+	}
+	mock.err = nil
+	mock.nonce = 15
+	nonce, err := account.NonceManager().Peek(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if nonce.Int64() != 15 {
+		t.Fatalf("nonce should be resynced to 15, instead: %d", nonce)
+	}
+}
+
+// TestAccount_EstimateFeeQueryVersion checks the fee estimates are signed with
+// the query version, so they can not be sent as real transactions
+func TestAccount_EstimateFeeQueryVersion(t *testing.T) {
+	queryBit := new(big.Int).Lsh(big.NewInt(1), 128)
+	call := types.FunctionCall{
+		ContractAddress:    utils.TestHexToFelt(t, mockAccountAddress),
+		EntryPointSelector: types.GetSelectorFromNameFelt("increment"),
+	}
+
+	// RPC v1
+	account, mock := newMockRPCAccount(t, AccountVersion1)
+	mock.nonce = 4
+	if _, err := account.EstimateFee(context.Background(), []types.FunctionCall{call}, types.ExecuteDetails{}); err != nil {
+		t.Fatal("estimate should succeed, instead:", err)
+	}
+	var tx rpc.BroadcastedInvokeV1Transaction
+	mock.lastReceived(t, &tx)
+	if tx.Version != rpc.TransactionV1WithQueryBit {
+		t.Fatalf("estimate version should be %s, instead: %s", rpc.TransactionV1WithQueryBit, tx.Version)
+	}
+	hash, err := account.estimateFeeHash([]types.FunctionCall{call}, types.ExecuteDetails{
+		Nonce:  big.NewInt(4),
+		MaxFee: MAX_FEE,
+	}, new(big.Int).Add(queryBit, big.NewInt(1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	verifyMockSignature(t, hash, tx.Signature)
+
+	// RPC v3
+	account, mock = newMockRPCAccount(t, AccountVersion3)
+	if _, err := account.EstimateFee(context.Background(), []types.FunctionCall{call}, types.ExecuteDetails{}); err != nil {
+		t.Fatal("estimate should succeed, instead:", err)
+	}
+	var txV3 rpc.BroadcastedInvokeV3Transaction
+	mock.lastReceived(t, &txV3)
+	if txV3.Version != rpc.TransactionV3WithQueryBit {
+		t.Fatalf("estimate version should be %s, instead: %s", rpc.TransactionV3WithQueryBit, txV3.Version)
+	}
+	version, err := utils.BigIntToFelt(new(big.Int).Add(queryBit, big.NewInt(3)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	hashV3 := crypto.PoseidonArray(
+		new(felt.Felt).SetBytes([]byte("invoke")),
+		version,
+		account.AccountAddress,
+		crypto.PoseidonArray(
+			&felt.Zero,
+			utils.TestHexToFelt(t, "0x4c315f474153000000000000000000000000000000000000000000000000"),
+			utils.TestHexToFelt(t, "0x4c325f474153000000000000000000000000000000000000000000000000"),
+		),
+		crypto.PoseidonArray(),
+		new(felt.Felt).SetBytes([]byte("SN_GOERLI")),
+		txV3.Nonce,
+		&felt.Zero,
+		crypto.PoseidonArray(),
+		crypto.PoseidonArray(txV3.Calldata...),
+	)
+	verifyMockSignature(t, hashV3.BigInt(big.NewInt(0)), txV3.Signature)
+
+	// gateway
+	account, _ = newMockRPCAccount(t, AccountVersion1)
+	account.provider = ProviderGateway
+	invoke, err := account.prepFunctionInvoke(context.Background(), "estimate", []types.FunctionCall{call}, types.ExecuteDetails{Nonce: big.NewInt(4)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if invoke.Version.Cmp(new(big.Int).Add(queryBit, big.NewInt(1))) != 0 {
+		t.Fatalf("estimate version should be %s, instead: 0x%x", rpc.TransactionV1WithQueryBit, invoke.Version)
+	}
+	verifyMockSignature(t, hash, []*felt.Felt{
+		utils.TestHexToFelt(t, fmt.Sprintf("0x%x", invoke.Signature[0])),
+		utils.TestHexToFelt(t, fmt.Sprintf("0x%x", invoke.Signature[1])),
+	})
+}
@@ -0,0 +1,249 @@
+/*
+Package airdrop builds the Merkle trees of token airdrops and the proofs of
+their claims.
+
+A claim of Amount tokens by Address, with an optional Extra value, is the leaf
+Curve.HashElements(address, amount[, extra]) of a FixedSizeMerkleTree hashed
+with Pedersen, like the leaves and nodes hashed by the Cairo Merkle-drop
+contracts. A claim is verified with ProofMerklePath against the root stored
+in the contract.
+*/
+package airdrop
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+
+	starknetgo "github.com/sjxqqq/starknet-go"
+	"github.com/sjxqqq/starknet-go/types"
+)
+
+var ErrClaimNotFound = errors.New("claim not found")
+
+// Claim is the amount of tokens an address can claim.
+type Claim struct {
+	Address *big.Int
+	Amount  *big.Int
+	// Extra is an optional value hashed after the amount, nil when absent.
+	Extra *big.Int
+}
+
+// Leaf returns the leaf of the claim in the tree of the airdrop.
+func (c Claim) Leaf() (*big.Int, error) {
+	elems := []*big.Int{c.Address, c.Amount}
+	if c.Extra != nil {
+		elems = append(elems, c.Extra)
+	}
+	for _, elem := range elems {
+		if elem == nil || elem.Sign() < 0 || elem.Cmp(starknetgo.Curve.P) >= 0 {
+			return nil, fmt.Errorf("invalid claim value: %v", elem)
+		}
+	}
+	return starknetgo.Curve.HashElements(elems)
+}
+
+/*
+Reads the claims of a CSV of address,amount[,extra] rows. The values are
+decimal or 0x prefixed hexadecimal numbers. A first row that is not a claim,
+such as address,amount, is skipped as the header.
+*/
+func ReadCSV(r io.Reader) ([]Claim, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	claims := []Claim{}
+	for line, record := range records {
+		if len(record) < 2 || len(record) > 3 {
+			return nil, fmt.Errorf("line %d: expected address,amount[,extra], got %d fields", line+1, len(record))
+		}
+		values := make([]*big.Int, len(record))
+		for i, field := range record {
+			value, ok := new(big.Int).SetString(strings.TrimSpace(field), 0)
+			if !ok {
+				values = nil
+				break
+			}
+			values[i] = value
+		}
+		if values == nil {
+			if line == 0 {
+				continue
+			}
+			return nil, fmt.Errorf("line %d: invalid number in %v", line+1, record)
+		}
+		claim := Claim{Address: values[0], Amount: values[1]}
+		if len(values) == 3 {
+			claim.Extra = values[2]
+		}
+		claims = append(claims, claim)
+	}
+	return claims, nil
+}
+
+// Airdrop is the tree of the claims of an airdrop, one per address.
+type Airdrop struct {
+	Claims []Claim
+	Tree   *starknetgo.FixedSizeMerkleTree
+	index  map[string]int
+}
+
+// New builds the tree of claims, in their order.
+func New(claims []Claim) (*Airdrop, error) {
+	if len(claims) == 0 {
+		return nil, errors.New("airdrop without claims")
+	}
+	airdrop := &Airdrop{
+		Claims: claims,
+		index:  map[string]int{},
+	}
+	leaves := make([]*big.Int, len(claims))
+	for i, claim := range claims {
+		leaf, err := claim.Leaf()
+		if err != nil {
+			return nil, err
+		}
+		address := types.BigToHex(claim.Address)
+		if _, ok := airdrop.index[address]; ok {
+			return nil, fmt.Errorf("duplicate claim of %s", address)
+		}
+		airdrop.index[address] = i
+		leaves[i] = leaf
+	}
+	tree, err := starknetgo.NewFixedSizeMerkleTree(leaves...)
+	if err != nil {
+		return nil, err
+	}
+	airdrop.Tree = tree
+	return airdrop, nil
+}
+
+// Root returns the root to store in the airdrop contract.
+func (a *Airdrop) Root() *big.Int {
+	return a.Tree.Root
+}
+
+// ClaimProof is a claim with its leaf and the proof of the leaf.
+type ClaimProof struct {
+	Claim
+	Leaf  *big.Int
+	Proof []*big.Int
+}
+
+// Proof returns the proof of the claim of address.
+func (a *Airdrop) Proof(address *big.Int) (*ClaimProof, error) {
+	i, ok := a.index[types.BigToHex(address)]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrClaimNotFound, types.BigToHex(address))
+	}
+	proof, err := a.Tree.ProofAt(i)
+	if err != nil {
+		return nil, err
+	}
+	return &ClaimProof{
+		Claim: a.Claims[i],
+		Leaf:  proof.Leaf,
+		Proof: proof.Path,
+	}, nil
+}
+
+// Verify returns true when the claim of p is in the airdrop of root. The
+// leaf is computed from the claim.
+func Verify(root *big.Int, p *ClaimProof) bool {
+	leaf, err := p.Claim.Leaf()
+	if err != nil {
+		return false
+	}
+	return starknetgo.ProofMerklePath(root, leaf, p.Proof)
+}
+
+type claimProofJSON struct {
+	Address string   `json:"address"`
+	Amount  string   `json:"amount"`
+	Extra   string   `json:"extra,omitempty"`
+	Leaf    string   `json:"leaf"`
+	Proof   []string `json:"proof"`
+}
+
+func (p ClaimProof) MarshalJSON() ([]byte, error) {
+	raw := claimProofJSON{
+		Address: types.BigToHex(p.Address),
+		Amount:  types.BigToHex(p.Amount),
+		Leaf:    types.BigToHex(p.Leaf),
+		Proof:   make([]string, len(p.Proof)),
+	}
+	if p.Extra != nil {
+		raw.Extra = types.BigToHex(p.Extra)
+	}
+	for i, node := range p.Proof {
+		raw.Proof[i] = types.BigToHex(node)
+	}
+	return json.Marshal(raw)
+}
+
+func (p *ClaimProof) UnmarshalJSON(data []byte) error {
+	var raw claimProofJSON
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	values := append([]string{raw.Address, raw.Amount, raw.Leaf}, raw.Proof...)
+	if raw.Extra != "" {
+		values = append(values, raw.Extra)
+	}
+	numbers := make([]*big.Int, len(values))
+	for i, value := range values {
+		number, ok := new(big.Int).SetString(value, 0)
+		if !ok {
+			return fmt.Errorf("invalid number %q", value)
+		}
+		numbers[i] = number
+	}
+	*p = ClaimProof{
+		Claim: Claim{Address: numbers[0], Amount: numbers[1]},
+		Leaf:  numbers[2],
+		Proof: numbers[3 : 3+len(raw.Proof)],
+	}
+	if raw.Extra != "" {
+		p.Extra = numbers[len(numbers)-1]
+	}
+	return nil
+}
+
+// Proofs are the root of an airdrop and the proofs of its claims by address.
+type Proofs struct {
+	Root   string                 `json:"root"`
+	Claims map[string]*ClaimProof `json:"claims"`
+}
+
+// Proofs returns the proofs of all the claims.
+func (a *Airdrop) Proofs() (*Proofs, error) {
+	proofs := &Proofs{
+		Root:   types.BigToHex(a.Root()),
+		Claims: make(map[string]*ClaimProof, len(a.Claims)),
+	}
+	for _, claim := range a.Claims {
+		proof, err := a.Proof(claim.Address)
+		if err != nil {
+			return nil, err
+		}
+		proofs.Claims[types.BigToHex(claim.Address)] = proof
+	}
+	return proofs, nil
+}
+
+// Claim returns the proof of the claim of address.
+func (p *Proofs) Claim(address *big.Int) (*ClaimProof, error) {
+	proof, ok := p.Claims[types.BigToHex(address)]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrClaimNotFound, types.BigToHex(address))
+	}
+	return proof, nil
+}
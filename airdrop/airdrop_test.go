@@ -0,0 +1,111 @@
+package airdrop
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	starknetgo "github.com/sjxqqq/starknet-go"
+)
+
+const testCSV = `address,amount
+0x1234, 1000
+0xabc,25
+0x49d36570d4e46f48e99674bd3fcc84644ddd6b96f7c741b1562b82f9e004dc7,7,3
+`
+
+func TestReadCSV(t *testing.T) {
+	for _, tc := range []struct {
+		content string
+		claims  int
+		fails   bool
+	}{{
+		content: testCSV,
+		claims:  3,
+	}, {
+		content: "1,2\n3,4\n",
+		claims:  2,
+	}, {
+		content: "0x1,2\n0x3\n",
+		fails:   true,
+	}, {
+		content: "0x1,2\n0x3,four\n",
+		fails:   true,
+	}} {
+		claims, err := ReadCSV(strings.NewReader(tc.content))
+		if tc.fails {
+			if err == nil {
+				t.Fatalf("reading %q should fail", tc.content)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(claims) != tc.claims {
+			t.Fatalf("expected %d claims, got %d", tc.claims, len(claims))
+		}
+	}
+}
+
+func TestAirdrop(t *testing.T) {
+	claims, err := ReadCSV(strings.NewReader(testCSV))
+	if err != nil {
+		t.Fatal(err)
+	}
+	drop, err := New(claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the leaves are the hashes assembled by hand
+	leaf, err := starknetgo.Curve.HashElements([]*big.Int{big.NewInt(0xabc), big.NewInt(25)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	proof, err := drop.Proof(big.NewInt(0xabc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if proof.Leaf.Cmp(leaf) != 0 {
+		t.Fatalf("expected leaf 0x%s, got 0x%s", leaf.Text(16), proof.Leaf.Text(16))
+	}
+	if !starknetgo.ProofMerklePath(drop.Root(), leaf, proof.Proof) {
+		t.Fatal("the proof should match the root")
+	}
+
+	proofs, err := drop.Proofs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err := json.Marshal(proofs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded Proofs
+	if err := json.Unmarshal(content, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	for _, claim := range claims {
+		proof, err := decoded.Claim(claim.Address)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !Verify(drop.Root(), proof) {
+			t.Fatalf("claim of 0x%s should be valid", claim.Address.Text(16))
+		}
+		proof.Amount = new(big.Int).Add(proof.Amount, big.NewInt(1))
+		if Verify(drop.Root(), proof) {
+			t.Fatalf("claim of 0x%s with another amount should be invalid", claim.Address.Text(16))
+		}
+	}
+	if _, err := decoded.Claim(big.NewInt(0xabd)); !errors.Is(err, ErrClaimNotFound) {
+		t.Fatalf("expected %v, got %v", ErrClaimNotFound, err)
+	}
+
+	if _, err := New(append(claims, Claim{Address: big.NewInt(0xabc), Amount: big.NewInt(1)})); err == nil {
+		t.Fatal("duplicate claims should fail")
+	}
+}
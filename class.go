@@ -0,0 +1,103 @@
+package starknetgo
+
+import (
+	"fmt"
+
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/sjxqqq/starknet-go/rpc"
+	"github.com/sjxqqq/starknet-go/types"
+)
+
+const (
+	CONTRACT_CLASS_PREFIX  = "CONTRACT_CLASS_V"
+	COMPILED_CLASS_VERSION = "COMPILED_CLASS_V1"
+)
+
+/*
+Computes the class hash of a Cairo 1 (Sierra) contract class.
+
+(ref: https://github.com/starkware-libs/cairo-lang/blob/master/src/starkware/starknet/core/os/contract_class/contract_class_hash.py)
+*/
+func ClassHash(contract rpc.ContractClass) *felt.Felt {
+	version := new(felt.Felt).SetBytes([]byte(CONTRACT_CLASS_PREFIX + contract.ContractClassVersion))
+	return PoseidonHashMany(
+		version,
+		hashSierraEntryPoints(contract.EntryPointsByType.External),
+		hashSierraEntryPoints(contract.EntryPointsByType.L1Handler),
+		hashSierraEntryPoints(contract.EntryPointsByType.Constructor),
+		types.GetSelectorFromNameFelt(contract.ABI),
+		PoseidonHashMany(contract.SierraProgram...),
+	)
+}
+
+func hashSierraEntryPoints(entryPoints []rpc.SierraEntryPoint) *felt.Felt {
+	flattened := make([]*felt.Felt, 0, len(entryPoints)*2)
+	for _, entryPoint := range entryPoints {
+		flattened = append(flattened, entryPoint.Selector, new(felt.Felt).SetUint64(uint64(entryPoint.FunctionIdx)))
+	}
+	return PoseidonHashMany(flattened...)
+}
+
+/*
+Computes the compiled class hash of a CASM class, as declared alongside the
+Sierra class in a DECLARE v2 transaction.
+
+(ref: https://github.com/starkware-libs/cairo-lang/blob/master/src/starkware/starknet/core/os/contract_class/compiled_class_hash.py)
+*/
+func CompiledClassHash(casm rpc.CasmClass) (*felt.Felt, error) {
+	bytecodeHash := PoseidonHashMany(casm.Bytecode...)
+	if casm.BytecodeSegmentLengths != nil {
+		var err error
+		bytecodeHash, _, err = hashBytecodeSegments(casm.Bytecode, *casm.BytecodeSegmentLengths)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return PoseidonHashMany(
+		new(felt.Felt).SetBytes([]byte(COMPILED_CLASS_VERSION)),
+		hashCasmEntryPoints(casm.EntryPointsByType.External),
+		hashCasmEntryPoints(casm.EntryPointsByType.L1Handler),
+		hashCasmEntryPoints(casm.EntryPointsByType.Constructor),
+		bytecodeHash,
+	), nil
+}
+
+func hashCasmEntryPoints(entryPoints []rpc.CasmEntryPoint) *felt.Felt {
+	flattened := make([]*felt.Felt, 0, len(entryPoints)*3)
+	for _, entryPoint := range entryPoints {
+		builtins := make([]*felt.Felt, len(entryPoint.Builtins))
+		for i, builtin := range entryPoint.Builtins {
+			builtins[i] = new(felt.Felt).SetBytes([]byte(builtin))
+		}
+		flattened = append(flattened,
+			entryPoint.Selector,
+			new(felt.Felt).SetUint64(entryPoint.Offset),
+			PoseidonHashMany(builtins...),
+		)
+	}
+	return PoseidonHashMany(flattened...)
+}
+
+// hashBytecodeSegments hashes bytecode split according to lengths. A leaf is
+// the hash of its data; a node is 1 plus the hash of its (length, hash) pairs.
+// It returns the hash and the number of bytecode elements consumed.
+func hashBytecodeSegments(bytecode []*felt.Felt, lengths rpc.SegmentLengths) (*felt.Felt, uint64, error) {
+	if lengths.Segments == nil {
+		if lengths.Length > uint64(len(bytecode)) {
+			return nil, 0, fmt.Errorf("bytecode segment length %d exceeds bytecode length %d", lengths.Length, len(bytecode))
+		}
+		return PoseidonHashMany(bytecode[:lengths.Length]...), lengths.Length, nil
+	}
+	var offset uint64
+	segments := make([]*felt.Felt, 0, len(lengths.Segments)*2)
+	for _, segment := range lengths.Segments {
+		hash, length, err := hashBytecodeSegments(bytecode[offset:], segment)
+		if err != nil {
+			return nil, 0, err
+		}
+		segments = append(segments, new(felt.Felt).SetUint64(length), hash)
+		offset += length
+	}
+	one := new(felt.Felt).SetUint64(1)
+	return new(felt.Felt).Add(PoseidonHashMany(segments...), one), offset, nil
+}
@@ -0,0 +1,160 @@
+package starknetgo
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/NethermindEth/juno/core/crypto"
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/sjxqqq/starknet-go/rpc"
+	"github.com/sjxqqq/starknet-go/utils"
+)
+
+// TestClassHash checks the Sierra class hash against a class declared on the
+// integration network.
+func TestClassHash(t *testing.T) {
+	content, err := os.ReadFile("./rpc/tests/0x4e70b19333ae94bd958625f7b61ce9eec631653597e68645e13780061b2136c.json")
+	if err != nil {
+		t.Fatal("should read the class, instead:", err)
+	}
+	var class rpc.ContractClass
+	if err := json.Unmarshal(content, &class); err != nil {
+		t.Fatal("should unmarshal the class, instead:", err)
+	}
+	expected := utils.TestHexToFelt(t, "0x4e70b19333ae94bd958625f7b61ce9eec631653597e68645e13780061b2136c")
+	if hash := ClassHash(class); !hash.Equal(expected) {
+		t.Fatalf("class hash should be %s, instead: %s", expected, hash)
+	}
+}
+
+// TestClassHash_ABIFormat checks an ABI given as JSON hashes like the
+// equivalent string ABI.
+func TestClassHash_ABIFormat(t *testing.T) {
+	var fromString, fromArray rpc.ContractClass
+	if err := json.Unmarshal([]byte(`{"abi": "[{\"type\": \"function\", \"name\": \"a,b:c\"}]"}`), &fromString); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(`{"abi": [{"type":"function","name":"a,b:c"}]}`), &fromArray); err != nil {
+		t.Fatal(err)
+	}
+	if fromString.ABI != fromArray.ABI {
+		t.Fatalf("abi should be %q, instead: %q", fromString.ABI, fromArray.ABI)
+	}
+	if !ClassHash(fromString).Equal(ClassHash(fromArray)) {
+		t.Fatal("class hashes should match")
+	}
+}
+
+// TestCompiledClassHash_Fixtures checks the compiled class hash of classes
+// compiled by the Cairo compiler against their declared compiled class hash,
+// with and without bytecode segment lengths.
+func TestCompiledClassHash_Fixtures(t *testing.T) {
+	type testSetType struct {
+		CompiledClassHash string
+		Segmented         bool
+	}
+	testSet := map[string][]testSetType{
+		"mock": {
+			// HelloStarknet compiled by Cairo 2.6.3
+			{CompiledClassHash: "0x6ff9f7df06da94198ee535f41b214dce0b8bafbdb45e6c6b09d4b3b693b1f17", Segmented: true},
+			// hello_starknet compiled by Cairo 2.1.0
+			{CompiledClassHash: "0x785fa5f2bacf0bfe3bc413be5820a61e1ea63f2ec27ef00331ee9f46ad07603", Segmented: false},
+		},
+	}[testEnv]
+
+	for _, test := range testSet {
+		content, err := os.ReadFile("./rpc/tests/" + test.CompiledClassHash + ".casm.json")
+		if err != nil {
+			t.Fatal("should read the class, instead:", err)
+		}
+		var casm rpc.CasmClass
+		if err := json.Unmarshal(content, &casm); err != nil {
+			t.Fatal("should unmarshal the class, instead:", err)
+		}
+		if (casm.BytecodeSegmentLengths != nil) != test.Segmented {
+			t.Fatalf("class %s should have bytecode segment lengths: %v", test.CompiledClassHash, test.Segmented)
+		}
+		hash, err := CompiledClassHash(casm)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := utils.TestHexToFelt(t, test.CompiledClassHash)
+		if !hash.Equal(expected) {
+			t.Fatalf("compiled class hash should be %s, instead: %s", expected, hash)
+		}
+	}
+}
+
+func TestCompiledClassHash(t *testing.T) {
+	casm := rpc.CasmClass{
+		Bytecode: []*felt.Felt{
+			utils.TestHexToFelt(t, "0x1"),
+			utils.TestHexToFelt(t, "0x2"),
+			utils.TestHexToFelt(t, "0x3"),
+		},
+		EntryPointsByType: rpc.CasmEntryPointsByType{
+			External: []rpc.CasmEntryPoint{{
+				Selector: utils.TestHexToFelt(t, "0x22ff5f21f0b81b113e63f7db6da94fedef11b2119b4088b89664fb9a3cb658"),
+				Offset:   1,
+				Builtins: []string{"range_check"},
+			}},
+		},
+	}
+	external := crypto.PoseidonArray(
+		casm.EntryPointsByType.External[0].Selector,
+		new(felt.Felt).SetUint64(1),
+		crypto.PoseidonArray(new(felt.Felt).SetBytes([]byte("range_check"))),
+	)
+	expected := crypto.PoseidonArray(
+		new(felt.Felt).SetBytes([]byte("COMPILED_CLASS_V1")),
+		external,
+		crypto.PoseidonArray(),
+		crypto.PoseidonArray(),
+		crypto.PoseidonArray(casm.Bytecode...),
+	)
+	hash, err := CompiledClassHash(casm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !hash.Equal(expected) {
+		t.Fatalf("compiled class hash should be %s, instead: %s", expected, hash)
+	}
+
+	// With segments, the bytecode hash becomes 1 + h(len_1, h_1, len_2, h_2).
+	if err := json.Unmarshal([]byte(`[1, [2]]`), &casm.BytecodeSegmentLengths); err != nil {
+		t.Fatal(err)
+	}
+	inner := new(felt.Felt).Add(
+		crypto.PoseidonArray(new(felt.Felt).SetUint64(2), crypto.PoseidonArray(casm.Bytecode[1:]...)),
+		new(felt.Felt).SetUint64(1),
+	)
+	bytecodeHash := new(felt.Felt).Add(
+		crypto.PoseidonArray(
+			new(felt.Felt).SetUint64(1), crypto.PoseidonArray(casm.Bytecode[0]),
+			new(felt.Felt).SetUint64(2), inner,
+		),
+		new(felt.Felt).SetUint64(1),
+	)
+	expected = crypto.PoseidonArray(
+		new(felt.Felt).SetBytes([]byte("COMPILED_CLASS_V1")),
+		external,
+		crypto.PoseidonArray(),
+		crypto.PoseidonArray(),
+		bytecodeHash,
+	)
+	hash, err = CompiledClassHash(casm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !hash.Equal(expected) {
+		t.Fatalf("segmented compiled class hash should be %s, instead: %s", expected, hash)
+	}
+
+	if err := json.Unmarshal([]byte(`[4]`), &casm.BytecodeSegmentLengths); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := CompiledClassHash(casm); err == nil {
+		t.Fatal("segments longer than the bytecode should fail")
+	}
+}
@@ -49,7 +49,7 @@ func (ap *AccountManager) Write(filename string) error {
 
 type Provider interface {
 	declareAndWaitWithWallet(context context.Context, contractClass []byte) (*DeclareOutput, error)
-	deployAccountAndWaitNoWallet(ctx context.Context, classHash *felt.Felt, compiledClass []byte, salt string, inputs []string) (*DeployOutput, error)
+	deployAccountAndWait(ctx context.Context, privateKey *big.Int, classHash, salt *felt.Felt, inputs []*felt.Felt) (*DeployOutput, error)
 }
 
 const (
@@ -68,10 +68,30 @@ func guessProviderType(p interface{}) (Provider, error) {
 	return nil, errors.New("unsupported type")
 }
 
-// InstallAndWaitForAccount installs an account with a DEPLOY command.
-//
-// Deprecated: this function should be replaced by InstallAndWaitForAccount
-// that will use the DEPLOY_ACCOUNT syscall.
+// accountKeystore returns a keystore holding privateKey, indexed by its public
+// key, and the counterfactual address of the account to deploy.
+func accountKeystore(privateKey *big.Int, classHash, salt *felt.Felt, inputs []*felt.Felt) (*felt.Felt, *felt.Felt, starknetgo.Keystore, error) {
+	publicKey, _, err := starknetgo.Curve.PrivateToPoint(privateKey)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	sender, err := utils.BigIntToFelt(publicKey)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	address, err := starknetgo.PrecomputeAccountAddress(salt, classHash, inputs)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	ks := starknetgo.NewMemKeystore()
+	ks.Put(sender.String(), privateKey)
+	return sender, address, ks, nil
+}
+
+// InstallAndWaitForAccount declares the account classes and deploys the
+// account with a DEPLOY_ACCOUNT transaction signed by privateKey. The public
+// key is used as the salt; the counterfactual address must hold enough funds
+// to pay for the deployment on networks that charge fees.
 func InstallAndWaitForAccount[V *rpc.Provider | *gateway.GatewayProvider](ctx context.Context, provider V, privateKey *big.Int, compiledContracts artifacts.CompiledContract) (*AccountManager, error) {
 	if len(compiledContracts.AccountCompiled) == 0 {
 		return nil, errors.New("empty account")
@@ -82,7 +102,6 @@ func InstallAndWaitForAccount[V *rpc.Provider | *gateway.GatewayProvider](ctx co
 		return nil, err
 	}
 	publicKeyString := fmt.Sprintf("0x%x", publicKey)
-	fmt.Println("z")
 	p, err := guessProviderType(provider)
 	if err != nil {
 		return nil, err
@@ -103,32 +122,35 @@ func InstallAndWaitForAccount[V *rpc.Provider | *gateway.GatewayProvider](ctx co
 		}
 		pluginClassHash = output.classHash
 	}
-	compiledDeployed := compiledContracts.AccountCompiled
+	proxyClassHash := ""
+	deployedClassHash := accountClassHash
 	if len(compiledContracts.ProxyCompiled) != 0 {
-		compiledDeployed = compiledContracts.ProxyCompiled
+		output, err := p.declareAndWaitWithWallet(ctx, compiledContracts.ProxyCompiled)
+		if err != nil {
+			return nil, err
+		}
+		proxyClassHash = output.classHash
+		deployedClassHash = proxyClassHash
 	}
 	calldata, err := compiledContracts.Formatter(accountClassHash, pluginClassHash, publicKeyString)
 	if err != nil {
 		return nil, err
 	}
-	fmt.Println("d")
-	accountClassHashStr, err := utils.HexToFelt(accountClassHash)
+	classHash, err := utils.HexToFelt(deployedClassHash)
 	if err != nil {
 		return nil, err
 	}
-
-	// TODO: compiledDeploed could be proxy
-	deployedOutput, err := p.deployAccountAndWaitNoWallet(ctx, accountClassHashStr, compiledDeployed, publicKeyString, calldata)
+	salt, err := utils.HexToFelt(publicKeyString)
 	if err != nil {
 		return nil, err
 	}
-	fmt.Println("e")
-	proxyClassHash := ""
-	switch len(compiledContracts.ProxyCompiled) {
-	case 0:
-		accountClassHash = deployedOutput.ClassHash
-	default:
-		proxyClassHash = deployedOutput.ClassHash
+	inputs, err := utils.HexArrToFelt(calldata)
+	if err != nil {
+		return nil, err
+	}
+	deployedOutput, err := p.deployAccountAndWait(ctx, privateKey, classHash, salt, inputs)
+	if err != nil {
+		return nil, err
 	}
 	return &AccountManager{
 		AccountAddress:   deployedOutput.ContractAddress,
@@ -140,5 +162,4 @@ func InstallAndWaitForAccount[V *rpc.Provider | *gateway.GatewayProvider](ctx co
 		TransactionHash:  deployedOutput.TransactionHash,
 		Version:          "v1",
 	}, nil
-
 }
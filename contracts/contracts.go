@@ -9,6 +9,7 @@ import (
 	"math/big"
 
 	"github.com/NethermindEth/juno/core/felt"
+	starknetgo "github.com/sjxqqq/starknet-go"
 	"github.com/sjxqqq/starknet-go/gateway"
 	"github.com/sjxqqq/starknet-go/rpc"
 	"github.com/sjxqqq/starknet-go/types"
@@ -51,29 +52,22 @@ func (p *GatewayProvider) declareAndWaitWithWallet(ctx context.Context, compiled
 	}, nil
 }
 
-// TODO: remove compiledClass from the interface
-func (p *GatewayProvider) deployAccountAndWaitNoWallet(ctx context.Context, classHash *felt.Felt, compiledClass []byte, salt string, inputs []string) (*DeployOutput, error) {
+func (p *GatewayProvider) deployAccountAndWait(ctx context.Context, privateKey *big.Int, classHash, salt *felt.Felt, inputs []*felt.Felt) (*DeployOutput, error) {
 	provider := gateway.GatewayProvider(*p)
-	class := rpc.DeprecatedContractClass{}
-
-	if err := json.Unmarshal(compiledClass, &class); err != nil {
+	sender, address, ks, err := accountKeystore(privateKey, classHash, salt, inputs)
+	if err != nil {
 		return nil, err
 	}
-	fmt.Printf("classHash %v\n", classHash.String())
-	tx, err := provider.DeployAccount(ctx, types.DeployAccountRequest{
-		// MaxFee
-		Version:             big.NewInt(1),
-		ContractAddressSalt: salt,
-		ConstructorCalldata: inputs,
-		ClassHash:           classHash.String(),
-	})
-
+	account, err := starknetgo.NewGatewayAccount(sender, address, ks, &provider, starknetgo.AccountVersion1)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := account.DeployAccount(ctx, classHash, salt, inputs, types.ExecuteDetails{})
 	if err != nil {
 		return nil, err
 	}
 
 	_, receipt, err := (&provider).WaitForTransaction(ctx, tx.TransactionHash, 8, 60)
-
 	if err != nil {
 		log.Printf("contract Address: %s\n", tx.ContractAddress)
 		log.Printf("transaction Hash: %s\n", tx.TransactionHash)
@@ -87,6 +81,7 @@ func (p *GatewayProvider) deployAccountAndWaitNoWallet(ctx context.Context, clas
 
 	return &DeployOutput{
 		ContractAddress: tx.ContractAddress,
+		ClassHash:       classHash.String(),
 		TransactionHash: tx.TransactionHash,
 	}, nil
 }
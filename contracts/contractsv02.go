@@ -7,9 +7,11 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"math/big"
 	"time"
 
 	"github.com/NethermindEth/juno/core/felt"
+	starknetgo "github.com/sjxqqq/starknet-go"
 	"github.com/sjxqqq/starknet-go/rpc"
 	"github.com/sjxqqq/starknet-go/types"
 	"github.com/sjxqqq/starknet-go/utils"
@@ -58,39 +60,26 @@ func (p *RPCProvider) declareAndWaitWithWallet(ctx context.Context, compiledClas
 	}, nil
 }
 
-func (p *RPCProvider) deployAccountAndWaitNoWallet(ctx context.Context, classHash *felt.Felt, compiledClass []byte, salt string, inputs []string) (*DeployOutput, error) {
+func (p *RPCProvider) deployAccountAndWait(ctx context.Context, privateKey *big.Int, classHash, salt *felt.Felt, inputs []*felt.Felt) (*DeployOutput, error) {
 	provider := rpc.Provider(*p)
-	class := rpc.DeprecatedContractClass{}
-	if err := json.Unmarshal(compiledClass, &class); err != nil {
+	sender, address, ks, err := accountKeystore(privateKey, classHash, salt, inputs)
+	if err != nil {
 		return nil, err
 	}
-
-	fmt.Printf("classHash %v\n", classHash.String())
-
-	saltFelt, err := utils.HexToFelt(salt)
+	account, err := starknetgo.NewRPCAccount(sender, address, ks, &provider, starknetgo.AccountVersion1)
 	if err != nil {
 		return nil, err
 	}
-	inputsFelt, err := utils.HexArrToFelt(inputs)
+	tx, err := account.DeployAccount(ctx, classHash, salt, inputs, types.ExecuteDetails{})
 	if err != nil {
 		return nil, err
 	}
-
-	tx, err := provider.AddDeployAccountTransaction(ctx, rpc.BroadcastedDeployAccountTransaction{
-		BroadcastedTxnCommonProperties: rpc.BroadcastedTxnCommonProperties{
-			MaxFee:  new(felt.Felt).SetUint64(1),
-			Version: "0x01",
-			Nonce:   new(felt.Felt).SetUint64(2), // TODO: nonce handling
-		},
-		ContractAddressSalt: saltFelt,
-		ConstructorCalldata: inputsFelt,
-		ClassHash:           classHash,
-	})
+	transactionHash, err := utils.HexToFelt(tx.TransactionHash)
 	if err != nil {
 		return nil, err
 	}
 
-	status, err := provider.WaitForTransaction(ctx, tx.TransactionHash, 8*time.Second)
+	status, err := provider.WaitForTransaction(ctx, transactionHash, 8*time.Second)
 	if err != nil {
 		log.Printf("contract Address: %s\n", tx.ContractAddress)
 		log.Printf("transaction Hash: %s\n", tx.TransactionHash)
@@ -102,8 +91,9 @@ func (p *RPCProvider) deployAccountAndWaitNoWallet(ctx context.Context, classHas
 		return nil, errors.New("deploy rejected")
 	}
 	return &DeployOutput{
-		ContractAddress: tx.ContractAddress.String(),
-		TransactionHash: tx.TransactionHash.String(),
+		ContractAddress: tx.ContractAddress,
+		ClassHash:       classHash.String(),
+		TransactionHash: tx.TransactionHash,
 	}, nil
 }
 
@@ -3,6 +3,7 @@ package starknetgo
 import (
 	"fmt"
 	"math/big"
+	"sync"
 	"testing"
 
 	"github.com/sjxqqq/starknet-go/types"
@@ -21,8 +22,89 @@ func BenchmarkPedersenHash(b *testing.B) {
 
 	for _, test := range suite {
 		b.Run(fmt.Sprintf("input_size_%d_%d", test[0].BitLen(), test[1].BitLen()), func(b *testing.B) {
-			Curve.PedersenHash(test)
+			for i := 0; i < b.N; i++ {
+				Curve.PedersenHash(test)
+			}
 		})
+		b.Run(fmt.Sprintf("bits_input_size_%d_%d", test[0].BitLen(), test[1].BitLen()), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				Curve.pedersenHashBits(test)
+			}
+		})
+	}
+}
+
+func BenchmarkComputeHashOnElements(b *testing.B) {
+	elems := make([]*big.Int, 100)
+	for i := range elems {
+		elems[i] = new(big.Int).Lsh(big.NewInt(int64(i+1)), 200)
+	}
+	b.Run("tables", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			Curve.ComputeHashOnElements(elems)
+		}
+	})
+	b.Run("bits", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			hash := big.NewInt(0)
+			for _, elem := range append(elems, big.NewInt(int64(len(elems)))) {
+				hash, _ = Curve.pedersenHashBits([]*big.Int{hash, elem})
+			}
+		}
+	})
+}
+
+// TestPedersenHash_Tables checks the precomputed tables give the hash of the
+// constant points, including from concurrent goroutines.
+func TestPedersenHash_Tables(t *testing.T) {
+	max := new(big.Int).Sub(Curve.P, big.NewInt(1))
+	inputs := [][]*big.Int{
+		{big.NewInt(0), big.NewInt(0)},
+		{big.NewInt(1)},
+		{max, max},
+		{new(big.Int).Lsh(big.NewInt(1), 248), new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 248), big.NewInt(1))},
+	}
+	for i := 0; i < 32; i++ {
+		a, err := Curve.GetRandomPrivateKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := Curve.GetRandomPrivateKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		inputs = append(inputs, []*big.Int{a, b})
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, len(inputs))
+	for _, input := range inputs {
+		wg.Add(1)
+		go func(input []*big.Int) {
+			defer wg.Done()
+			expected, err := Curve.pedersenHashBits(input)
+			if err != nil {
+				errs <- err
+				return
+			}
+			hash, err := Curve.PedersenHash(input)
+			if err != nil {
+				errs <- err
+				return
+			}
+			if hash.Cmp(expected) != 0 {
+				errs <- fmt.Errorf("hash of %v should be %x, instead: %x", input, expected, hash)
+			}
+		}(input)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatal(err)
+	}
+
+	if _, err := Curve.PedersenHash([]*big.Int{Curve.P, big.NewInt(1)}); err == nil {
+		t.Fatal("elements out of the field should fail")
 	}
 }
 
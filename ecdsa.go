@@ -0,0 +1,313 @@
+package starknetgo
+
+import (
+	"crypto/subtle"
+	"math/big"
+	"runtime"
+	"sync"
+
+	starkcurve "github.com/consensys/gnark-crypto/ecc/stark-curve"
+	"github.com/consensys/gnark-crypto/ecc/stark-curve/fp"
+	"github.com/consensys/gnark-crypto/ecc/stark-curve/fr"
+)
+
+// ecWindows is the number of 4 bits windows of a scalar below the curve
+// order.
+const ecWindows = 63
+
+// projectivePoint is a point (X:Y:Z) with x = X/Z and y = Y/Z. The point at
+// infinity is (0:1:0).
+type projectivePoint struct {
+	X, Y, Z fp.Element
+}
+
+var (
+	ecBaseTableOnce sync.Once
+	// ecBaseTable holds j * 16^w * EcGen at [w][j].
+	ecBaseTable [ecWindows][16]projectivePoint
+	// ecCurveB3 is 3 times the b coefficient of the curve.
+	ecCurveB3 fp.Element
+	// ecOrderMinusTwo is the exponent of the inversions modulo the order.
+	ecOrderMinusTwo *big.Int
+)
+
+func initECBaseTable() {
+	_, b := starkcurve.CurveCoefficients()
+	ecCurveB3.Double(&b).Add(&ecCurveB3, &b)
+	ecOrderMinusTwo = new(big.Int).Sub(fr.Modulus(), big.NewInt(2))
+
+	_, generator := starkcurve.Generators()
+	base := projectivePoint{X: generator.X, Y: generator.Y}
+	base.Z.SetOne()
+	for w := 0; w < ecWindows; w++ {
+		ecBaseTable[w][0].Y.SetOne()
+		for j := 1; j < 16; j++ {
+			ecBaseTable[w][j].add(&ecBaseTable[w][j-1], &base)
+		}
+		// the base of the next window is 16 times the base of this one
+		base.add(&ecBaseTable[w][15], &ecBaseTable[w][1])
+	}
+}
+
+/*
+Sets p to p1 + p2 with the complete addition formulas for short Weierstrass
+curves, which have no exceptional case and run in constant time.
+
+(ref: https://eprint.iacr.org/2015/1060.pdf, algorithm 1 with a = 1)
+*/
+func (p *projectivePoint) add(p1, p2 *projectivePoint) *projectivePoint {
+	var t0, t1, t2, t3, t4, t5, x3, y3, z3 fp.Element
+	t0.Mul(&p1.X, &p2.X)
+	t1.Mul(&p1.Y, &p2.Y)
+	t2.Mul(&p1.Z, &p2.Z)
+	t3.Add(&p1.X, &p1.Y)
+	t4.Add(&p2.X, &p2.Y)
+	t3.Mul(&t3, &t4)
+	t4.Add(&t0, &t1)
+	t3.Sub(&t3, &t4)
+	t4.Add(&p1.X, &p1.Z)
+	t5.Add(&p2.X, &p2.Z)
+	t4.Mul(&t4, &t5)
+	t5.Add(&t0, &t2)
+	t4.Sub(&t4, &t5)
+	t5.Add(&p1.Y, &p1.Z)
+	x3.Add(&p2.Y, &p2.Z)
+	t5.Mul(&t5, &x3)
+	x3.Add(&t1, &t2)
+	t5.Sub(&t5, &x3)
+	z3.Set(&t4)
+	x3.Mul(&ecCurveB3, &t2)
+	z3.Add(&x3, &z3)
+	x3.Sub(&t1, &z3)
+	z3.Add(&t1, &z3)
+	y3.Mul(&x3, &z3)
+	t1.Double(&t0)
+	t1.Add(&t1, &t0)
+	t4.Mul(&ecCurveB3, &t4)
+	t1.Add(&t1, &t2)
+	t2.Sub(&t0, &t2)
+	t4.Add(&t4, &t2)
+	t0.Mul(&t1, &t4)
+	y3.Add(&y3, &t0)
+	t0.Mul(&t5, &t4)
+	x3.Mul(&t3, &x3)
+	x3.Sub(&x3, &t0)
+	t0.Mul(&t3, &t1)
+	z3.Mul(&t5, &z3)
+	z3.Add(&z3, &t0)
+	p.X, p.Y, p.Z = x3, y3, z3
+	return p
+}
+
+// affine returns the affine coordinates of p, which must not be the point at
+// infinity.
+func (p *projectivePoint) affine() (x, y *big.Int) {
+	var zInv, ax, ay fp.Element
+	zInv.Inverse(&p.Z)
+	ax.Mul(&p.X, &zInv)
+	ay.Mul(&p.Y, &zInv)
+	return ax.BigInt(new(big.Int)), ay.BigInt(new(big.Int))
+}
+
+// jacobian returns p in the Jacobian coordinates of the curve backend.
+func (p *projectivePoint) jacobian() starkcurve.G1Jac {
+	// (X:Y:Z) is (XZ:YZ^2:Z) in Jacobian coordinates
+	var q starkcurve.G1Jac
+	q.X.Mul(&p.X, &p.Z)
+	q.Y.Square(&p.Z).Mul(&q.Y, &p.Y)
+	q.Z.Set(&p.Z)
+	return q
+}
+
+// scalarBaseMult returns k * EcGen, reading the whole table of each window so
+// the time and memory accesses do not depend on k.
+func scalarBaseMult(k *fr.Element) projectivePoint {
+	ecBaseTableOnce.Do(initECBaseTable)
+	scalar := k.Bytes()
+	var acc, selected projectivePoint
+	acc.Y.SetOne()
+	for w := 0; w < ecWindows; w++ {
+		nibble := scalar[len(scalar)-1-w/2] >> (4 * (w % 2)) & 0xf
+		selected = projectivePoint{}
+		for j := range ecBaseTable[w] {
+			c := subtle.ConstantTimeByteEq(uint8(j), nibble)
+			selected.X.Select(c, &selected.X, &ecBaseTable[w][j].X)
+			selected.Y.Select(c, &selected.Y, &ecBaseTable[w][j].Y)
+			selected.Z.Select(c, &selected.Z, &ecBaseTable[w][j].Z)
+		}
+		acc.add(&acc, &selected)
+	}
+	return acc
+}
+
+// scalarBaseMultVartime returns k * EcGen for a public k.
+func scalarBaseMultVartime(k *fr.Element) projectivePoint {
+	ecBaseTableOnce.Do(initECBaseTable)
+	scalar := k.Bytes()
+	var acc projectivePoint
+	acc.Y.SetOne()
+	for w := 0; w < ecWindows; w++ {
+		if nibble := scalar[len(scalar)-1-w/2] >> (4 * (w % 2)) & 0xf; nibble != 0 {
+			acc.add(&acc, &ecBaseTable[w][nibble])
+		}
+	}
+	return acc
+}
+
+// usesECBackend returns true when sc is the Stark curve of the field element
+// backend.
+func (sc StarkCurve) usesECBackend() bool {
+	return sc.P != nil && sc.P.Cmp(fp.Modulus()) == 0 &&
+		sc.N != nil && sc.N.Cmp(fr.Modulus()) == 0 &&
+		sc.EcGenX != nil && sc.EcGenX.Cmp(Curve.EcGenX) == 0 &&
+		sc.EcGenY != nil && sc.EcGenY.Cmp(Curve.EcGenY) == 0
+}
+
+// signECBackend signs msgHash like Sign, with the curve arithmetic in
+// constant time.
+func (sc StarkCurve) signECBackend(msgHash, privKey, seed *big.Int) (r, s *big.Int) {
+	ecBaseTableOnce.Do(initECBaseTable)
+	var priv, hash fr.Element
+	priv.SetBigInt(privKey)
+	hash.SetBigInt(msgHash)
+	for {
+		kBig := sc.GenerateSecret(new(big.Int).Set(msgHash), new(big.Int).Set(privKey), new(big.Int).Set(seed))
+		// In case r is rejected k shall be generated with new seed
+		seed.Add(seed, big.NewInt(1))
+
+		var k fr.Element
+		k.SetBigInt(kBig)
+		point := scalarBaseMult(&k)
+		r, _ = point.affine()
+		if r.Sign() != 1 || r.Cmp(sc.Max) != -1 {
+			continue
+		}
+
+		// s = (r * priv + msgHash) / k, the inverse of w = k / (r * priv + msgHash)
+		var agg, kInv, sElem fr.Element
+		agg.SetBigInt(r)
+		agg.Mul(&agg, &priv).Add(&agg, &hash)
+		if agg.IsZero() {
+			continue
+		}
+		kInv.Exp(k, ecOrderMinusTwo)
+		sElem.Mul(&agg, &kInv)
+		var aggInv, w fr.Element
+		aggInv.Exp(agg, ecOrderMinusTwo)
+		w.Mul(&k, &aggInv)
+		if w.BigInt(new(big.Int)).Cmp(sc.Max) != -1 {
+			continue
+		}
+		return r, sElem.BigInt(new(big.Int))
+	}
+}
+
+// validSignature returns true when the values of a signature are in the
+// ranges Verify accepts. w is the inverse of s modulo the curve order.
+func (sc StarkCurve) validSignature(msgHash, r, s, w, pubX, pubY *big.Int) bool {
+	if s.Sign() != 1 || s.Cmp(sc.N) != -1 {
+		return false
+	}
+	if r.Sign() != 1 || r.Cmp(sc.Max) != -1 {
+		return false
+	}
+	if w.Sign() != 1 || w.Cmp(sc.Max) != -1 {
+		return false
+	}
+	if msgHash.Sign() != 1 || msgHash.Cmp(sc.Max) != -1 {
+		return false
+	}
+	return sc.IsOnCurve(pubX, pubY)
+}
+
+// verifyECBackend checks x(w * (msgHash * EcGen +- r * pub)) == r, where w is
+// the inverse of s, without inverting the coordinates of the result.
+func verifyECBackend(msgHash, r *big.Int, w *fr.Element, pubX, pubY *big.Int) bool {
+	var u1, u2 fr.Element
+	u1.SetBigInt(msgHash)
+	u1.Mul(&u1, w)
+	u2.SetBigInt(r)
+	u2.Mul(&u2, w)
+
+	base := scalarBaseMultVartime(&u1)
+	a := base.jacobian()
+	var pub starkcurve.G1Affine
+	pub.X.SetBigInt(pubX)
+	pub.Y.SetBigInt(pubY)
+	var b starkcurve.G1Jac
+	b.FromAffine(&pub)
+	b.ScalarMultiplication(&b, u2.BigInt(new(big.Int)))
+
+	var rElem fp.Element
+	rElem.SetBigInt(r)
+	matches := func(p *starkcurve.G1Jac) bool {
+		if p.Z.IsZero() {
+			return false
+		}
+		var expected fp.Element
+		expected.Square(&p.Z).Mul(&expected, &rElem)
+		return expected.Equal(&p.X)
+	}
+	var sum, difference starkcurve.G1Jac
+	sum.Set(&a).AddAssign(&b)
+	if matches(&sum) {
+		return true
+	}
+	difference.Set(&a).SubAssign(&b)
+	return matches(&difference)
+}
+
+// VerifyInput is a signature checked by BatchVerify.
+type VerifyInput struct {
+	MsgHash *big.Int
+	R       *big.Int
+	S       *big.Int
+	PubX    *big.Int
+	PubY    *big.Int
+}
+
+/*
+Verifies many signatures at once, returning the result of Verify for each of
+them. The inverses of the signatures are computed together and the signatures
+checked in parallel.
+*/
+func (sc StarkCurve) BatchVerify(inputs []VerifyInput) []bool {
+	results := make([]bool, len(inputs))
+	if !sc.usesECBackend() {
+		for i, input := range inputs {
+			results[i] = sc.Verify(input.MsgHash, input.R, input.S, input.PubX, input.PubY)
+		}
+		return results
+	}
+
+	values := make([]fr.Element, len(inputs))
+	for i, input := range inputs {
+		if input.S.Sign() == 1 && input.S.Cmp(sc.N) == -1 {
+			values[i].SetBigInt(input.S)
+		}
+	}
+	// the zero values are left as is and rejected with their s
+	inverses := fr.BatchInvert(values)
+
+	workers := runtime.GOMAXPROCS(0)
+	if workers > len(inputs) {
+		workers = len(inputs)
+	}
+	var wg sync.WaitGroup
+	for worker := 0; worker < workers; worker++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			for i := worker; i < len(inputs); i += workers {
+				input := inputs[i]
+				w := inverses[i].BigInt(new(big.Int))
+				if !sc.validSignature(input.MsgHash, input.R, input.S, w, input.PubX, input.PubY) {
+					continue
+				}
+				results[i] = verifyECBackend(input.MsgHash, input.R, &inverses[i], input.PubX, input.PubY)
+			}
+		}(worker)
+	}
+	wg.Wait()
+	return results
+}
@@ -0,0 +1,52 @@
+package starknetgo
+
+import (
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+)
+
+// StarkKeyFromEthSignature returns the Stark private key and public key
+// derived from a 65 bytes [r || s || v] Ethereum signature, the way StarkEx
+// and the dApps onboarding Ethereum wallets do: r is ground onto the Stark
+// curve order.
+func StarkKeyFromEthSignature(signature []byte) (*big.Int, *big.Int, error) {
+	if len(signature) != ethcrypto.SignatureLength {
+		return nil, nil, fmt.Errorf("signature should be %d bytes, instead: %d", ethcrypto.SignatureLength, len(signature))
+	}
+	key, err := GrindKey(new(big.Int).SetBytes(signature[:32]))
+	if err != nil {
+		return nil, nil, err
+	}
+	x, _, err := Curve.PrivateToPoint(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	return key, x, nil
+}
+
+// SignEthMessage returns the personal_sign (EIP-191) signature of message
+// with ethKey. The signature is deterministic, so the same Stark key is
+// derived every time.
+func SignEthMessage(ethKey *ecdsa.PrivateKey, message []byte) ([]byte, error) {
+	signature, err := ethcrypto.Sign(accounts.TextHash(message), ethKey)
+	if err != nil {
+		return nil, err
+	}
+	// wallets return v as 27 or 28
+	signature[ethcrypto.RecoveryIDOffset] += 27
+	return signature, nil
+}
+
+// StarkKeyFromEthPrivateKey returns the Stark private key and public key
+// derived from the personal_sign signature of message with ethKey.
+func StarkKeyFromEthPrivateKey(ethKey *ecdsa.PrivateKey, message []byte) (*big.Int, *big.Int, error) {
+	signature, err := SignEthMessage(ethKey, message)
+	if err != nil {
+		return nil, nil, err
+	}
+	return StarkKeyFromEthSignature(signature)
+}
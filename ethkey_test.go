@@ -0,0 +1,113 @@
+package starknetgo
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestStarkKeyFromEthSignature(t *testing.T) {
+	type testSetType struct {
+		Signature     string
+		Expected      string
+		ExpectedError bool
+	}
+	testSet := map[string][]testSetType{
+		"mock": {
+			{
+				Signature: "21fbf0696d5e0aa2ef41a2b4ffb623bcaf070461d61cf7251c74161f82fec3a4370854bc0a34b3ab487c1bc021cd318c734c51ae29374f2beb0e6f2dd49b4bf41c",
+				Expected:  "766f11e90cd7c7b43085b56da35c781f8c067ac0d578eabdceebc4886435bda",
+			},
+			{
+				Signature:     "21fbf0696d5e0aa2ef41a2b4ffb623bcaf070461d61cf7251c74161f82fec3a4",
+				ExpectedError: true,
+			},
+		},
+	}[testEnv]
+
+	for _, test := range testSet {
+		signature, _ := hex.DecodeString(test.Signature)
+		key, public, err := StarkKeyFromEthSignature(signature)
+		if test.ExpectedError {
+			if err == nil {
+				t.Fatal("short signature should fail")
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if key.Text(16) != test.Expected {
+			t.Fatalf("key should be %s, instead: %x", test.Expected, key)
+		}
+		x, _, err := Curve.PrivateToPoint(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if x.Cmp(public) != 0 {
+			t.Fatalf("public key should be %x, instead: %x", x, public)
+		}
+	}
+}
+
+func TestStarkKeyFromEthPrivateKey(t *testing.T) {
+	ethKey, err := ethcrypto.HexToECDSA("4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318")
+	if err != nil {
+		t.Fatal(err)
+	}
+	message := []byte("Sign in to Starknet")
+	signature, err := SignEthMessage(ethKey, message)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := signature[ethcrypto.RecoveryIDOffset]; v != 27 && v != 28 {
+		t.Fatalf("v should be 27 or 28, instead: %d", v)
+	}
+	recovery := append([]byte{}, signature...)
+	recovery[ethcrypto.RecoveryIDOffset] -= 27
+	public, err := ethcrypto.SigToPub(accounts.TextHash(message), recovery)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ethcrypto.PubkeyToAddress(*public) != ethcrypto.PubkeyToAddress(ethKey.PublicKey) {
+		t.Fatal("signature should recover the ethereum address")
+	}
+
+	key, starkPublic, err := StarkKeyFromEthPrivateKey(ethKey, message)
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, _, err := StarkKeyFromEthPrivateKey(ethKey, message)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key.Cmp(again) != 0 {
+		t.Fatal("derivation should be deterministic")
+	}
+	fromSignature, _, err := StarkKeyFromEthSignature(signature)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key.Cmp(fromSignature) != 0 {
+		t.Fatal("keys from the private key and the signature should match")
+	}
+	other, _, err := StarkKeyFromEthPrivateKey(ethKey, []byte("another message"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(key.Bytes(), other.Bytes()) {
+		t.Fatal("another message should derive another key")
+	}
+
+	ks := NewMemKeystore()
+	ks.Put(starkPublic.Text(16), key)
+	hash := ethcrypto.Keccak256Hash(message).Big()
+	hash.Rsh(hash, 8)
+	if _, _, err := ks.Sign(context.Background(), starkPublic.Text(16), hash); err != nil {
+		t.Fatal("derived key should sign, instead:", err)
+	}
+}
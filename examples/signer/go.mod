@@ -0,0 +1,12 @@
+module github.com/sjxqqq/starknet-go/examples/signer
+
+go 1.18
+
+replace github.com/sjxqqq/starknet-go => ../../
+
+require github.com/sjxqqq/starknet-go v0.3.1
+
+require (
+	golang.org/x/crypto v0.0.0-20220525230936-793ad666bf5e // indirect
+	golang.org/x/sys v0.0.0-20220615213510-4f61da869c0c // indirect
+)
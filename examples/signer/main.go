@@ -0,0 +1,67 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	starknetgo "github.com/sjxqqq/starknet-go"
+)
+
+// signer serves the keys of a file keystore to remote keystores over mutual
+// TLS. Clients connect with:
+//
+//	starknetgo.NewRemoteKeystore("https://localhost:8443", starknetgo.WithRemoteTLS(config))
+func main() {
+	addr := flag.String("addr", "localhost:8443", "address to listen on")
+	dir := flag.String("keystore", "./keystore", "directory of the encrypted keys")
+	cert := flag.String("cert", "server.crt", "server certificate")
+	key := flag.String("key", "server.key", "server certificate key")
+	clientCA := flag.String("client-ca", "ca.crt", "CA of the accepted client certificates")
+	timeout := flag.Duration("unlock", time.Hour, "how long the keys stay unlocked")
+	flag.Parse()
+
+	ks := starknetgo.NewFileKeystore(*dir, starknetgo.StandardScryptN, starknetgo.StandardScryptP)
+	accounts, err := ks.Accounts()
+	if err != nil {
+		panic(err.Error())
+	}
+	passphrase := os.Getenv("KEYSTORE_PASSPHRASE")
+	for _, address := range accounts {
+		if err := ks.Unlock(address, passphrase, *timeout); err != nil {
+			panic(err.Error())
+		}
+		fmt.Println("unlocked", address)
+	}
+
+	content, err := os.ReadFile(*clientCA)
+	if err != nil {
+		panic(err.Error())
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(content) {
+		panic("invalid client CA")
+	}
+	handler, err := starknetgo.NewSignerServer(ks)
+	if err != nil {
+		panic(err.Error())
+	}
+	server := &http.Server{
+		Addr:    *addr,
+		Handler: handler,
+		TLSConfig: &tls.Config{
+			ClientAuth: tls.RequireAndVerifyClientCert,
+			ClientCAs:  pool,
+			MinVersion: tls.VersionTLS12,
+		},
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	fmt.Println("signer listening on", *addr)
+	if err := server.ListenAndServeTLS(*cert, *key); err != nil {
+		panic(err.Error())
+	}
+}
@@ -0,0 +1,221 @@
+package starknetgo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/sjxqqq/starknet-go/gateway"
+	"github.com/sjxqqq/starknet-go/rpc"
+	"github.com/sjxqqq/starknet-go/types"
+)
+
+// FeeRequest is the transaction a FeeStrategy chooses the max fee for.
+type FeeRequest struct {
+	Account *Account
+	// Version is the version of the transaction. Version 3 transactions pay
+	// their fee in fri (10^-18 STRK), the earlier versions in wei.
+	Version uint64
+	// Estimate returns the fee estimate of the transaction.
+	Estimate func(ctx context.Context) (*types.FeeEstimate, error)
+}
+
+// FeeStrategy chooses the max fee of the transactions sent with
+// Account.Execute when ExecuteDetails.MaxFee is not set, or the resource
+// bounds of a v3 transaction when ExecuteDetails.ResourceBounds is not set. It
+// returns the max fee and a human readable reason recorded in the execute
+// output.
+type FeeStrategy interface {
+	MaxFee(ctx context.Context, req FeeRequest) (*big.Int, string, error)
+}
+
+// FeeStrategyFunc is a callback used as a FeeStrategy.
+type FeeStrategyFunc func(ctx context.Context, req FeeRequest) (*big.Int, string, error)
+
+func (f FeeStrategyFunc) MaxFee(ctx context.Context, req FeeRequest) (*big.Int, string, error) {
+	return f(ctx, req)
+}
+
+// AccountFeeStrategy sets the FeeStrategy of the account. By default the
+// account pays up to twice the estimated fee.
+func AccountFeeStrategy(strategy FeeStrategy) AccountOptionFunc {
+	return func(*felt.Felt, *felt.Felt) (AccountOption, error) {
+		if strategy == nil {
+			return AccountOption{}, errors.New("nil fee strategy")
+		}
+		return AccountOption{
+			feeStrategy: strategy,
+		}, nil
+	}
+}
+
+type fixedFee struct {
+	maxFee *big.Int
+}
+
+// FixedFee always uses maxFee.
+func FixedFee(maxFee *big.Int) FeeStrategy {
+	return fixedFee{maxFee: new(big.Int).Set(maxFee)}
+}
+
+func (f fixedFee) MaxFee(context.Context, FeeRequest) (*big.Int, string, error) {
+	return new(big.Int).Set(f.maxFee), fmt.Sprintf("fixed max fee 0x%x", f.maxFee), nil
+}
+
+type feeMultiplier struct {
+	multiplier *big.Rat
+	cap        *big.Int
+}
+
+// FeeMultiplier multiplies the estimated fee by multiplier. The max fee never
+// exceeds cap, unless cap is nil.
+func FeeMultiplier(multiplier float64, cap *big.Int) FeeStrategy {
+	strategy := feeMultiplier{multiplier: new(big.Rat)}
+	if !math.IsNaN(multiplier) && !math.IsInf(multiplier, 0) {
+		strategy.multiplier.SetFloat64(multiplier)
+	}
+	if cap != nil {
+		strategy.cap = new(big.Int).Set(cap)
+	}
+	return strategy
+}
+
+func (f feeMultiplier) MaxFee(ctx context.Context, req FeeRequest) (*big.Int, string, error) {
+	if f.multiplier.Sign() <= 0 {
+		return nil, "", errors.New("fee multiplier should be positive")
+	}
+	estimate, err := req.Estimate(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+	overallFee, ok := new(big.Int).SetString(string(estimate.OverallFee), 0)
+	if !ok {
+		return nil, "", errors.New("could not match OverallFee to big.Int")
+	}
+	fee := new(big.Rat).Mul(new(big.Rat).SetInt(overallFee), f.multiplier)
+	maxFee := new(big.Int).Quo(fee.Num(), fee.Denom())
+	reason := fmt.Sprintf("estimated fee 0x%x x%s", overallFee, f.multiplier.FloatString(2))
+	if f.cap != nil && maxFee.Cmp(f.cap) > 0 {
+		return new(big.Int).Set(f.cap), reason + fmt.Sprintf(", capped at 0x%x", f.cap), nil
+	}
+	return maxFee, reason, nil
+}
+
+type gasPricePercentile struct {
+	percentile float64
+	blocks     int
+}
+
+// GasPricePercentile pays the estimated gas consumption at the percentile
+// (between 0 and 100) of the gas prices of the last blocks, in the fee unit of
+// the transaction version. With the RPC provider, the node must return the
+// block gas price, i.e. implement RPC v0.5, and its price in fri for version 3
+// transactions.
+func GasPricePercentile(percentile float64, blocks int) FeeStrategy {
+	return gasPricePercentile{percentile: percentile, blocks: blocks}
+}
+
+func (g gasPricePercentile) MaxFee(ctx context.Context, req FeeRequest) (*big.Int, string, error) {
+	if g.percentile <= 0 || g.percentile > 100 || g.blocks <= 0 {
+		return nil, "", fmt.Errorf("invalid gas price percentile %v over %d blocks", g.percentile, g.blocks)
+	}
+	estimate, err := req.Estimate(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+	gasConsumed, ok := new(big.Int).SetString(string(estimate.GasConsumed), 0)
+	if !ok {
+		return nil, "", errors.New("could not match GasConsumed to big.Int")
+	}
+	unit := feeUnit(req.Version)
+	prices, err := req.Account.recentGasPrices(ctx, g.blocks, unit)
+	if err != nil {
+		return nil, "", err
+	}
+	sort.Slice(prices, func(i, j int) bool {
+		return prices[i].Cmp(prices[j]) < 0
+	})
+	// nearest-rank percentile
+	rank := int(math.Ceil(g.percentile / 100 * float64(len(prices))))
+	if rank < 1 {
+		rank = 1
+	}
+	gasPrice := prices[rank-1]
+	reason := fmt.Sprintf("gas consumed 0x%x at p%v gas price 0x%x %s of the last %d blocks", gasConsumed, g.percentile, gasPrice, unit, len(prices))
+	return new(big.Int).Mul(gasConsumed, gasPrice), reason, nil
+}
+
+// feeUnit returns the unit of the fee of the transactions of version.
+func feeUnit(version uint64) string {
+	if version == 3 {
+		return "fri"
+	}
+	return "wei"
+}
+
+// recentGasPrices returns the gas prices in unit, "wei" or "fri", of up to
+// the last n blocks.
+func (account *Account) recentGasPrices(ctx context.Context, n int, unit string) ([]*big.Int, error) {
+	prices := []*big.Int{}
+	switch account.provider {
+	case ProviderRPC:
+		latest, err := account.rpc.BlockNumber(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for i := 0; i < n && uint64(i) <= latest; i++ {
+			result, err := account.rpc.BlockWithTxHashes(ctx, rpc.WithBlockNumber(latest-uint64(i)))
+			if err != nil {
+				return nil, err
+			}
+			block, ok := result.(*rpc.Block)
+			if !ok {
+				return nil, fmt.Errorf("unexpected block %T", result)
+			}
+			if block.L1GasPrice == nil {
+				return nil, fmt.Errorf("block %d has no gas price", block.BlockNumber)
+			}
+			price := block.L1GasPrice.PriceInWei
+			if unit == "fri" {
+				price = block.L1GasPrice.PriceInFRI
+			}
+			if price == nil {
+				return nil, fmt.Errorf("block %d has no gas price in %s", block.BlockNumber, unit)
+			}
+			prices = append(prices, price.BigInt(big.NewInt(0)))
+		}
+	case ProviderGateway:
+		if unit != "wei" {
+			return nil, fmt.Errorf("the gateway has no gas price in %s", unit)
+		}
+		latest, err := account.sequencer.Block(ctx, &gateway.BlockOptions{Tag: "latest"})
+		if err != nil {
+			return nil, err
+		}
+		for i := 0; i < n && i <= latest.BlockNumber; i++ {
+			block := latest
+			if i > 0 {
+				number := uint64(latest.BlockNumber - i)
+				block, err = account.sequencer.Block(ctx, &gateway.BlockOptions{BlockNumber: &number})
+				if err != nil {
+					return nil, err
+				}
+			}
+			price, ok := new(big.Int).SetString(block.GasPrice, 0)
+			if !ok {
+				return nil, fmt.Errorf("block %d has no gas price", block.BlockNumber)
+			}
+			prices = append(prices, price)
+		}
+	default:
+		return nil, ErrUnsupportedAccount
+	}
+	if len(prices) == 0 {
+		return nil, errors.New("no block to read the gas price from")
+	}
+	return prices, nil
+}
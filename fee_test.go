@@ -0,0 +1,229 @@
+package starknetgo
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/sjxqqq/starknet-go/rpc"
+	"github.com/sjxqqq/starknet-go/types"
+	"github.com/sjxqqq/starknet-go/utils"
+)
+
+func TestFeeStrategy(t *testing.T) {
+	type testSetType struct {
+		Strategy       FeeStrategy
+		ExpectedMaxFee *big.Int
+		ExpectedReason string
+		ExpectedError  bool
+	}
+	testSet := map[string][]testSetType{
+		"mock": {
+			{
+				Strategy:       FixedFee(big.NewInt(1000)),
+				ExpectedMaxFee: big.NewInt(1000),
+				ExpectedReason: "fixed max fee 0x3e8",
+			},
+			{
+				Strategy:       FeeMultiplier(1.5, nil),
+				ExpectedMaxFee: big.NewInt(150),
+				ExpectedReason: "estimated fee 0x64 x1.50",
+			},
+			{
+				Strategy:       FeeMultiplier(3, big.NewInt(250)),
+				ExpectedMaxFee: big.NewInt(250),
+				ExpectedReason: "estimated fee 0x64 x3.00, capped at 0xfa",
+			},
+			{
+				Strategy:       FeeMultiplier(2, big.NewInt(250)),
+				ExpectedMaxFee: big.NewInt(200),
+				ExpectedReason: "estimated fee 0x64 x2.00",
+			},
+			{
+				Strategy:      FeeMultiplier(0, nil),
+				ExpectedError: true,
+			},
+			{
+				Strategy: FeeStrategyFunc(func(ctx context.Context, req FeeRequest) (*big.Int, string, error) {
+					estimate, err := req.Estimate(ctx)
+					if err != nil {
+						return nil, "", err
+					}
+					return types.HexToBN(string(estimate.OverallFee)), "estimate as is", nil
+				}),
+				ExpectedMaxFee: big.NewInt(100),
+				ExpectedReason: "estimate as is",
+			},
+		},
+	}[testEnv]
+
+	req := FeeRequest{
+		Estimate: func(context.Context) (*types.FeeEstimate, error) {
+			return &types.FeeEstimate{
+				GasConsumed: "0xa",
+				GasPrice:    "0xa",
+				OverallFee:  "0x64",
+			}, nil
+		},
+	}
+	for _, test := range testSet {
+		maxFee, reason, err := test.Strategy.MaxFee(context.Background(), req)
+		if test.ExpectedError {
+			if err == nil {
+				t.Fatal("strategy should fail")
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal("strategy should succeed, instead:", err)
+		}
+		if maxFee.Cmp(test.ExpectedMaxFee) != 0 || reason != test.ExpectedReason {
+			t.Fatalf("expected %s (%s), instead: %s (%s)", test.ExpectedMaxFee, test.ExpectedReason, maxFee, reason)
+		}
+	}
+
+	estimateErr := errors.New("estimate failed")
+	_, _, err := FeeMultiplier(2, nil).MaxFee(context.Background(), FeeRequest{
+		Estimate: func(context.Context) (*types.FeeEstimate, error) {
+			return nil, estimateErr
+		},
+	})
+	if !errors.Is(err, estimateErr) {
+		t.Fatalf("error should be %v, instead: %v", estimateErr, err)
+	}
+}
+
+func TestFeeStrategy_GasPricePercentile(t *testing.T) {
+	type testSetType struct {
+		Percentile     float64
+		Blocks         int
+		ExpectedMaxFee *big.Int
+		ExpectedError  bool
+	}
+	testSet := map[string][]testSetType{
+		"mock": {
+			// last 4 blocks: 40, 10, 30, 20
+			{Percentile: 50, Blocks: 4, ExpectedMaxFee: big.NewInt(200)},
+			{Percentile: 100, Blocks: 4, ExpectedMaxFee: big.NewInt(400)},
+			{Percentile: 1, Blocks: 4, ExpectedMaxFee: big.NewInt(100)},
+			// only the latest block
+			{Percentile: 90, Blocks: 1, ExpectedMaxFee: big.NewInt(200)},
+			// more blocks than the chain holds
+			{Percentile: 100, Blocks: 100, ExpectedMaxFee: big.NewInt(500)},
+			{Percentile: 0, Blocks: 4, ExpectedError: true},
+			{Percentile: 50, Blocks: 0, ExpectedError: true},
+		},
+	}[testEnv]
+
+	account, mock := newMockRPCAccount(t, AccountVersion1)
+	mock.gasPrices = []uint64{50, 40, 10, 30, 20}
+	req := FeeRequest{
+		Account: account,
+		Estimate: func(context.Context) (*types.FeeEstimate, error) {
+			return &types.FeeEstimate{GasConsumed: "0xa"}, nil
+		},
+	}
+	for _, test := range testSet {
+		maxFee, _, err := GasPricePercentile(test.Percentile, test.Blocks).MaxFee(context.Background(), req)
+		if test.ExpectedError {
+			if err == nil {
+				t.Fatalf("p%v over %d blocks should fail", test.Percentile, test.Blocks)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal("strategy should succeed, instead:", err)
+		}
+		if maxFee.Cmp(test.ExpectedMaxFee) != 0 {
+			t.Fatalf("p%v over %d blocks should be %s, instead: %s", test.Percentile, test.Blocks, test.ExpectedMaxFee, maxFee)
+		}
+	}
+}
+
+func TestRPCAccount_ExecuteV3GasPricePercentile(t *testing.T) {
+	account, mock := newMockRPCAccount(t, AccountVersion3, AccountFeeStrategy(GasPricePercentile(100, 2)))
+	// the gas is a million times cheaper in wei than in fri
+	mock.gasPrices = []uint64{3, 1, 2}
+	mock.friPrices = []uint64{3000000, 1000000, 2000000}
+	mock.estimate = rpc.FeeEstimate{GasConsumed: "0x64", GasPrice: "0x1e8480", OverallFee: "0xbebc200"}
+
+	call := types.FunctionCall{
+		ContractAddress:    utils.TestHexToFelt(t, mockAccountAddress),
+		EntryPointSelector: types.GetSelectorFromNameFelt("increment"),
+	}
+	output, err := account.Execute(context.Background(), []types.FunctionCall{call}, types.ExecuteDetails{})
+	if err != nil {
+		t.Fatal("execute should succeed, instead:", err)
+	}
+	expectedReason := "gas consumed 0x64 at p100 gas price 0x1e8480 fri of the last 2 blocks"
+	if output.MaxFee.Cmp(big.NewInt(200000000)) != 0 || output.FeeReason != expectedReason {
+		t.Fatalf("max fee should be 200000000 (%s), instead: %s (%s)", expectedReason, output.MaxFee, output.FeeReason)
+	}
+	var tx rpc.BroadcastedInvokeV3Transaction
+	mock.lastReceived(t, &tx)
+	expected := rpc.ResourceBounds{MaxAmount: "0x64", MaxPricePerUnit: "0x1e8480"}
+	if tx.ResourceBounds.L1Gas != expected {
+		t.Fatalf("l1 gas bounds should be %+v, instead: %+v", expected, tx.ResourceBounds.L1Gas)
+	}
+
+	// blocks without a price in fri can't price a v3 transaction
+	mock.friPrices = nil
+	if _, err := account.Execute(context.Background(), []types.FunctionCall{call}, types.ExecuteDetails{}); err == nil {
+		t.Fatal("execute without gas price in fri should fail")
+	}
+}
+
+func TestRPCAccount_ExecuteFeeStrategy(t *testing.T) {
+	type testSetType struct {
+		Options        []AccountOptionFunc
+		Details        types.ExecuteDetails
+		ExpectedMaxFee string
+		ExpectedReason string
+	}
+	testSet := map[string][]testSetType{
+		"mock": {
+			{
+				Options:        []AccountOptionFunc{AccountVersion1},
+				ExpectedMaxFee: "0xc8",
+				ExpectedReason: "estimated fee 0x64 x2.00",
+			},
+			{
+				Options:        []AccountOptionFunc{AccountVersion1, AccountFeeStrategy(FixedFee(big.NewInt(1234)))},
+				ExpectedMaxFee: "0x4d2",
+				ExpectedReason: "fixed max fee 0x4d2",
+			},
+			{
+				Options:        []AccountOptionFunc{AccountVersion1, AccountFeeStrategy(FixedFee(big.NewInt(1234)))},
+				Details:        types.ExecuteDetails{MaxFee: big.NewInt(42)},
+				ExpectedMaxFee: "0x2a",
+				ExpectedReason: "max fee set in the execute details",
+			},
+		},
+	}[testEnv]
+
+	call := types.FunctionCall{
+		ContractAddress:    utils.TestHexToFelt(t, mockAccountAddress),
+		EntryPointSelector: types.GetSelectorFromNameFelt("increment"),
+	}
+	for _, test := range testSet {
+		account, mock := newMockRPCAccount(t, test.Options...)
+		mock.estimate = rpc.FeeEstimate{GasConsumed: "0xa", GasPrice: "0xa", OverallFee: "0x64"}
+		output, err := account.Execute(context.Background(), []types.FunctionCall{call}, test.Details)
+		if err != nil {
+			t.Fatal("execute should succeed, instead:", err)
+		}
+		if output.MaxFee == nil || "0x"+output.MaxFee.Text(16) != test.ExpectedMaxFee || output.FeeReason != test.ExpectedReason {
+			t.Fatalf("expected %s (%s), instead: %v (%s)", test.ExpectedMaxFee, test.ExpectedReason, output.MaxFee, output.FeeReason)
+		}
+		var tx rpc.BroadcastedInvokeV1Transaction
+		mock.lastReceived(t, &tx)
+		if tx.MaxFee.String() != test.ExpectedMaxFee {
+			t.Fatalf("max fee %s should be broadcast, instead: %s", test.ExpectedMaxFee, tx.MaxFee)
+		}
+	}
+
+	if _, err := newAccount(nil, nil, nil, AccountFeeStrategy(nil)); err == nil {
+		t.Fatal("nil fee strategy should fail")
+	}
+}
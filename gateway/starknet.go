@@ -124,11 +124,9 @@ func (sg *Gateway) Invoke(ctx context.Context, invoke types.FunctionInvoke) (*ty
 	}
 	tx.Calldata = calldata
 
-	if len(invoke.Signature) == 0 {
-		tx.Signature = []string{}
-	} else {
-		// stop-gap before full *felt.Felt cutover
-		tx.Signature = []string{invoke.Signature[0].String(), invoke.Signature[1].String()}
+	tx.Signature = []string{}
+	for _, value := range invoke.Signature {
+		tx.Signature = append(tx.Signature, value.String())
 	}
 
 	req, err := sg.newRequest(ctx, http.MethodPost, "/add_transaction", tx)
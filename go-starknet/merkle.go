@@ -0,0 +1,127 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/sjxqqq/starknet-go/airdrop"
+	"github.com/urfave/cli/v2"
+)
+
+var merkleBuildFlags = []cli.Flag{
+	&cli.StringFlag{
+		Name:     "csv",
+		Usage:    "CSV of the address,amount[,extra] claims",
+		Required: true,
+	},
+	&cli.StringFlag{
+		Name:  "out",
+		Usage: "file of the root and proofs by address",
+		Value: "proofs.json",
+	},
+}
+
+var merkleVerifyFlags = []cli.Flag{
+	&cli.StringFlag{
+		Name:     "proofs",
+		Usage:    "file of the proofs written by merkle build",
+		Required: true,
+	},
+	&cli.StringFlag{
+		Name:     "address",
+		Usage:    "address of the claim to verify",
+		Required: true,
+	},
+	&cli.StringFlag{
+		Name:  "root",
+		Usage: "root of the airdrop contract; defaults to the root of the proofs",
+	},
+}
+
+var merkleCommand = cli.Command{
+	Name:    "merkle",
+	Aliases: []string{"m"},
+	Usage:   "build and verify the merkle proofs of airdrops",
+	Subcommands: []*cli.Command{
+		{
+			Name:   "build",
+			Usage:  "build the root and proofs of the claims of a CSV",
+			Flags:  merkleBuildFlags,
+			Action: merkleBuild,
+		},
+		{
+			Name:   "verify",
+			Usage:  "verify the claim of an address offline",
+			Flags:  merkleVerifyFlags,
+			Action: merkleVerify,
+		},
+	},
+}
+
+func merkleBuild(cCtx *cli.Context) error {
+	file, err := os.Open(cCtx.String("csv"))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	claims, err := airdrop.ReadCSV(file)
+	if err != nil {
+		return err
+	}
+	drop, err := airdrop.New(claims)
+	if err != nil {
+		return err
+	}
+	proofs, err := drop.Proofs()
+	if err != nil {
+		return err
+	}
+	content, err := json.MarshalIndent(proofs, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(cCtx.String("out"), content, 0o644); err != nil {
+		return err
+	}
+	fmt.Printf("claims: %d\n", len(claims))
+	fmt.Printf("root:   %s\n", proofs.Root)
+	fmt.Printf("proofs: %s\n", cCtx.String("out"))
+	return nil
+}
+
+func merkleVerify(cCtx *cli.Context) error {
+	content, err := os.ReadFile(cCtx.String("proofs"))
+	if err != nil {
+		return err
+	}
+	proofs := airdrop.Proofs{}
+	if err := json.Unmarshal(content, &proofs); err != nil {
+		return err
+	}
+	address, ok := big.NewInt(0).SetString(cCtx.String("address"), 0)
+	if !ok {
+		return errors.New("not a number")
+	}
+	root := proofs.Root
+	if cCtx.IsSet("root") {
+		root = cCtx.String("root")
+	}
+	rootInt, ok := big.NewInt(0).SetString(root, 0)
+	if !ok {
+		return errors.New("not a number")
+	}
+	proof, err := proofs.Claim(address)
+	if err != nil {
+		return err
+	}
+	if !airdrop.Verify(rootInt, proof) {
+		return errors.New("invalid claim")
+	}
+	fmt.Printf("amount: 0x%s\n", proof.Amount.Text(16))
+	fmt.Println("claim is valid")
+	fmt.Println()
+	return nil
+}
@@ -4,26 +4,35 @@ import (
 	"fmt"
 	"math/big"
 
+	"github.com/NethermindEth/juno/core/felt"
 	"github.com/sjxqqq/starknet-go/types"
+	"github.com/sjxqqq/starknet-go/utils"
 )
 
-func fmtCalldataStrings(calls []types.FunctionCall) (calldataStrings []string) {
-	callArray := fmtCalldata(calls)
+func fmtCalldataStrings(callArray []*big.Int) (calldataStrings []string) {
 	for _, data := range callArray {
 		calldataStrings = append(calldataStrings, fmt.Sprintf("0x%x", data))
 	}
 	return calldataStrings
 }
 
+// CallEncoder formats the calls of a multicall into the calldata of the
+// account __execute__ entry point. The same calldata is hashed, signed and
+// sent to the network.
+type CallEncoder func(calls []types.FunctionCall) []*big.Int
+
 /*
 Formats the multicall transactions in a format which can be signed and verified by the network and OpenZeppelin account contracts
+written in Cairo 0: a call array with the offset of the data of each call,
+followed by the concatenated calldata.
 */
-func fmtCalldata(calls []types.FunctionCall) (calldataArray []*big.Int) {
+func EncodeCallsCairo0(calls []types.FunctionCall) []*big.Int {
 	callArray := []*big.Int{big.NewInt(int64(len(calls)))}
+	calldataArray := []*big.Int{}
 
 	for _, tx := range calls {
 		address := tx.ContractAddress.BigInt(big.NewInt(0))
-		callArray = append(callArray, address, types.GetSelectorFromName(tx.EntryPointSelector.String()))
+		callArray = append(callArray, address, tx.EntryPointSelector.BigInt(big.NewInt(0)))
 
 		if len(tx.Calldata) == 0 {
 			callArray = append(callArray, big.NewInt(0), big.NewInt(0))
@@ -33,7 +42,7 @@ func fmtCalldata(calls []types.FunctionCall) (calldataArray []*big.Int) {
 
 		callArray = append(callArray, big.NewInt(int64(len(calldataArray))), big.NewInt(int64(len(tx.Calldata))))
 		for _, cd := range tx.Calldata {
-			calldataArray = append(calldataArray, types.SNValToBN(cd.String()))
+			calldataArray = append(calldataArray, cd.BigInt(big.NewInt(0)))
 		}
 	}
 
@@ -41,3 +50,67 @@ func fmtCalldata(calls []types.FunctionCall) (calldataArray []*big.Int) {
 	callArray = append(callArray, calldataArray...)
 	return callArray
 }
+
+/*
+Formats the multicall transactions as the Array<Call> expected by Cairo 1
+accounts (OpenZeppelin, Argent, Braavos): every call is serialized with its own
+calldata span.
+*/
+func EncodeCallsCairo1(calls []types.FunctionCall) []*big.Int {
+	callArray := []*big.Int{big.NewInt(int64(len(calls)))}
+
+	for _, tx := range calls {
+		callArray = append(callArray,
+			tx.ContractAddress.BigInt(big.NewInt(0)),
+			tx.EntryPointSelector.BigInt(big.NewInt(0)),
+			big.NewInt(int64(len(tx.Calldata))),
+		)
+		for _, cd := range tx.Calldata {
+			callArray = append(callArray, cd.BigInt(big.NewInt(0)))
+		}
+	}
+	return callArray
+}
+
+// l2AddressUpperBound is 2**251 - 256, the upper bound of a contract address.
+var l2AddressUpperBound = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 251), big.NewInt(256))
+
+/*
+Computes the address of a contract deployed by deployerAddress with the given
+salt, class hash and constructor calldata. deployerAddress is 0 for
+DEPLOY_ACCOUNT transactions and for non-unique UDC deployments.
+
+(ref: https://github.com/starkware-libs/cairo-lang/blob/master/src/starkware/starknet/core/os/contract_address/contract_address.py)
+*/
+func ContractAddress(deployerAddress, salt, classHash *big.Int, constructorCalldata []*big.Int) (*big.Int, error) {
+	constructorCalldataHash, err := Curve.ComputeHashOnElements(constructorCalldata)
+	if err != nil {
+		return nil, err
+	}
+	address, err := Curve.ComputeHashOnElements([]*big.Int{
+		types.UTF8StrToBig(CONTRACT_ADDRESS_PREFIX),
+		deployerAddress,
+		salt,
+		classHash,
+		constructorCalldataHash,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return address.Mod(address, l2AddressUpperBound), nil
+}
+
+// PrecomputeAccountAddress returns the counterfactual address of an account
+// deployed with a DEPLOY_ACCOUNT transaction, so it can be funded before the
+// account is deployed.
+func PrecomputeAccountAddress(salt, classHash *felt.Felt, constructorCalldata []*felt.Felt) (*felt.Felt, error) {
+	calldata := make([]*big.Int, len(constructorCalldata))
+	for i, value := range constructorCalldata {
+		calldata[i] = value.BigInt(big.NewInt(0))
+	}
+	address, err := ContractAddress(big.NewInt(0), salt.BigInt(big.NewInt(0)), classHash.BigInt(big.NewInt(0)), calldata)
+	if err != nil {
+		return nil, err
+	}
+	return utils.BigIntToFelt(address)
+}
@@ -0,0 +1,96 @@
+package starknetgo
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/sjxqqq/starknet-go/types"
+	"github.com/sjxqqq/starknet-go/utils"
+)
+
+// TestContractAddress checks the address of a contract deployed on mainnet.
+func TestContractAddress(t *testing.T) {
+	calldata := []*big.Int{
+		types.HexToBN("0x6d706cfbac9b8262d601c38251c5fbe0497c3a96cc91a92b08d91b61d9e70c4"),
+		types.HexToBN("0x79dc0da7c54b95f10aa182ad0a46400db63156920adb65eca2654c0945a463"),
+		types.HexToBN("0x2"),
+		types.HexToBN("0x6658165b4984816ab189568637bedec5aa0a18305909c7f5726e4a16e3afef6"),
+		types.HexToBN("0x6b648b36b074a91eee55730f5f5e075ec19c0a8f9ffb0903cefeee93b6ff328"),
+	}
+	address, err := ContractAddress(
+		big.NewInt(0),
+		types.HexToBN("0x74dc2fe193daf1abd8241b63329c1123214842b96ad7fd003d25512598a956b"),
+		types.HexToBN("0x46f844ea1a3b3668f81d38b5c1bd55e816e0373802aefe732138628f0133486"),
+		calldata,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := types.HexToBN("0x3ec215c6c9028ff671b46a2a9814970ea23ed3c4bcc3838c6d1dcbf395263c3")
+	if address.Cmp(expected) != 0 {
+		t.Fatalf("address should be 0x%x, instead: 0x%x", expected, address)
+	}
+}
+
+func TestEncodeCalls(t *testing.T) {
+	calls := []types.FunctionCall{
+		{
+			ContractAddress:    utils.TestHexToFelt(t, "0x1"),
+			EntryPointSelector: types.GetSelectorFromNameFelt("transfer"),
+			Calldata:           []*felt.Felt{utils.TestHexToFelt(t, "0xa"), utils.TestHexToFelt(t, "0xb")},
+		},
+		{
+			ContractAddress:    utils.TestHexToFelt(t, "0x2"),
+			EntryPointSelector: types.GetSelectorFromNameFelt("increment"),
+		},
+		{
+			ContractAddress:    utils.TestHexToFelt(t, "0x3"),
+			EntryPointSelector: types.GetSelectorFromNameFelt("approve"),
+			Calldata:           []*felt.Felt{utils.TestHexToFelt(t, "0xc")},
+		},
+	}
+	transfer := types.GetSelectorFromName("transfer")
+	increment := types.GetSelectorFromName("increment")
+	approve := types.GetSelectorFromName("approve")
+
+	type testSetType struct {
+		Encoder  CallEncoder
+		Expected []*big.Int
+	}
+	testSet := map[string][]testSetType{
+		"mock": {
+			{
+				Encoder: EncodeCallsCairo0,
+				Expected: []*big.Int{
+					big.NewInt(3),
+					big.NewInt(1), transfer, big.NewInt(0), big.NewInt(2),
+					big.NewInt(2), increment, big.NewInt(0), big.NewInt(0),
+					big.NewInt(3), approve, big.NewInt(2), big.NewInt(1),
+					big.NewInt(3), big.NewInt(0xa), big.NewInt(0xb), big.NewInt(0xc),
+				},
+			},
+			{
+				Encoder: EncodeCallsCairo1,
+				Expected: []*big.Int{
+					big.NewInt(3),
+					big.NewInt(1), transfer, big.NewInt(2), big.NewInt(0xa), big.NewInt(0xb),
+					big.NewInt(2), increment, big.NewInt(0),
+					big.NewInt(3), approve, big.NewInt(1), big.NewInt(0xc),
+				},
+			},
+		},
+	}[testEnv]
+
+	for _, test := range testSet {
+		calldata := test.Encoder(calls)
+		if len(calldata) != len(test.Expected) {
+			t.Fatalf("calldata should have %d elements, instead: %d", len(test.Expected), len(calldata))
+		}
+		for i := range calldata {
+			if calldata[i].Cmp(test.Expected[i]) != 0 {
+				t.Fatalf("calldata[%d] should be 0x%x, instead: 0x%x", i, test.Expected[i], calldata[i])
+			}
+		}
+	}
+}
@@ -0,0 +1,193 @@
+package starknetgo
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+)
+
+// HardenedOffset is added to the index of hardened BIP-32 children.
+const HardenedOffset = 0x80000000
+
+// StarknetCoinType is the SLIP-44 coin type of Starknet, used by the wallets
+// deriving account keys along m/44'/9004'/0'/0/index.
+const StarknetCoinType = 9004
+
+var ErrInvalidPath = errors.New("invalid derivation path")
+
+// HDKey is a BIP-32 extended private key on the secp256k1 curve.
+type HDKey struct {
+	key       *big.Int
+	chainCode []byte
+}
+
+// NewMasterKey returns the BIP-32 master key of seed, e.g. the seed of a
+// BIP-39 mnemonic.
+func NewMasterKey(seed []byte) (*HDKey, error) {
+	if len(seed) < 16 || len(seed) > 64 {
+		return nil, fmt.Errorf("seed of %d bytes unsupported", len(seed))
+	}
+	mac := hmac.New(sha512.New, []byte("Bitcoin seed"))
+	mac.Write(seed)
+	sum := mac.Sum(nil)
+	key := new(big.Int).SetBytes(sum[:32])
+	if key.Sign() == 0 || key.Cmp(ethcrypto.S256().Params().N) >= 0 {
+		return nil, errors.New("invalid master key")
+	}
+	return &HDKey{key: key, chainCode: sum[32:]}, nil
+}
+
+// PrivateKey returns the secp256k1 private key of k.
+func (k *HDKey) PrivateKey() *big.Int {
+	return new(big.Int).Set(k.key)
+}
+
+// Child returns the child key of k at index. Indexes from HardenedOffset are
+// hardened children.
+func (k *HDKey) Child(index uint32) (*HDKey, error) {
+	data := make([]byte, 0, 37)
+	if index >= HardenedOffset {
+		data = append(data, 0)
+		data = append(data, k.key.FillBytes(make([]byte, 32))...)
+	} else {
+		private, err := ethcrypto.ToECDSA(k.key.FillBytes(make([]byte, 32)))
+		if err != nil {
+			return nil, err
+		}
+		data = append(data, ethcrypto.CompressPubkey(&private.PublicKey)...)
+	}
+	data = append(data, byte(index>>24), byte(index>>16), byte(index>>8), byte(index))
+	mac := hmac.New(sha512.New, k.chainCode)
+	mac.Write(data)
+	sum := mac.Sum(nil)
+
+	n := ethcrypto.S256().Params().N
+	tweak := new(big.Int).SetBytes(sum[:32])
+	if tweak.Cmp(n) >= 0 {
+		return nil, fmt.Errorf("invalid child %d", index)
+	}
+	key := new(big.Int).Add(tweak, k.key)
+	key.Mod(key, n)
+	if key.Sign() == 0 {
+		return nil, fmt.Errorf("invalid child %d", index)
+	}
+	return &HDKey{key: key, chainCode: sum[32:]}, nil
+}
+
+// Derive returns the descendant of k along path, e.g. m/44'/9004'/0'/0/0.
+func (k *HDKey) Derive(path string) (*HDKey, error) {
+	indexes, err := ParseDerivationPath(path)
+	if err != nil {
+		return nil, err
+	}
+	key := k
+	for _, index := range indexes {
+		if key, err = key.Child(index); err != nil {
+			return nil, err
+		}
+	}
+	return key, nil
+}
+
+// ParseDerivationPath returns the child indexes of a BIP-32 path. Hardened
+// indexes are marked with ' or h.
+func ParseDerivationPath(path string) ([]uint32, error) {
+	parts := strings.Split(strings.TrimSpace(path), "/")
+	if parts[0] != "m" {
+		return nil, fmt.Errorf("%w %q: should start with m", ErrInvalidPath, path)
+	}
+	indexes := make([]uint32, 0, len(parts)-1)
+	for _, part := range parts[1:] {
+		offset := uint64(0)
+		if strings.HasSuffix(part, "'") || strings.HasSuffix(part, "h") {
+			offset = HardenedOffset
+			part = part[:len(part)-1]
+		}
+		index, err := strconv.ParseUint(part, 10, 32)
+		if err != nil || index >= HardenedOffset {
+			return nil, fmt.Errorf("%w %q: bad index %q", ErrInvalidPath, path, part)
+		}
+		indexes = append(indexes, uint32(index+offset))
+	}
+	return indexes, nil
+}
+
+// StarknetAccountPath returns the m/44'/9004'/0'/0/index path of the account
+// keys derived by Argent X and Braavos.
+func StarknetAccountPath(index uint32) string {
+	return fmt.Sprintf("m/44'/%d'/0'/0/%d", StarknetCoinType, index)
+}
+
+// eip2645Index returns the low 31 bits of value.
+func eip2645Index(value *big.Int) uint64 {
+	return new(big.Int).And(value, big.NewInt(HardenedOffset-1)).Uint64()
+}
+
+// EIP2645Path returns the m/2645'/layer'/application'/eth_address_1'/
+// eth_address_2'/index path of EIP-2645. layer and application are hashed
+// with sha256 and ethAddress split in two 31 bits parts.
+func EIP2645Path(layer, application, ethAddress string, index uint32) (string, error) {
+	address, ok := new(big.Int).SetString(strings.TrimPrefix(strings.ToLower(ethAddress), "0x"), 16)
+	if !ok {
+		return "", fmt.Errorf("invalid ethereum address %q", ethAddress)
+	}
+	layerHash := sha256.Sum256([]byte(layer))
+	applicationHash := sha256.Sum256([]byte(application))
+	return fmt.Sprintf(
+		"m/2645'/%d'/%d'/%d'/%d'/%d",
+		eip2645Index(new(big.Int).SetBytes(layerHash[:])),
+		eip2645Index(new(big.Int).SetBytes(applicationHash[:])),
+		eip2645Index(address),
+		eip2645Index(new(big.Int).Rsh(address, 31)),
+		index,
+	), nil
+}
+
+// GrindKey turns a secp256k1 private key into a Stark private key, hashing it
+// with an increasing index until the hash is below the largest multiple of
+// the curve order under 2^256, so the key is uniformly distributed.
+func GrindKey(seed *big.Int) (*big.Int, error) {
+	if seed.Sign() < 0 || seed.BitLen() > 256 {
+		return nil, errors.New("seed out of range")
+	}
+	limit := new(big.Int).Lsh(big.NewInt(1), 256)
+	limit.Sub(limit, new(big.Int).Mod(limit, Curve.N))
+	seedBytes := seed.FillBytes(make([]byte, 32))
+	for i := int64(0); ; i++ {
+		data := append(append([]byte{}, seedBytes...), big.NewInt(i).Bytes()...)
+		if i == 0 {
+			data = append(data, 0)
+		}
+		hash := sha256.Sum256(data)
+		key := new(big.Int).SetBytes(hash[:])
+		if key.Cmp(limit) < 0 {
+			return key.Mod(key, Curve.N), nil
+		}
+	}
+}
+
+// StarkKeyFromMnemonic returns the Stark private key derived from a BIP-39
+// mnemonic along path, e.g. StarknetAccountPath(0). It can be stored with
+// MemKeystore.Put or FileKeystore.Import under its public key.
+func StarkKeyFromMnemonic(mnemonic, passphrase, path string) (*big.Int, error) {
+	seed, err := MnemonicToSeed(mnemonic, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	master, err := NewMasterKey(seed)
+	if err != nil {
+		return nil, err
+	}
+	key, err := master.Derive(path)
+	if err != nil {
+		return nil, err
+	}
+	return GrindKey(key.PrivateKey())
+}
@@ -0,0 +1,166 @@
+package starknetgo
+
+import (
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+// TestMnemonic checks the BIP-39 reference vectors, with the TREZOR
+// passphrase.
+func TestMnemonic(t *testing.T) {
+	type testSetType struct {
+		Entropy  string
+		Mnemonic string
+		Seed     string
+	}
+	testSet := map[string][]testSetType{
+		"mock": {
+			{
+				Entropy:  "00000000000000000000000000000000",
+				Mnemonic: "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about",
+				Seed:     "c55257c360c07c72029aebc1b53c05ed0362ada38ead3e3e9efa3708e53495531f09a6987599d18264c1e1c92f2cf141630c7a3c4ab7c81b2f001698e7463b04",
+			},
+			{
+				Entropy:  "7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f",
+				Mnemonic: "legal winner thank year wave sausage worth useful legal winner thank yellow",
+				Seed:     "2e8905819b8723fe2c1d161860e5ee1830318dbf49a83bd451cfb8440c28bd6fa457fe1296106559a3c80937a1c1069be3a3a5bd381ee6260e8d9739fce1f607",
+			},
+			{
+				Entropy:  "80808080808080808080808080808080",
+				Mnemonic: "letter advice cage absurd amount doctor acoustic avoid letter advice cage above",
+				Seed:     "d71de856f81a8acc65e6fc851a38d4d7ec216fd0796d0a6827a3ad6ed5511a30fa280f12eb2e47ed2ac03b5c462a0358d18d69fe4f985ec81778c1b370b652a8",
+			},
+			{
+				Entropy:  "ffffffffffffffffffffffffffffffff",
+				Mnemonic: "zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo wrong",
+				Seed:     "ac27495480225222079d7be181583751e86f571027b0497b5b5d11218e0a8a13332572917f0f8e5a589620c6f15b11c61dee327651a14c34e18231052e48c069",
+			},
+		},
+	}[testEnv]
+
+	for _, test := range testSet {
+		entropy, _ := hex.DecodeString(test.Entropy)
+		mnemonic, err := MnemonicFromEntropy(entropy)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if mnemonic != test.Mnemonic {
+			t.Fatalf("mnemonic should be %q, instead: %q", test.Mnemonic, mnemonic)
+		}
+		decoded, err := MnemonicToEntropy(mnemonic)
+		if err != nil || hex.EncodeToString(decoded) != test.Entropy {
+			t.Fatalf("entropy should be %s, instead: %x, %v", test.Entropy, decoded, err)
+		}
+		seed, err := MnemonicToSeed(mnemonic, "TREZOR")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if hex.EncodeToString(seed) != test.Seed {
+			t.Fatalf("seed should be %s, instead: %x", test.Seed, seed)
+		}
+	}
+
+	mnemonic, err := NewMnemonic(256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(strings.Fields(mnemonic)) != 24 || ValidateMnemonic(mnemonic) != nil {
+		t.Fatalf("unexpected mnemonic %q", mnemonic)
+	}
+	for _, invalid := range []string{
+		"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon",
+		"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon",
+		"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon starknet",
+	} {
+		if err := ValidateMnemonic(invalid); !errors.Is(err, ErrInvalidMnemonic) {
+			t.Fatalf("%q should be invalid, instead: %v", invalid, err)
+		}
+	}
+}
+
+// TestHDKey checks the first BIP-32 reference vector.
+func TestHDKey(t *testing.T) {
+	type testSetType struct {
+		Path     string
+		Expected string
+	}
+	testSet := map[string][]testSetType{
+		"mock": {
+			{Path: "m", Expected: "e8f32e723decf4051aefac8e2c93c9c5b214313817cdb01a1494b917c8436b35"},
+			{Path: "m/0'", Expected: "edb2e14f9ee77d26dd93b4ecede8d16ed408ce149b6cd80b0715a2d911a0afea"},
+			{Path: "m/0'/1", Expected: "3c6cb8d0f6a264c91ea8b5030fadaa8e538b020f0a387421a12de9319dc93368"},
+			{Path: "m/0h/1/2h", Expected: "cbce0d719ecf7431d88e6a89fa1483e02e35092af60c042b1df2ff59fa424dca"},
+			{Path: "m/0'/1/2'/2", Expected: "0f479245fb19a38a1954c5c7c0ebab2f9bdfd96a17563ef28a6a4b1a2a764ef4"},
+			{Path: "m/0'/1/2'/2/1000000000", Expected: "471b76e389e528d6de6d816857e012c5455051cad6660850e58372a6c3e6e7c8"},
+		},
+	}[testEnv]
+
+	seed, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	master, err := NewMasterKey(seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range testSet {
+		key, err := master.Derive(test.Path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := hex.EncodeToString(key.PrivateKey().FillBytes(make([]byte, 32))); got != test.Expected {
+			t.Fatalf("%s should be %s, instead: %s", test.Path, test.Expected, got)
+		}
+	}
+	for _, invalid := range []string{"", "0/1", "m/x", "m/2147483648", "m/1''"} {
+		if _, err := master.Derive(invalid); !errors.Is(err, ErrInvalidPath) {
+			t.Fatalf("%q should be invalid, instead: %v", invalid, err)
+		}
+	}
+}
+
+func TestEIP2645Path(t *testing.T) {
+	path, err := EIP2645Path("starkex", "starkdeployement", "0xa4864d977b944315389d1765ffa7e66F74ee8cd7", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "m/2645'/579218131'/891216374'/1961790679'/2135936222'/0"
+	if path != expected {
+		t.Fatalf("path should be %s, instead: %s", expected, path)
+	}
+	if StarknetAccountPath(3) != "m/44'/9004'/0'/0/3" {
+		t.Fatalf("unexpected account path %s", StarknetAccountPath(3))
+	}
+}
+
+func TestGrindKey(t *testing.T) {
+	seed, _ := new(big.Int).SetString("86F3E7293141F20A8BAFF320E8EE4ACCB9D4A4BF2B4D295E8CEE784DB46E0519", 16)
+	key, err := GrindKey(seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key.Text(16) != "5c8c8683596c732541a59e03007b2d30dbbbb873556fe65b5fb63c16688f941" {
+		t.Fatalf("unexpected key %x", key)
+	}
+
+	// the derived key signs like any other Stark key
+	key, err = StarkKeyFromMnemonic("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about", "", StarknetAccountPath(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key.Sign() <= 0 || key.Cmp(Curve.N) >= 0 {
+		t.Fatalf("key %x should be in the curve order", key)
+	}
+	x, y, err := Curve.PrivateToPoint(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash := big.NewInt(42)
+	r, s, err := Curve.Sign(hash, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !Curve.Verify(hash, r, s, x, y) {
+		t.Fatal("derived key should sign")
+	}
+}
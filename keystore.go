@@ -6,12 +6,195 @@ import (
 	"fmt"
 	"math/big"
 	"sync"
+
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/sjxqqq/starknet-go/rpc"
+	"github.com/sjxqqq/starknet-go/types"
+	"github.com/sjxqqq/starknet-go/utils"
 )
 
 type Keystore interface {
 	Sign(ctx context.Context, id string, msgHash *big.Int) (x *big.Int, y *big.Int, err error)
 }
 
+// Signer signs transaction hashes with signatures of any length, e.g. the
+// owner and guardian signatures of an Argent account or the signatures of a
+// multisig account. The account sends the signature as is.
+type Signer interface {
+	SignTransaction(ctx context.Context, id string, msgHash *big.Int) ([]*felt.Felt, error)
+}
+
+// SignRequest is a transaction or message hash to sign with the transaction
+// or message it was computed from, so the keystore can decide whether to
+// sign it. The fields hold every input of the hash, which TransactionHash
+// recomputes.
+type SignRequest struct {
+	ID      string
+	Hash    *big.Int
+	Type    rpc.TransactionType
+	Version rpc.TransactionVersion
+	// SenderAddress is the address of the account sending the transaction.
+	SenderAddress *felt.Felt
+	// Calls are the calls of an invoke transaction, including the call of
+	// the account plugin.
+	Calls []types.FunctionCall
+	// Calldata is the calldata of the transaction: the encoded Calls of an
+	// invoke transaction or the constructor calldata of a deploy-account
+	// transaction.
+	Calldata []*felt.Felt
+	// ClassHash is the class of a declare or deploy-account transaction.
+	ClassHash *felt.Felt
+	// CompiledClassHash is the compiled class of a DECLARE v2 or v3.
+	CompiledClassHash *felt.Felt
+	// ContractAddressSalt is the salt of a deploy-account transaction.
+	ContractAddressSalt *felt.Felt
+	// MaxFee is the max fee of the transaction in wei, or the most a v3
+	// transaction can pay in fri with its resource bounds and tip.
+	MaxFee  *big.Int
+	Nonce   *big.Int
+	ChainID string
+	// The fields below are the fee fields of a v3 transaction, as in
+	// types.ExecuteDetails.
+	ResourceBounds            *types.ResourceBoundsMapping
+	Tip                       uint64
+	PaymasterData             []*felt.Felt
+	AccountDeploymentData     []*felt.Felt
+	NonceDataAvailabilityMode types.DataAvailabilityMode
+	FeeDataAvailabilityMode   types.DataAvailabilityMode
+	// TypedData is the message of the hash of SignTypedData, nil for the
+	// transactions.
+	TypedData *TypedData
+}
+
+// TransactionHash computes the hash of the transaction of req from its fields,
+// so a keystore can check req.Hash is the hash of the transaction it judges.
+func (req SignRequest) TransactionHash() (*big.Int, error) {
+	if req.SenderAddress == nil || req.Nonce == nil {
+		return nil, errors.New("sign request without sender address or nonce")
+	}
+	switch req.Type {
+	case rpc.TransactionType_Declare:
+		if req.ClassHash == nil || (req.Version != rpc.TransactionV1 && req.CompiledClassHash == nil) {
+			return nil, errors.New("declare request without class hash")
+		}
+	case rpc.TransactionType_DeployAccount:
+		if req.ClassHash == nil || req.ContractAddressSalt == nil {
+			return nil, errors.New("deploy account request without class hash or salt")
+		}
+	}
+	switch req.Version {
+	case rpc.TransactionV3, rpc.TransactionV3WithQueryBit:
+		if req.MaxFee == nil || req.ResourceBounds == nil || req.MaxFee.Cmp(maxFeeV3(req.ResourceBounds, req.Tip)) != 0 {
+			return nil, errors.New("max fee does not match the resource bounds")
+		}
+		details := types.ExecuteDetails{
+			Nonce:                     req.Nonce,
+			ResourceBounds:            req.ResourceBounds,
+			Tip:                       req.Tip,
+			PaymasterData:             req.PaymasterData,
+			AccountDeploymentData:     req.AccountDeploymentData,
+			NonceDataAvailabilityMode: req.NonceDataAvailabilityMode,
+			FeeDataAvailabilityMode:   req.FeeDataAvailabilityMode,
+		}
+		var hash *felt.Felt
+		var err error
+		switch {
+		case req.Type == rpc.TransactionType_Invoke:
+			hash, err = hashInvokeV3(req.Version, req.SenderAddress, req.ChainID, req.Calldata, details)
+		case req.Type == rpc.TransactionType_Declare && req.Version == rpc.TransactionV3:
+			hash, err = hashDeclareV3(req.SenderAddress, req.ChainID, req.ClassHash, req.CompiledClassHash, details)
+		case req.Type == rpc.TransactionType_DeployAccount && req.Version == rpc.TransactionV3:
+			hash, err = hashDeployAccountV3(req.SenderAddress, req.ChainID, req.ClassHash, req.ContractAddressSalt, req.Calldata, details)
+		default:
+			return nil, fmt.Errorf("unsupported %s transaction version %s", req.Type, req.Version)
+		}
+		if err != nil {
+			return nil, err
+		}
+		return hash.BigInt(big.NewInt(0)), nil
+	case rpc.TransactionV1, rpc.TransactionV1WithQueryBit, rpc.TransactionV2:
+		if req.MaxFee == nil {
+			return nil, errors.New("sign request without max fee")
+		}
+		version, err := req.Version.BigInt()
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case req.Type == rpc.TransactionType_Invoke && req.Version != rpc.TransactionV2:
+			calldata := make([]*big.Int, len(req.Calldata))
+			for i, value := range req.Calldata {
+				calldata[i] = value.BigInt(big.NewInt(0))
+			}
+			return hashInvoke(version, req.SenderAddress, req.ChainID, calldata, req.MaxFee, req.Nonce)
+		case req.Type == rpc.TransactionType_Declare && !req.Version.IsQuery():
+			var compiledClassHash *big.Int
+			if req.CompiledClassHash != nil {
+				compiledClassHash = req.CompiledClassHash.BigInt(big.NewInt(0))
+			}
+			return hashDeclare(version.Uint64(), req.SenderAddress, req.ChainID, req.ClassHash.BigInt(big.NewInt(0)), compiledClassHash, req.MaxFee, req.Nonce)
+		case req.Type == rpc.TransactionType_DeployAccount && req.Version == rpc.TransactionV1:
+			return hashDeployAccount(req.SenderAddress, req.ChainID, req.ClassHash, req.ContractAddressSalt, req.Calldata, req.MaxFee, req.Nonce)
+		}
+	}
+	return nil, fmt.Errorf("unsupported %s transaction version %s", req.Type, req.Version)
+}
+
+// RequestSigner is implemented by the keystores and signers using the
+// transaction of the hash. The account signs with SignRequest when the
+// keystore implements it.
+type RequestSigner interface {
+	SignRequest(ctx context.Context, req SignRequest) ([]*felt.Felt, error)
+}
+
+// keystoreSigner adapts a Keystore to the Signer interface.
+type keystoreSigner struct {
+	ks Keystore
+}
+
+// KeystoreSigner returns a Signer producing the [r, s] signature of ks. It
+// returns ks itself when it already implements Signer.
+func KeystoreSigner(ks Keystore) Signer {
+	if signer, ok := ks.(Signer); ok {
+		return signer
+	}
+	return keystoreSigner{ks: ks}
+}
+
+func (s keystoreSigner) SignTransaction(ctx context.Context, id string, msgHash *big.Int) ([]*felt.Felt, error) {
+	x, y, err := s.ks.Sign(ctx, id, msgHash)
+	if err != nil {
+		return nil, err
+	}
+	xFelt, err := utils.BigIntToFelt(x)
+	if err != nil {
+		return nil, err
+	}
+	yFelt, err := utils.BigIntToFelt(y)
+	if err != nil {
+		return nil, err
+	}
+	return []*felt.Felt{xFelt, yFelt}, nil
+}
+
+// requestSignature signs req with signer, giving it the transaction when it
+// implements RequestSigner.
+func requestSignature(ctx context.Context, signer Signer, req SignRequest) ([]*felt.Felt, error) {
+	if requestSigner, ok := signer.(RequestSigner); ok {
+		return requestSigner.SignRequest(ctx, req)
+	}
+	return signer.SignTransaction(ctx, req.ID, req.Hash)
+}
+
+// SignRequest forwards req to the keystore when it implements RequestSigner
+// and signs the hash of req otherwise.
+func (s keystoreSigner) SignRequest(ctx context.Context, req SignRequest) ([]*felt.Felt, error) {
+	if signer, ok := s.ks.(RequestSigner); ok {
+		return signer.SignRequest(ctx, req)
+	}
+	return s.SignTransaction(ctx, req.ID, req.Hash)
+}
+
 // MemKeystore implements the Keystore interface and is intended for example and test code.
 type MemKeystore struct {
 	mu   sync.Mutex
@@ -0,0 +1,488 @@
+package starknetgo
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/scrypt"
+	"golang.org/x/crypto/sha3"
+)
+
+const (
+	// StandardScryptN and StandardScryptP are the scrypt parameters of the
+	// Web3 keystores, about 1s and 256MB to unlock a key.
+	StandardScryptN = 1 << 18
+	StandardScryptP = 1
+	// LightScryptN and LightScryptP are cheaper scrypt parameters for
+	// constrained environments and tests.
+	LightScryptN = 1 << 12
+	LightScryptP = 6
+
+	scryptR     = 8
+	scryptDKLen = 32
+
+	// maxScryptMemory bounds the 128*N*r bytes and maxScryptWork the N*r*p
+	// rounds of the scrypt parameters of the key files read, so a key file
+	// can not exhaust the host. The standard parameters use both in full.
+	maxScryptMemory = 1 << 28
+	maxScryptWork   = 1 << 22
+)
+
+var (
+	ErrKeyLocked       = errors.New("key is locked")
+	ErrWrongPassphrase = errors.New("could not decrypt key with given passphrase")
+)
+
+// encryptedKeyJSON is the Web3 secret storage (version 3) layout of a key.
+type encryptedKeyJSON struct {
+	Address string     `json:"address"`
+	Crypto  cryptoJSON `json:"crypto"`
+	ID      string     `json:"id"`
+	Version int        `json:"version"`
+}
+
+type cryptoJSON struct {
+	Cipher       string       `json:"cipher"`
+	CipherText   string       `json:"ciphertext"`
+	CipherParams cipherParams `json:"cipherparams"`
+	KDF          string       `json:"kdf"`
+	KDFParams    kdfParams    `json:"kdfparams"`
+	MAC          string       `json:"mac"`
+}
+
+type cipherParams struct {
+	IV string `json:"iv"`
+}
+
+type kdfParams struct {
+	DKLen int    `json:"dklen"`
+	N     int    `json:"n"`
+	P     int    `json:"p"`
+	R     int    `json:"r"`
+	Salt  string `json:"salt"`
+}
+
+// unlockedKey is a decrypted key, dropped when its timer fires.
+type unlockedKey struct {
+	key   *big.Int
+	timer *time.Timer
+}
+
+// FileKeystore implements the Keystore interface with Stark private keys
+// stored in dir, encrypted with scrypt and AES-128-CTR in the Web3 secret
+// storage format. Keys are indexed by address, the id passed to Sign, and
+// must be unlocked before signing.
+type FileKeystore struct {
+	dir      string
+	scryptN  int
+	scryptP  int
+	mu       sync.Mutex
+	unlocked map[string]*unlockedKey
+}
+
+// NewFileKeystore returns a keystore reading and writing keys in dir. New keys
+// are encrypted with the scrypt parameters scryptN and scryptP.
+func NewFileKeystore(dir string, scryptN, scryptP int) *FileKeystore {
+	return &FileKeystore{
+		dir:      dir,
+		scryptN:  scryptN,
+		scryptP:  scryptP,
+		unlocked: map[string]*unlockedKey{},
+	}
+}
+
+// keystoreAddress normalizes a hexadecimal address, with or without the 0x
+// prefix, so the same key is found whatever its case or padding.
+func keystoreAddress(address string) (string, error) {
+	value, ok := new(big.Int).SetString(strings.TrimPrefix(strings.ToLower(address), "0x"), 16)
+	if !ok {
+		return "", fmt.Errorf("invalid address %q", address)
+	}
+	return fmt.Sprintf("0x%x", value), nil
+}
+
+// NewKey generates a private key, stores it encrypted with passphrase and
+// returns its address, the x coordinate of the public key.
+func (ks *FileKeystore) NewKey(passphrase string) (string, error) {
+	key, err := Curve.GetRandomPrivateKey()
+	if err != nil {
+		return "", err
+	}
+	x, _, err := Curve.PrivateToPoint(key)
+	if err != nil {
+		return "", err
+	}
+	address := fmt.Sprintf("0x%x", x)
+	return address, ks.Import(address, key, passphrase)
+}
+
+// Import stores key under address, encrypted with passphrase.
+func (ks *FileKeystore) Import(address string, key *big.Int, passphrase string) error {
+	address, err := keystoreAddress(address)
+	if err != nil {
+		return err
+	}
+	if key.Sign() <= 0 || key.Cmp(Curve.N) >= 0 {
+		return errors.New("private key out of range")
+	}
+	if _, err := ks.find(address); err == nil {
+		return fmt.Errorf("key %s already exists", address)
+	}
+	content, err := encryptKey(address, key, passphrase, ks.scryptN, ks.scryptP)
+	if err != nil {
+		return err
+	}
+	return ks.write(address, content)
+}
+
+// ImportJSON stores an encrypted key file, checking passphrase decrypts it.
+// It returns the address of the key.
+func (ks *FileKeystore) ImportJSON(content []byte, passphrase string) (string, error) {
+	var encrypted encryptedKeyJSON
+	if err := json.Unmarshal(content, &encrypted); err != nil {
+		return "", err
+	}
+	address, err := keystoreAddress(encrypted.Address)
+	if err != nil {
+		return "", err
+	}
+	if _, err := decryptKey(encrypted, passphrase); err != nil {
+		return "", err
+	}
+	if _, err := ks.find(address); err == nil {
+		return "", fmt.Errorf("key %s already exists", address)
+	}
+	return address, ks.write(address, content)
+}
+
+// Export decrypts and returns the private key stored under address.
+func (ks *FileKeystore) Export(address, passphrase string) (*big.Int, error) {
+	encrypted, err := ks.read(address)
+	if err != nil {
+		return nil, err
+	}
+	return decryptKey(*encrypted, passphrase)
+}
+
+// ExportJSON returns the encrypted key file stored under address.
+func (ks *FileKeystore) ExportJSON(address string) ([]byte, error) {
+	address, err := keystoreAddress(address)
+	if err != nil {
+		return nil, err
+	}
+	filename, err := ks.find(address)
+	if err != nil {
+		return nil, err
+	}
+	return os.ReadFile(filename)
+}
+
+// Accounts returns the addresses of the stored keys, in order.
+func (ks *FileKeystore) Accounts() ([]string, error) {
+	keys, err := ks.keys()
+	if err != nil {
+		return nil, err
+	}
+	addresses := make([]string, 0, len(keys))
+	for address := range keys {
+		addresses = append(addresses, address)
+	}
+	sort.Strings(addresses)
+	return addresses, nil
+}
+
+// Unlock decrypts the key stored under address so Sign can use it. The key
+// is locked again after timeout, or only by Lock when timeout is 0.
+func (ks *FileKeystore) Unlock(address, passphrase string, timeout time.Duration) error {
+	address, err := keystoreAddress(address)
+	if err != nil {
+		return err
+	}
+	key, err := ks.Export(address, passphrase)
+	if err != nil {
+		return err
+	}
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	if previous, ok := ks.unlocked[address]; ok && previous.timer != nil {
+		previous.timer.Stop()
+	}
+	unlocked := &unlockedKey{key: key}
+	if timeout > 0 {
+		unlocked.timer = time.AfterFunc(timeout, func() {
+			ks.mu.Lock()
+			defer ks.mu.Unlock()
+			if ks.unlocked[address] == unlocked {
+				delete(ks.unlocked, address)
+			}
+		})
+	}
+	ks.unlocked[address] = unlocked
+	return nil
+}
+
+// Lock drops the decrypted key of address.
+func (ks *FileKeystore) Lock(address string) error {
+	address, err := keystoreAddress(address)
+	if err != nil {
+		return err
+	}
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	if unlocked, ok := ks.unlocked[address]; ok {
+		if unlocked.timer != nil {
+			unlocked.timer.Stop()
+		}
+		delete(ks.unlocked, address)
+	}
+	return nil
+}
+
+// Sign signs msgHash with the key of id, which must be unlocked.
+func (ks *FileKeystore) Sign(ctx context.Context, id string, msgHash *big.Int) (*big.Int, *big.Int, error) {
+	address, err := keystoreAddress(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	ks.mu.Lock()
+	unlocked, ok := ks.unlocked[address]
+	ks.mu.Unlock()
+	if !ok {
+		if _, err := ks.find(address); err != nil {
+			return nil, nil, err
+		}
+		return nil, nil, fmt.Errorf("error signing with %s: %w", address, ErrKeyLocked)
+	}
+	return sign(ctx, msgHash, unlocked.key)
+}
+
+// keys returns the key files of the keystore by address. Files which are not
+// Web3 keys are ignored.
+func (ks *FileKeystore) keys() (map[string]string, error) {
+	entries, err := os.ReadDir(ks.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	keys := map[string]string{}
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		filename := filepath.Join(ks.dir, entry.Name())
+		content, err := os.ReadFile(filename)
+		if err != nil {
+			return nil, err
+		}
+		var encrypted encryptedKeyJSON
+		if err := json.Unmarshal(content, &encrypted); err != nil || encrypted.Version != 3 {
+			continue
+		}
+		address, err := keystoreAddress(encrypted.Address)
+		if err != nil {
+			continue
+		}
+		keys[address] = filename
+	}
+	return keys, nil
+}
+
+func (ks *FileKeystore) find(address string) (string, error) {
+	keys, err := ks.keys()
+	if err != nil {
+		return "", err
+	}
+	filename, ok := keys[address]
+	if !ok {
+		return "", fmt.Errorf("error getting key for sender %s: %w", address, ErrSenderNoExist)
+	}
+	return filename, nil
+}
+
+func (ks *FileKeystore) read(address string) (*encryptedKeyJSON, error) {
+	address, err := keystoreAddress(address)
+	if err != nil {
+		return nil, err
+	}
+	filename, err := ks.find(address)
+	if err != nil {
+		return nil, err
+	}
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	var encrypted encryptedKeyJSON
+	if err := json.Unmarshal(content, &encrypted); err != nil {
+		return nil, err
+	}
+	return &encrypted, nil
+}
+
+// write stores a key file with the UTC--<time>--<address> name of the Web3
+// keystores. The file is written to a temporary file first so a crash never
+// leaves a truncated key behind.
+func (ks *FileKeystore) write(address string, content []byte) error {
+	if err := os.MkdirAll(ks.dir, 0700); err != nil {
+		return err
+	}
+	name := fmt.Sprintf("UTC--%s--%s", time.Now().UTC().Format("2006-01-02T15-04-05.000000000Z"), strings.TrimPrefix(address, "0x"))
+	tmp, err := os.CreateTemp(ks.dir, "."+name+".tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), filepath.Join(ks.dir, name))
+}
+
+// encryptKey returns the Web3 key file of key.
+func encryptKey(address string, key *big.Int, passphrase string, scryptN, scryptP int) ([]byte, error) {
+	if err := checkScryptParams(kdfParams{DKLen: scryptDKLen, N: scryptN, P: scryptP, R: scryptR}); err != nil {
+		return nil, err
+	}
+	salt := make([]byte, 32)
+	iv := make([]byte, aes.BlockSize)
+	id := make([]byte, 16)
+	for _, buf := range [][]byte{salt, iv, id} {
+		if _, err := rand.Read(buf); err != nil {
+			return nil, err
+		}
+	}
+	derivedKey, err := scrypt.Key([]byte(passphrase), salt, scryptN, scryptR, scryptP, scryptDKLen)
+	if err != nil {
+		return nil, err
+	}
+	plainText := key.FillBytes(make([]byte, 32))
+	cipherText, err := aesCTRXOR(derivedKey[:16], plainText, iv)
+	if err != nil {
+		return nil, err
+	}
+	// random UUID, version 4
+	id[6] = id[6]&0x0f | 0x40
+	id[8] = id[8]&0x3f | 0x80
+	return json.Marshal(encryptedKeyJSON{
+		Address: strings.TrimPrefix(address, "0x"),
+		Crypto: cryptoJSON{
+			Cipher:       "aes-128-ctr",
+			CipherText:   hex.EncodeToString(cipherText),
+			CipherParams: cipherParams{IV: hex.EncodeToString(iv)},
+			KDF:          "scrypt",
+			KDFParams: kdfParams{
+				DKLen: scryptDKLen,
+				N:     scryptN,
+				P:     scryptP,
+				R:     scryptR,
+				Salt:  hex.EncodeToString(salt),
+			},
+			MAC: hex.EncodeToString(keystoreMAC(derivedKey, cipherText)),
+		},
+		ID:      fmt.Sprintf("%x-%x-%x-%x-%x", id[0:4], id[4:6], id[6:8], id[8:10], id[10:]),
+		Version: 3,
+	})
+}
+
+// decryptKey returns the private key of a Web3 key file.
+func decryptKey(encrypted encryptedKeyJSON, passphrase string) (*big.Int, error) {
+	if encrypted.Version != 3 {
+		return nil, fmt.Errorf("version %d unsupported", encrypted.Version)
+	}
+	if encrypted.Crypto.Cipher != "aes-128-ctr" {
+		return nil, fmt.Errorf("cipher %s unsupported", encrypted.Crypto.Cipher)
+	}
+	if encrypted.Crypto.KDF != "scrypt" {
+		return nil, fmt.Errorf("kdf %s unsupported", encrypted.Crypto.KDF)
+	}
+	params := encrypted.Crypto.KDFParams
+	if err := checkScryptParams(params); err != nil {
+		return nil, err
+	}
+	salt, err := hex.DecodeString(params.Salt)
+	if err != nil {
+		return nil, err
+	}
+	iv, err := hex.DecodeString(encrypted.Crypto.CipherParams.IV)
+	if err != nil {
+		return nil, err
+	}
+	if len(iv) != aes.BlockSize {
+		return nil, fmt.Errorf("invalid iv length %d", len(iv))
+	}
+	cipherText, err := hex.DecodeString(encrypted.Crypto.CipherText)
+	if err != nil {
+		return nil, err
+	}
+	mac, err := hex.DecodeString(encrypted.Crypto.MAC)
+	if err != nil {
+		return nil, err
+	}
+	derivedKey, err := scrypt.Key([]byte(passphrase), salt, params.N, params.R, params.P, params.DKLen)
+	if err != nil {
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare(keystoreMAC(derivedKey, cipherText), mac) != 1 {
+		return nil, ErrWrongPassphrase
+	}
+	plainText, err := aesCTRXOR(derivedKey[:16], cipherText, iv)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(plainText), nil
+}
+
+// checkScryptParams returns an error unless the scrypt parameters of a key
+// file derive a key long enough for the cipher and the MAC within the bounds
+// of maxScryptMemory and maxScryptWork.
+func checkScryptParams(params kdfParams) error {
+	if params.DKLen < 32 || params.DKLen > 64 {
+		return fmt.Errorf("invalid scrypt dklen %d", params.DKLen)
+	}
+	if params.N <= 1 || params.N&(params.N-1) != 0 || params.R <= 0 || params.P <= 0 {
+		return fmt.Errorf("invalid scrypt parameters n=%d r=%d p=%d", params.N, params.R, params.P)
+	}
+	if params.N > maxScryptMemory/128 || params.R > maxScryptMemory/128/params.N || params.P > maxScryptWork/(params.N*params.R) {
+		return fmt.Errorf("scrypt parameters n=%d r=%d p=%d above the limits", params.N, params.R, params.P)
+	}
+	return nil
+}
+
+// keystoreMAC is the Keccak-256 MAC of the Web3 key files.
+func keystoreMAC(derivedKey, cipherText []byte) []byte {
+	hash := sha3.NewLegacyKeccak256()
+	hash.Write(derivedKey[16:32])
+	hash.Write(cipherText)
+	return hash.Sum(nil)
+}
+
+func aesCTRXOR(key, input, iv []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	output := make([]byte, len(input))
+	cipher.NewCTR(block, iv).XORKeyStream(output, input)
+	return output, nil
+}
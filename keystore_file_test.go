@@ -0,0 +1,147 @@
+package starknetgo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/sjxqqq/starknet-go/types"
+)
+
+// TestFileKeystore_Web3Vector decrypts the scrypt test vector of the Web3
+// secret storage definition.
+func TestFileKeystore_Web3Vector(t *testing.T) {
+	content := `{
+		"crypto": {
+			"cipher": "aes-128-ctr",
+			"cipherparams": {"iv": "83dbcc02d8ccb40e466191a123791e0e"},
+			"ciphertext": "d172bf743a674da9cdad04534d56926ef8358534d458fffccd4e6ad2fbde479c",
+			"kdf": "scrypt",
+			"kdfparams": {"dklen": 32, "n": 262144, "r": 1, "p": 8, "salt": "ab0c7876052600dd703518d6fc3fe8984592145b591fc8fb5c6d43190334ba19"},
+			"mac": "2103ac29920d71da29f15d75b4a16dbe95cfd7ff8faea1056c33131d846e3097"
+		},
+		"id": "3198bc9c-6672-5ab3-d995-4942343ae5b6",
+		"version": 3
+	}`
+	var encrypted encryptedKeyJSON
+	if err := json.Unmarshal([]byte(content), &encrypted); err != nil {
+		t.Fatal(err)
+	}
+	key, err := decryptKey(encrypted, "testpassword")
+	if err != nil {
+		t.Fatal("should decrypt the vector, instead:", err)
+	}
+	if key.Text(16) != "7a28b5ba57c53603b0b07b56bba752f7784bf506fa95edc395f5cf6c7514fe9d" {
+		t.Fatalf("unexpected key %x", key)
+	}
+	if _, err := decryptKey(encrypted, "wrong"); !errors.Is(err, ErrWrongPassphrase) {
+		t.Fatalf("error should be %v, instead: %v", ErrWrongPassphrase, err)
+	}
+
+	// the MAC does not cover the iv and the scrypt parameters
+	type testSetType struct {
+		Edit func(*encryptedKeyJSON)
+	}
+	testSet := map[string][]testSetType{
+		"mock": {
+			{Edit: func(e *encryptedKeyJSON) { e.Crypto.CipherParams.IV = "83dbcc02d8ccb40e" }},
+			{Edit: func(e *encryptedKeyJSON) { e.Crypto.CipherParams.IV = "" }},
+			{Edit: func(e *encryptedKeyJSON) { e.Crypto.KDFParams.DKLen = 16 }},
+			{Edit: func(e *encryptedKeyJSON) { e.Crypto.KDFParams.N = 1 << 30 }},
+			{Edit: func(e *encryptedKeyJSON) { e.Crypto.KDFParams.N = 1000 }},
+			{Edit: func(e *encryptedKeyJSON) { e.Crypto.KDFParams.P = 1 << 20 }},
+			{Edit: func(e *encryptedKeyJSON) { e.Crypto.KDFParams.R = 0 }},
+		},
+	}[testEnv]
+	for i, test := range testSet {
+		malformed := encrypted
+		test.Edit(&malformed)
+		if _, err := decryptKey(malformed, "testpassword"); err == nil {
+			t.Fatalf("%d: malformed key file should fail", i)
+		}
+	}
+}
+
+func TestFileKeystore(t *testing.T) {
+	ks := NewFileKeystore(t.TempDir(), LightScryptN, LightScryptP)
+	address, err := ks.NewKey("passphrase")
+	if err != nil {
+		t.Fatal("should create a key, instead:", err)
+	}
+	if err := ks.Import(mockAccountPrivateKey, types.HexToBN(mockAccountPrivateKey), "other"); err != nil {
+		t.Fatal("should import the key, instead:", err)
+	}
+	if err := ks.Import(mockAccountPrivateKey, types.HexToBN(mockAccountPrivateKey), "other"); err == nil {
+		t.Fatal("importing the same address twice should fail")
+	}
+	accounts, err := ks.Accounts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(accounts) != 2 {
+		t.Fatalf("keystore should hold 2 keys, instead: %v", accounts)
+	}
+
+	hash := big.NewInt(1234)
+	if _, _, err := ks.Sign(context.Background(), mockAccountPrivateKey, hash); !errors.Is(err, ErrKeyLocked) {
+		t.Fatalf("error should be %v, instead: %v", ErrKeyLocked, err)
+	}
+	if _, _, err := ks.Sign(context.Background(), "0x1", hash); !errors.Is(err, ErrSenderNoExist) {
+		t.Fatalf("error should be %v, instead: %v", ErrSenderNoExist, err)
+	}
+	if err := ks.Unlock(mockAccountPrivateKey, "passphrase", 0); !errors.Is(err, ErrWrongPassphrase) {
+		t.Fatalf("error should be %v, instead: %v", ErrWrongPassphrase, err)
+	}
+	if err := ks.Unlock(mockAccountPrivateKey, "other", 0); err != nil {
+		t.Fatal("should unlock the key, instead:", err)
+	}
+	signature, err := KeystoreSigner(ks).SignTransaction(context.Background(), mockAccountPrivateKey, hash)
+	if err != nil {
+		t.Fatal("should sign, instead:", err)
+	}
+	verifyMockSignature(t, hash, signature)
+	if err := ks.Lock(mockAccountPrivateKey); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := ks.Sign(context.Background(), mockAccountPrivateKey, hash); !errors.Is(err, ErrKeyLocked) {
+		t.Fatalf("locked key should not sign, instead: %v", err)
+	}
+
+	if err := ks.Unlock(address, "passphrase", 50*time.Millisecond); err != nil {
+		t.Fatal("should unlock the key, instead:", err)
+	}
+	if _, _, err := ks.Sign(context.Background(), address, hash); err != nil {
+		t.Fatal("unlocked key should sign, instead:", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	if _, _, err := ks.Sign(context.Background(), address, hash); !errors.Is(err, ErrKeyLocked) {
+		t.Fatalf("key should be locked after the timeout, instead: %v", err)
+	}
+
+	content, err := ks.ExportJSON(address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other := NewFileKeystore(t.TempDir(), LightScryptN, LightScryptP)
+	if _, err := other.ImportJSON(content, "wrong"); !errors.Is(err, ErrWrongPassphrase) {
+		t.Fatalf("error should be %v, instead: %v", ErrWrongPassphrase, err)
+	}
+	imported, err := other.ImportJSON(content, "passphrase")
+	if err != nil || imported != address {
+		t.Fatalf("should import %s, instead: %s, %v", address, imported, err)
+	}
+	key, err := other.Export(address, "passphrase")
+	if err != nil {
+		t.Fatal(err)
+	}
+	x, _, err := Curve.PrivateToPoint(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if "0x"+x.Text(16) != address {
+		t.Fatalf("exported key should match %s", address)
+	}
+}
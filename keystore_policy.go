@@ -0,0 +1,403 @@
+package starknetgo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/sjxqqq/starknet-go/rpc"
+	"github.com/sjxqqq/starknet-go/types"
+)
+
+var ErrPolicyViolation = errors.New("policy violation")
+
+// spendingSelectors are the ERC-20 entry points moving or allowing the
+// [recipient, amount.low, amount.high] amount of their calldata.
+var spendingSelectors = []*felt.Felt{
+	types.GetSelectorFromNameFelt("transfer"),
+	types.GetSelectorFromNameFelt("approve"),
+	types.GetSelectorFromNameFelt("increaseAllowance"),
+	types.GetSelectorFromNameFelt("increase_allowance"),
+}
+
+// AllowedContract is a contract the policy lets the account call. An empty
+// Selectors allows all the entry points of the contract.
+type AllowedContract struct {
+	Address   *felt.Felt
+	Selectors []*felt.Felt
+}
+
+// SpendLimit caps the amount of Token transferred or approved by the account
+// over a sliding Period. A zero Period never resets the limit.
+type SpendLimit struct {
+	Token  *felt.Felt
+	Amount *big.Int
+	Period time.Duration
+}
+
+// Policy is the set of rules a PolicyKeystore checks before signing.
+type Policy struct {
+	// AllowedContracts are the contracts invoke transactions can call. nil
+	// allows all contracts.
+	AllowedContracts []AllowedContract
+	SpendLimits      []SpendLimit
+	// MaxFeeWei caps the max fee of the transactions before v3, paid in wei.
+	// nil does not cap it.
+	MaxFeeWei *big.Int
+	// MaxFeeFri caps the max fee of the v3 transactions, paid in fri, i.e.
+	// the most they can pay with their resource bounds and tip. nil does not
+	// cap it.
+	MaxFeeFri *big.Int
+	// ChainIDs are the chains the transactions can be signed for. nil allows
+	// all chains.
+	ChainIDs []string
+	// Declare and DeployAccount allow the DECLARE and DEPLOY_ACCOUNT
+	// transactions, which have no calls for AllowedContracts to check.
+	Declare       bool
+	DeployAccount bool
+	// TypedData allows signing typed data messages. The other rules do not
+	// check their content, so enabling it allows the account to sign any
+	// message, including SNIP-9 outside executions of any call.
+	TypedData bool
+}
+
+// spend is an amount signed by the policy keystore.
+type spend struct {
+	at     time.Time
+	amount *big.Int
+}
+
+// policyAuditEntry is a line of the audit log of the policy keystore.
+type policyAuditEntry struct {
+	Time     time.Time              `json:"time"`
+	ID       string                 `json:"id"`
+	Type     rpc.TransactionType    `json:"type,omitempty"`
+	Version  rpc.TransactionVersion `json:"version,omitempty"`
+	ChainID  string                 `json:"chain_id,omitempty"`
+	Nonce    string                 `json:"nonce,omitempty"`
+	MaxFee   string                 `json:"max_fee,omitempty"`
+	Hash     string                 `json:"hash,omitempty"`
+	Calls    []types.FunctionCall   `json:"calls,omitempty"`
+	Decision string                 `json:"decision"`
+	Reason   string                 `json:"reason,omitempty"`
+}
+
+// The decisions of the audit log.
+const (
+	policyAllowed = "allowed"
+	policyDenied  = "denied"
+	policyFailed  = "failed"
+)
+
+// PolicyKeystore implements the Keystore and RequestSigner interfaces by
+// checking the transactions against a Policy before signing them with an
+// inner keystore. It refuses to sign bare hashes, so the account must use it
+// through KeystoreSigner or as its keystore. The calls of invoke transactions
+// must be encoded with EncodeCallsCairo0 or EncodeCallsCairo1.
+//
+// Each decision is written as a JSON line to the audit log. The signatures
+// are serialized so the spend limits hold with concurrent requests.
+type PolicyKeystore struct {
+	mu     sync.Mutex
+	inner  Signer
+	policy Policy
+	audit  io.Writer
+	spends [][]spend
+	now    func() time.Time
+}
+
+// NewPolicyKeystore returns a keystore signing the transactions allowed by
+// policy with inner. audit can be nil to disable the audit log.
+func NewPolicyKeystore(inner Keystore, policy Policy, audit io.Writer) *PolicyKeystore {
+	return &PolicyKeystore{
+		inner:  KeystoreSigner(inner),
+		policy: policy,
+		audit:  audit,
+		spends: make([][]spend, len(policy.SpendLimits)),
+		now:    time.Now,
+	}
+}
+
+// Sign always fails: the policy can not be checked without the transaction.
+func (ks *PolicyKeystore) Sign(ctx context.Context, id string, msgHash *big.Int) (*big.Int, *big.Int, error) {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	err := fmt.Errorf("%w: hash signed without its transaction", ErrPolicyViolation)
+	if auditErr := ks.log(SignRequest{ID: id, Hash: msgHash}, policyDenied, err); auditErr != nil {
+		return nil, nil, auditErr
+	}
+	return nil, nil, err
+}
+
+// SignRequest signs the hash of req with the inner keystore when the
+// transaction complies with the policy. It returns an error wrapping
+// ErrPolicyViolation otherwise, including when req.Hash is not the hash of
+// the transaction of req. The query transactions of the fee estimation and
+// simulation are only checked against the chains and contracts allowed, and
+// do not count towards the spend limits.
+func (ks *PolicyKeystore) SignRequest(ctx context.Context, req SignRequest) ([]*felt.Felt, error) {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	now := ks.now()
+	amounts, err := ks.check(req, now)
+	if err != nil {
+		if auditErr := ks.log(req, policyDenied, err); auditErr != nil {
+			return nil, auditErr
+		}
+		return nil, err
+	}
+	signature, err := requestSignature(ctx, ks.inner, req)
+	if err != nil {
+		if auditErr := ks.log(req, policyFailed, err); auditErr != nil {
+			return nil, auditErr
+		}
+		return nil, err
+	}
+	// the signature is only released once the decision is logged
+	if err := ks.log(req, policyAllowed, nil); err != nil {
+		return nil, err
+	}
+	for i, amount := range amounts {
+		if amount.Sign() > 0 {
+			ks.spends[i] = append(ks.spends[i], spend{at: now, amount: amount})
+		}
+	}
+	return signature, nil
+}
+
+// check returns the amounts req spends for each spend limit of the policy, or
+// the rule it violates.
+func (ks *PolicyKeystore) check(req SignRequest, now time.Time) ([]*big.Int, error) {
+	if req.TypedData == nil {
+		if err := checkTransactionHash(req); err != nil {
+			return nil, err
+		}
+	} else if err := checkMessageHash(req); err != nil {
+		return nil, err
+	}
+	if len(ks.policy.ChainIDs) > 0 && !containsString(ks.policy.ChainIDs, req.ChainID) {
+		return nil, fmt.Errorf("%w: chain %q not allowed", ErrPolicyViolation, req.ChainID)
+	}
+	if req.TypedData != nil {
+		if !ks.policy.TypedData {
+			return nil, fmt.Errorf("%w: typed data not allowed", ErrPolicyViolation)
+		}
+		return nil, nil
+	}
+	switch req.Type {
+	case rpc.TransactionType_Declare:
+		if !ks.policy.Declare {
+			return nil, fmt.Errorf("%w: declare transactions not allowed", ErrPolicyViolation)
+		}
+	case rpc.TransactionType_DeployAccount:
+		if !ks.policy.DeployAccount {
+			return nil, fmt.Errorf("%w: deploy account transactions not allowed", ErrPolicyViolation)
+		}
+	}
+	if ks.policy.AllowedContracts != nil {
+		for _, call := range req.Calls {
+			if !ks.allowed(call) {
+				return nil, fmt.Errorf("%w: call to %s of %s not allowed", ErrPolicyViolation, feltString(call.EntryPointSelector), feltString(call.ContractAddress))
+			}
+		}
+	}
+	if req.Version.IsQuery() {
+		return nil, nil
+	}
+	maxFee, unit := ks.policy.MaxFeeWei, "wei"
+	if req.Version == rpc.TransactionV3 {
+		maxFee, unit = ks.policy.MaxFeeFri, "fri"
+	}
+	if maxFee != nil {
+		if req.MaxFee == nil {
+			return nil, fmt.Errorf("%w: unknown max fee", ErrPolicyViolation)
+		}
+		if req.MaxFee.Cmp(maxFee) > 0 {
+			return nil, fmt.Errorf("%w: max fee %s %s above %s", ErrPolicyViolation, req.MaxFee, unit, maxFee)
+		}
+	}
+	amounts := make([]*big.Int, len(ks.policy.SpendLimits))
+	for i, limit := range ks.policy.SpendLimits {
+		amount, err := spentAmount(limit.Token, req.Calls)
+		if err != nil {
+			return nil, err
+		}
+		amounts[i] = amount
+		if amount.Sign() == 0 {
+			continue
+		}
+		total := new(big.Int).Set(amount)
+		kept := ks.spends[i][:0]
+		for _, previous := range ks.spends[i] {
+			if limit.Period > 0 && !previous.at.After(now.Add(-limit.Period)) {
+				continue
+			}
+			kept = append(kept, previous)
+			total.Add(total, previous.amount)
+		}
+		ks.spends[i] = kept
+		if total.Cmp(limit.Amount) > 0 {
+			return nil, fmt.Errorf("%w: spend of %s above the limit of %s for token %s", ErrPolicyViolation, total, limit.Amount, feltString(limit.Token))
+		}
+	}
+	return amounts, nil
+}
+
+// checkTransactionHash returns an error unless req.Hash is the hash of the
+// transaction of req and, for an invoke transaction, its calldata is the
+// encoding of its calls.
+func checkTransactionHash(req SignRequest) error {
+	hash, err := req.TransactionHash()
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrPolicyViolation, err)
+	}
+	if req.Hash == nil || hash.Cmp(req.Hash) != 0 {
+		return fmt.Errorf("%w: hash does not match the transaction", ErrPolicyViolation)
+	}
+	if req.Type != rpc.TransactionType_Invoke {
+		return nil
+	}
+	for _, encoder := range []CallEncoder{EncodeCallsCairo0, EncodeCallsCairo1} {
+		if calldataEqual(encoder(req.Calls), req.Calldata) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: calldata does not match the calls", ErrPolicyViolation)
+}
+
+// checkMessageHash returns an error unless req.Hash is the hash of the typed
+// data of req signed by its sender.
+func checkMessageHash(req SignRequest) error {
+	if req.SenderAddress == nil {
+		return fmt.Errorf("%w: sign request without sender address", ErrPolicyViolation)
+	}
+	hash, err := req.TypedData.GetMessageHash(req.SenderAddress.BigInt(big.NewInt(0)), req.TypedData.Message, Curve)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrPolicyViolation, err)
+	}
+	if req.Hash == nil || hash.Cmp(req.Hash) != 0 {
+		return fmt.Errorf("%w: hash does not match the typed data", ErrPolicyViolation)
+	}
+	return nil
+}
+
+func calldataEqual(encoded []*big.Int, calldata []*felt.Felt) bool {
+	if len(encoded) != len(calldata) {
+		return false
+	}
+	for i, value := range encoded {
+		if calldata[i] == nil || value.Cmp(calldata[i].BigInt(big.NewInt(0))) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// allowed returns true when the policy allows call.
+func (ks *PolicyKeystore) allowed(call types.FunctionCall) bool {
+	for _, contract := range ks.policy.AllowedContracts {
+		if !feltEqual(contract.Address, call.ContractAddress) {
+			continue
+		}
+		if len(contract.Selectors) == 0 {
+			return true
+		}
+		for _, selector := range contract.Selectors {
+			if feltEqual(selector, call.EntryPointSelector) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// spentAmount returns the amount of token transferred or approved by calls.
+func spentAmount(token *felt.Felt, calls []types.FunctionCall) (*big.Int, error) {
+	total := big.NewInt(0)
+	for _, call := range calls {
+		if !feltEqual(call.ContractAddress, token) || !isSpendingSelector(call.EntryPointSelector) {
+			continue
+		}
+		if len(call.Calldata) < 3 {
+			return nil, fmt.Errorf("%w: can not decode the amount of the call to %s", ErrPolicyViolation, feltString(token))
+		}
+		low := call.Calldata[1].BigInt(big.NewInt(0))
+		high := call.Calldata[2].BigInt(big.NewInt(0))
+		total.Add(total, low.Add(low, high.Lsh(high, 128)))
+	}
+	return total, nil
+}
+
+func isSpendingSelector(selector *felt.Felt) bool {
+	for _, spending := range spendingSelectors {
+		if feltEqual(spending, selector) {
+			return true
+		}
+	}
+	return false
+}
+
+// log writes the decision on req to the audit log.
+func (ks *PolicyKeystore) log(req SignRequest, decision string, reason error) error {
+	if ks.audit == nil {
+		return nil
+	}
+	entry := policyAuditEntry{
+		Time:     ks.now().UTC(),
+		ID:       req.ID,
+		Type:     req.Type,
+		Version:  req.Version,
+		ChainID:  req.ChainID,
+		Calls:    req.Calls,
+		Decision: decision,
+	}
+	if req.Nonce != nil {
+		entry.Nonce = "0x" + req.Nonce.Text(16)
+	}
+	if req.MaxFee != nil {
+		entry.MaxFee = "0x" + req.MaxFee.Text(16)
+	}
+	if req.Hash != nil {
+		entry.Hash = "0x" + req.Hash.Text(16)
+	}
+	if reason != nil {
+		entry.Reason = reason.Error()
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if _, err := ks.audit.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("audit log: %w", err)
+	}
+	return nil
+}
+
+func feltEqual(a, b *felt.Felt) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(b)
+}
+
+func feltString(value *felt.Felt) string {
+	if value == nil {
+		return "<nil>"
+	}
+	return value.String()
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,348 @@
+package starknetgo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/sjxqqq/starknet-go/rpc"
+	"github.com/sjxqqq/starknet-go/types"
+	"github.com/sjxqqq/starknet-go/utils"
+)
+
+func TestPolicyKeystore(t *testing.T) {
+	token := utils.TestHexToFelt(t, "0x49d36570d4e46f48e99674bd3fcc84644ddd6b96f7c741b1562b82f9e004dc7")
+	recipient := utils.TestHexToFelt(t, "0x1234")
+	transfer := func(selector string, amount int64) types.FunctionCall {
+		return types.FunctionCall{
+			ContractAddress:    token,
+			EntryPointSelector: types.GetSelectorFromNameFelt(selector),
+			Calldata:           []*felt.Felt{recipient, utils.TestBigIntToFelt(t, big.NewInt(amount)), &felt.Zero},
+		}
+	}
+
+	ks := NewMemKeystore()
+	ks.Put(mockAccountPrivateKey, types.HexToBN(mockAccountPrivateKey))
+	audit := &bytes.Buffer{}
+	policyKs := NewPolicyKeystore(ks, Policy{
+		AllowedContracts: []AllowedContract{
+			{Address: token, Selectors: []*felt.Felt{types.GetSelectorFromNameFelt("transfer")}},
+			{Address: utils.TestHexToFelt(t, mockAccountAddress)},
+		},
+		SpendLimits: []SpendLimit{{Token: token, Amount: big.NewInt(100), Period: time.Hour}},
+		MaxFeeWei:   big.NewInt(1000),
+		ChainIDs:    []string{"SN_GOERLI"},
+	}, audit)
+	now := time.Date(2023, 11, 1, 0, 0, 0, 0, time.UTC)
+	policyKs.now = func() time.Time { return now }
+	account, mock := newMockRPCAccount(t, AccountVersion1, AccountSigner(KeystoreSigner(policyKs)))
+
+	type testSetType struct {
+		Call            types.FunctionCall
+		MaxFee          int64
+		Advance         time.Duration
+		ExpectedAllowed bool
+	}
+	testSet := map[string][]testSetType{
+		"mock": {
+			{Call: transfer("transfer", 60), MaxFee: 1000, ExpectedAllowed: true},
+			{Call: transfer("transfer", 50), MaxFee: 1000},
+			{Call: transfer("transfer", 40), MaxFee: 1000, ExpectedAllowed: true},
+			{Call: transfer("approve", 1), MaxFee: 1000},
+			{
+				Call: types.FunctionCall{
+					ContractAddress:    utils.TestHexToFelt(t, "0x42"),
+					EntryPointSelector: types.GetSelectorFromNameFelt("increment"),
+				},
+				MaxFee: 1000,
+			},
+			{
+				Call: types.FunctionCall{
+					ContractAddress:    utils.TestHexToFelt(t, mockAccountAddress),
+					EntryPointSelector: types.GetSelectorFromNameFelt("increment"),
+				},
+				MaxFee: 1001,
+			},
+			{Call: transfer("transfer", 50), MaxFee: 1000, Advance: time.Hour, ExpectedAllowed: true},
+		},
+	}[testEnv]
+
+	decisions := []string{}
+	for _, test := range testSet {
+		now = now.Add(test.Advance)
+		_, err := account.Execute(context.Background(), []types.FunctionCall{test.Call}, types.ExecuteDetails{
+			MaxFee: big.NewInt(test.MaxFee),
+			Nonce:  big.NewInt(int64(len(decisions))),
+		})
+		if !test.ExpectedAllowed {
+			if !errors.Is(err, ErrPolicyViolation) {
+				t.Fatalf("error should be %v, instead: %v", ErrPolicyViolation, err)
+			}
+			decisions = append(decisions, policyDenied)
+			continue
+		}
+		if err != nil {
+			t.Fatal("execute should succeed, instead:", err)
+		}
+		var tx rpc.BroadcastedInvokeV1Transaction
+		mock.lastReceived(t, &tx)
+		if len(tx.Signature) != 2 {
+			t.Fatalf("signature should have 2 elements, instead: %d", len(tx.Signature))
+		}
+		decisions = append(decisions, policyAllowed)
+	}
+
+	// the fee estimation is not capped and does not spend
+	if _, err := account.EstimateFee(context.Background(), []types.FunctionCall{transfer("transfer", 100)}, types.ExecuteDetails{Nonce: big.NewInt(0)}); err != nil {
+		t.Fatal("estimate should succeed, instead:", err)
+	}
+	decisions = append(decisions, policyAllowed)
+	if _, _, err := policyKs.Sign(context.Background(), mockAccountPrivateKey, big.NewInt(1)); !errors.Is(err, ErrPolicyViolation) {
+		t.Fatalf("bare hashes should not be signed, instead: %v", err)
+	}
+	decisions = append(decisions, policyDenied)
+
+	lines := strings.Split(strings.TrimSpace(audit.String()), "\n")
+	if len(lines) != len(decisions) {
+		t.Fatalf("audit log should have %d entries, instead: %d", len(decisions), len(lines))
+	}
+	for i, line := range lines {
+		var entry policyAuditEntry
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatal(err)
+		}
+		if entry.Decision != decisions[i] {
+			t.Fatalf("entry %d should be %s, instead: %s", i, decisions[i], line)
+		}
+		if entry.Decision == policyDenied && entry.Reason == "" {
+			t.Fatalf("entry %d should have a reason: %s", i, line)
+		}
+		if i == 0 && (entry.ChainID != "SN_GOERLI" || entry.MaxFee != "0x3e8" || len(entry.Calls) != 1 || entry.Type != rpc.TransactionType_Invoke) {
+			t.Fatalf("entry should describe the transaction, instead: %s", line)
+		}
+	}
+}
+
+func TestPolicyKeystore_ChainID(t *testing.T) {
+	ks := NewMemKeystore()
+	ks.Put(mockAccountPrivateKey, types.HexToBN(mockAccountPrivateKey))
+	account, _ := newMockRPCAccount(t, AccountVersion1, AccountSigner(KeystoreSigner(NewPolicyKeystore(ks, Policy{ChainIDs: []string{"SN_MAIN"}}, nil))))
+	call := types.FunctionCall{
+		ContractAddress:    utils.TestHexToFelt(t, mockAccountAddress),
+		EntryPointSelector: types.GetSelectorFromNameFelt("increment"),
+	}
+	if _, err := account.Execute(context.Background(), []types.FunctionCall{call}, types.ExecuteDetails{MaxFee: big.NewInt(1000)}); !errors.Is(err, ErrPolicyViolation) {
+		t.Fatalf("error should be %v, instead: %v", ErrPolicyViolation, err)
+	}
+}
+
+func TestPolicyKeystore_TypedData(t *testing.T) {
+	var td TypedData
+	if err := json.Unmarshal([]byte(mockTypedDataJSON), &td); err != nil {
+		t.Fatal(err)
+	}
+	for _, allowed := range []bool{false, true} {
+		ks := NewMemKeystore()
+		ks.Put(mockAccountPrivateKey, types.HexToBN(mockAccountPrivateKey))
+		account, _ := newMockRPCAccount(t, AccountSigner(KeystoreSigner(NewPolicyKeystore(ks, Policy{TypedData: allowed}, nil))))
+		_, err := account.SignTypedData(context.Background(), &td)
+		if !allowed && !errors.Is(err, ErrPolicyViolation) {
+			t.Fatalf("error should be %v, instead: %v", ErrPolicyViolation, err)
+		}
+		if allowed && err != nil {
+			t.Fatal("should sign the typed data, instead:", err)
+		}
+	}
+
+	// the hash of another message is not signed with the typed data attached
+	ks := NewMemKeystore()
+	ks.Put(mockAccountPrivateKey, types.HexToBN(mockAccountPrivateKey))
+	policyKs := NewPolicyKeystore(ks, Policy{TypedData: true}, nil)
+	sender := utils.TestHexToFelt(t, mockAccountAddress)
+	hash, err := td.GetMessageHash(sender.BigInt(big.NewInt(0)), nil, Curve)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, req := range []SignRequest{
+		{ID: mockAccountPrivateKey, Hash: big.NewInt(1), SenderAddress: sender, TypedData: &td},
+		{ID: mockAccountPrivateKey, Hash: hash, SenderAddress: utils.TestHexToFelt(t, "0x1"), TypedData: &td},
+		{ID: mockAccountPrivateKey, Hash: hash, TypedData: &td},
+	} {
+		if _, err := policyKs.SignRequest(context.Background(), req); !errors.Is(err, ErrPolicyViolation) {
+			t.Fatalf("error should be %v, instead: %v", ErrPolicyViolation, err)
+		}
+	}
+	signature, err := policyKs.SignRequest(context.Background(), SignRequest{ID: mockAccountPrivateKey, Hash: hash, SenderAddress: sender, TypedData: &td})
+	if err != nil {
+		t.Fatal("should sign the typed data, instead:", err)
+	}
+	verifyMockSignature(t, hash, signature)
+}
+
+func TestPolicyKeystore_TransactionHash(t *testing.T) {
+	ks := NewMemKeystore()
+	ks.Put(mockAccountPrivateKey, types.HexToBN(mockAccountPrivateKey))
+	sender := utils.TestHexToFelt(t, mockAccountAddress)
+	allowed := types.FunctionCall{
+		ContractAddress:    sender,
+		EntryPointSelector: types.GetSelectorFromNameFelt("increment"),
+	}
+	denied := types.FunctionCall{
+		ContractAddress:    utils.TestHexToFelt(t, "0x42"),
+		EntryPointSelector: types.GetSelectorFromNameFelt("increment"),
+	}
+	encode := func(calls ...types.FunctionCall) []*felt.Felt {
+		calldata, err := utils.HexArrToFelt(fmtCalldataStrings(EncodeCallsCairo0(calls)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return calldata
+	}
+	invoke := SignRequest{
+		ID:            mockAccountPrivateKey,
+		Type:          rpc.TransactionType_Invoke,
+		Version:       rpc.TransactionV1,
+		SenderAddress: sender,
+		Calls:         []types.FunctionCall{allowed},
+		Calldata:      encode(allowed),
+		MaxFee:        big.NewInt(1000),
+		Nonce:         big.NewInt(0),
+		ChainID:       "SN_GOERLI",
+	}
+	// the v3 invoke pays up to 10 x 100 for the L1 gas and a tip of 20 on
+	// each of its 5 units of L2 gas
+	invokeV3 := invoke
+	invokeV3.Version = rpc.TransactionV3
+	invokeV3.ResourceBounds = &types.ResourceBoundsMapping{
+		L1Gas: types.ResourceBounds{MaxAmount: 10, MaxPricePerUnit: big.NewInt(100)},
+		L2Gas: types.ResourceBounds{MaxAmount: 5, MaxPricePerUnit: big.NewInt(0)},
+	}
+	invokeV3.Tip = 20
+	invokeV3.MaxFee = big.NewInt(1100)
+	declare := SignRequest{
+		ID:                mockAccountPrivateKey,
+		Type:              rpc.TransactionType_Declare,
+		Version:           rpc.TransactionV2,
+		SenderAddress:     sender,
+		ClassHash:         utils.TestHexToFelt(t, "0x1"),
+		CompiledClassHash: utils.TestHexToFelt(t, "0x2"),
+		MaxFee:            big.NewInt(1000),
+		Nonce:             big.NewInt(0),
+		ChainID:           "SN_GOERLI",
+	}
+	deployAccount := SignRequest{
+		ID:                  mockAccountPrivateKey,
+		Type:                rpc.TransactionType_DeployAccount,
+		Version:             rpc.TransactionV3,
+		SenderAddress:       sender,
+		ClassHash:           utils.TestHexToFelt(t, "0x1"),
+		ContractAddressSalt: utils.TestHexToFelt(t, "0x2"),
+		ResourceBounds:      &types.ResourceBoundsMapping{L1Gas: types.ResourceBounds{MaxAmount: 10, MaxPricePerUnit: big.NewInt(100)}},
+		MaxFee:              big.NewInt(1000),
+		Nonce:               big.NewInt(0),
+		ChainID:             "SN_GOERLI",
+	}
+	withHash := func(req SignRequest) SignRequest {
+		hash, err := req.TransactionHash()
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Hash = hash
+		return req
+	}
+
+	type testSetType struct {
+		Policy          Policy
+		Request         SignRequest
+		ExpectedAllowed bool
+	}
+	policy := Policy{
+		AllowedContracts: []AllowedContract{{Address: sender}},
+		MaxFeeWei:        big.NewInt(1000),
+	}
+	withMaxFeeFri := func(maxFee int64) Policy {
+		policy := policy
+		policy.MaxFeeFri = big.NewInt(maxFee)
+		return policy
+	}
+	testSet := map[string][]testSetType{
+		"mock": {
+			{Policy: policy, Request: withHash(invoke), ExpectedAllowed: true},
+			// the hash of another transaction
+			{Policy: policy, Request: func() SignRequest {
+				req := withHash(invoke)
+				req.Hash = big.NewInt(1)
+				return req
+			}()},
+			// the calls do not match the hashed calldata
+			{Policy: policy, Request: func() SignRequest {
+				req := withHash(invoke)
+				req.Calls = nil
+				return req
+			}()},
+			{Policy: policy, Request: func() SignRequest {
+				req := withHash(invoke)
+				req.Calls = []types.FunctionCall{denied}
+				return req
+			}()},
+			// a query version does not make the hash of an invoke transaction
+			// skip the rules
+			{Policy: policy, Request: func() SignRequest {
+				req := withHash(invoke)
+				req.Version = rpc.TransactionV1WithQueryBit
+				return req
+			}()},
+			{Policy: policy, Request: func() SignRequest {
+				req := invoke
+				req.Calls = []types.FunctionCall{denied}
+				req.Calldata = encode(denied)
+				return withHash(req)
+			}()},
+			{Policy: policy, Request: func() SignRequest {
+				req := invoke
+				req.MaxFee = big.NewInt(1001)
+				return withHash(req)
+			}()},
+			// the fee caps apply to the transactions paying in their unit,
+			// the tip of a v3 transaction included
+			{Policy: policy, Request: withHash(invokeV3), ExpectedAllowed: true},
+			{Policy: withMaxFeeFri(1100), Request: withHash(invokeV3), ExpectedAllowed: true},
+			{Policy: withMaxFeeFri(1099), Request: withHash(invokeV3)},
+			{Policy: withMaxFeeFri(10), Request: withHash(invoke), ExpectedAllowed: true},
+			{Policy: withMaxFeeFri(1100), Request: func() SignRequest {
+				req := withHash(invokeV3)
+				req.MaxFee = big.NewInt(1000)
+				return req
+			}()},
+			{Policy: policy, Request: withHash(declare)},
+			{Policy: Policy{Declare: true}, Request: withHash(declare), ExpectedAllowed: true},
+			{Policy: policy, Request: withHash(deployAccount)},
+			{Policy: Policy{DeployAccount: true}, Request: withHash(deployAccount), ExpectedAllowed: true},
+			{Policy: Policy{DeployAccount: true}, Request: func() SignRequest {
+				req := withHash(deployAccount)
+				req.MaxFee = big.NewInt(1)
+				return req
+			}()},
+		},
+	}[testEnv]
+
+	for i, test := range testSet {
+		signature, err := NewPolicyKeystore(ks, test.Policy, nil).SignRequest(context.Background(), test.Request)
+		if !test.ExpectedAllowed {
+			if !errors.Is(err, ErrPolicyViolation) {
+				t.Fatalf("%d: error should be %v, instead: %v", i, ErrPolicyViolation, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%d: should sign, instead: %v", i, err)
+		}
+		verifyMockSignature(t, test.Request.Hash, signature)
+	}
+}
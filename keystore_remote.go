@@ -0,0 +1,188 @@
+package starknetgo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"time"
+
+	"github.com/NethermindEth/juno/core/felt"
+	ethrpc "github.com/ethereum/go-ethereum/rpc"
+	"github.com/sjxqqq/starknet-go/utils"
+)
+
+// The JSON-RPC error codes of the signer server. The remote keystore maps
+// them back to the errors of the local keystores.
+const (
+	signerErrorSenderNoExist = 1
+	signerErrorKeyLocked     = 2
+	signerErrorSign          = 3
+)
+
+var signerErrors = map[int]error{
+	signerErrorSenderNoExist: ErrSenderNoExist,
+	signerErrorKeyLocked:     ErrKeyLocked,
+}
+
+// signerError is a failed signature returned by the signer server.
+type signerError struct {
+	code int
+	err  error
+}
+
+func (e signerError) Error() string {
+	return e.err.Error()
+}
+
+func (e signerError) ErrorCode() int {
+	return e.code
+}
+
+// signerService serves the signer_sign method of the signer server.
+type signerService struct {
+	signer Signer
+}
+
+// Sign returns the signature of hash with the key of id.
+func (s *signerService) Sign(ctx context.Context, id string, hash string) ([]*felt.Felt, error) {
+	msgHash, ok := new(big.Int).SetString(hash, 0)
+	if !ok {
+		return nil, signerError{code: signerErrorSign, err: fmt.Errorf("invalid hash %q", hash)}
+	}
+	signature, err := s.signer.SignTransaction(ctx, id, msgHash)
+	if err != nil {
+		code := signerErrorSign
+		for errorCode, target := range signerErrors {
+			if errors.Is(err, target) {
+				code = errorCode
+			}
+		}
+		return nil, signerError{code: code, err: err}
+	}
+	return signature, nil
+}
+
+// NewSignerServer returns a JSON-RPC handler signing hashes with ks, to be
+// used by a RemoteKeystore. Serve it with an http.Server whose TLS
+// configuration requires and verifies client certificates to set up mutual
+// TLS.
+func NewSignerServer(ks Keystore) (http.Handler, error) {
+	server := ethrpc.NewServer()
+	if err := server.RegisterName("signer", &signerService{signer: KeystoreSigner(ks)}); err != nil {
+		return nil, err
+	}
+	return server, nil
+}
+
+// RemoteKeystore implements the Keystore and Signer interfaces by forwarding
+// the signatures to a signer server, see NewSignerServer.
+type RemoteKeystore struct {
+	client  *ethrpc.Client
+	timeout time.Duration
+	retries int
+	backoff time.Duration
+}
+
+// NewRemoteKeystore returns a keystore signing with the signer server at url.
+func NewRemoteKeystore(url string, opts ...RemoteKeystoreOption) (*RemoteKeystore, error) {
+	options := remoteKeystoreOptions{
+		timeout: 10 * time.Second,
+	}
+	for _, opt := range opts {
+		opt.apply(&options)
+	}
+	httpClient := options.httpClient
+	if httpClient == nil {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = options.tlsConfig
+		httpClient = &http.Client{Transport: transport}
+	}
+	client, err := ethrpc.DialHTTPWithClient(url, httpClient)
+	if err != nil {
+		return nil, err
+	}
+	return &RemoteKeystore{
+		client:  client,
+		timeout: options.timeout,
+		retries: options.retries,
+		backoff: options.backoff,
+	}, nil
+}
+
+// Close closes the connection to the signer.
+func (ks *RemoteKeystore) Close() {
+	ks.client.Close()
+}
+
+// Sign signs msgHash with the key of id. The signer must return a [r, s]
+// signature; use SignTransaction for signatures of other lengths.
+func (ks *RemoteKeystore) Sign(ctx context.Context, id string, msgHash *big.Int) (*big.Int, *big.Int, error) {
+	signature, err := ks.SignTransaction(ctx, id, msgHash)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(signature) != 2 {
+		return nil, nil, fmt.Errorf("signature should have 2 elements, instead: %d", len(signature))
+	}
+	return signature[0].BigInt(big.NewInt(0)), signature[1].BigInt(big.NewInt(0)), nil
+}
+
+// SignTransaction signs msgHash with the key of id, retrying when the signer
+// can not be reached.
+func (ks *RemoteKeystore) SignTransaction(ctx context.Context, id string, msgHash *big.Int) ([]*felt.Felt, error) {
+	backoff := ks.backoff
+	for attempt := 0; ; attempt++ {
+		signature, err := ks.sign(ctx, id, msgHash)
+		if err == nil || attempt >= ks.retries || !retryableSignerError(err) || ctx.Err() != nil {
+			return signature, remoteSignerError(err)
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+func (ks *RemoteKeystore) sign(ctx context.Context, id string, msgHash *big.Int) ([]*felt.Felt, error) {
+	if ks.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, ks.timeout)
+		defer cancel()
+	}
+	var signature []*felt.Felt
+	hash, err := utils.BigIntToFelt(msgHash)
+	if err != nil {
+		return nil, err
+	}
+	if err := ks.client.CallContext(ctx, &signature, "signer_sign", id, hash.String()); err != nil {
+		return nil, err
+	}
+	return signature, nil
+}
+
+// remoteSignerError wraps the errors of the signer server with the matching
+// errors of the local keystores.
+func remoteSignerError(err error) error {
+	var rpcErr ethrpc.Error
+	if errors.As(err, &rpcErr) {
+		if target, ok := signerErrors[rpcErr.ErrorCode()]; ok {
+			return fmt.Errorf("%s: %w", rpcErr.Error(), target)
+		}
+	}
+	return err
+}
+
+// retryableSignerError returns true when err means the signer was not reached
+// or failed before signing, rather than refused to sign.
+func retryableSignerError(err error) bool {
+	var httpErr ethrpc.HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode >= http.StatusInternalServerError || httpErr.StatusCode == http.StatusTooManyRequests
+	}
+	var rpcErr ethrpc.Error
+	return !errors.As(err, &rpcErr)
+}
@@ -0,0 +1,209 @@
+package starknetgo
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"io"
+	"log"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/sjxqqq/starknet-go/types"
+	"github.com/sjxqqq/starknet-go/utils"
+)
+
+// testCertificate returns a certificate signed by parent, or a self-signed CA
+// certificate when parent is nil.
+func testCertificate(t *testing.T, parent *tls.Certificate, serial int64) tls.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "starknet-go test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	signerCert, signerKey := template, any(key)
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+	} else {
+		signerCert = parent.Leaf
+		signerKey = parent.PrivateKey
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, signerCert, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}
+}
+
+// newTestSignerServer starts a signer server requiring client certificates
+// signed by ca. wrap, when set, wraps the signer handler.
+func newTestSignerServer(t *testing.T, ca tls.Certificate, wrap func(http.Handler) http.Handler) *httptest.Server {
+	t.Helper()
+	ks := NewMemKeystore()
+	ks.Put(mockAccountPrivateKey, types.HexToBN(mockAccountPrivateKey))
+	handler, err := NewSignerServer(ks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if wrap != nil {
+		handler = wrap(handler)
+	}
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.Leaf)
+	server := httptest.NewUnstartedServer(handler)
+	// the handshakes refused without client certificate are expected
+	server.Config.ErrorLog = log.New(io.Discard, "", 0)
+	server.TLS = &tls.Config{
+		Certificates: []tls.Certificate{testCertificate(t, &ca, 2)},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    pool,
+	}
+	server.StartTLS()
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestRemoteKeystore(t *testing.T) {
+	ca := testCertificate(t, nil, 1)
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.Leaf)
+	clientTLS := &tls.Config{
+		Certificates: []tls.Certificate{testCertificate(t, &ca, 3)},
+		RootCAs:      pool,
+	}
+
+	var attempts, failures int32
+	server := newTestSignerServer(t, ca, func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&attempts, 1)
+			if atomic.AddInt32(&failures, -1) >= 0 {
+				http.Error(w, "unavailable", http.StatusServiceUnavailable)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	})
+
+	type testSetType struct {
+		ID               string
+		Options          []RemoteKeystoreOption
+		Failures         int32
+		ExpectedAttempts int32
+		ExpectedError    error
+		ExpectedFailure  bool
+	}
+	testSet := map[string][]testSetType{
+		"mock": {
+			{
+				ID:               mockAccountPrivateKey,
+				Options:          []RemoteKeystoreOption{WithRemoteTLS(clientTLS)},
+				ExpectedAttempts: 1,
+			},
+			{
+				ID:               mockAccountPrivateKey,
+				Options:          []RemoteKeystoreOption{WithRemoteTLS(clientTLS), WithRemoteRetries(2, time.Millisecond)},
+				Failures:         2,
+				ExpectedAttempts: 3,
+			},
+			{
+				ID:               mockAccountPrivateKey,
+				Options:          []RemoteKeystoreOption{WithRemoteTLS(clientTLS), WithRemoteRetries(1, time.Millisecond)},
+				Failures:         2,
+				ExpectedAttempts: 2,
+				ExpectedFailure:  true,
+			},
+			{
+				// refused signatures are not retried
+				ID:               "0x1",
+				Options:          []RemoteKeystoreOption{WithRemoteTLS(clientTLS), WithRemoteRetries(2, time.Millisecond)},
+				ExpectedAttempts: 1,
+				ExpectedError:    ErrSenderNoExist,
+			},
+			{
+				// the signer requires a client certificate
+				ID:              mockAccountPrivateKey,
+				Options:         []RemoteKeystoreOption{WithRemoteTLS(&tls.Config{RootCAs: pool})},
+				ExpectedFailure: true,
+			},
+		},
+	}[testEnv]
+
+	hash := big.NewInt(1234)
+	for _, test := range testSet {
+		atomic.StoreInt32(&attempts, 0)
+		atomic.StoreInt32(&failures, test.Failures)
+		ks, err := NewRemoteKeystore(server.URL, test.Options...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		x, y, err := ks.Sign(context.Background(), test.ID, hash)
+		ks.Close()
+		if test.ExpectedError != nil || test.ExpectedFailure {
+			if err == nil || (test.ExpectedError != nil && !errors.Is(err, test.ExpectedError)) {
+				t.Fatalf("error should be %v, instead: %v", test.ExpectedError, err)
+			}
+		} else {
+			if err != nil {
+				t.Fatal("sign should succeed, instead:", err)
+			}
+			verifyMockSignature(t, hash, []*felt.Felt{utils.TestBigIntToFelt(t, x), utils.TestBigIntToFelt(t, y)})
+		}
+		if test.ExpectedAttempts != 0 && atomic.LoadInt32(&attempts) != test.ExpectedAttempts {
+			t.Fatalf("signer should be called %d times, instead: %d", test.ExpectedAttempts, attempts)
+		}
+	}
+}
+
+func TestRemoteKeystore_Timeout(t *testing.T) {
+	ca := testCertificate(t, nil, 1)
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.Leaf)
+	server := newTestSignerServer(t, ca, func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(300 * time.Millisecond):
+			}
+		})
+	})
+	ks, err := NewRemoteKeystore(server.URL, WithRemoteTimeout(50*time.Millisecond), WithRemoteTLS(&tls.Config{
+		Certificates: []tls.Certificate{testCertificate(t, &ca, 3)},
+		RootCAs:      pool,
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ks.Close()
+	start := time.Now()
+	if _, _, err := ks.Sign(context.Background(), mockAccountPrivateKey, big.NewInt(1)); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("error should be %v, instead: %v", context.DeadlineExceeded, err)
+	}
+	if time.Since(start) > 500*time.Millisecond {
+		t.Fatal("sign should stop at the timeout")
+	}
+}
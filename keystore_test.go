@@ -0,0 +1,89 @@
+package starknetgo
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/sjxqqq/starknet-go/rpc"
+	"github.com/sjxqqq/starknet-go/types"
+	"github.com/sjxqqq/starknet-go/utils"
+)
+
+// multisigSigner concatenates the signatures of several keystores, the way a
+// multisig account expects them.
+type multisigSigner []Keystore
+
+func (m multisigSigner) SignTransaction(ctx context.Context, id string, msgHash *big.Int) ([]*felt.Felt, error) {
+	signature := []*felt.Felt{}
+	for _, ks := range m {
+		part, err := KeystoreSigner(ks).SignTransaction(ctx, id, msgHash)
+		if err != nil {
+			return nil, err
+		}
+		signature = append(signature, part...)
+	}
+	return signature, nil
+}
+
+func TestKeystoreSigner(t *testing.T) {
+	ks := NewMemKeystore()
+	ks.Put(mockAccountPrivateKey, types.HexToBN(mockAccountPrivateKey))
+	hash := big.NewInt(1234)
+	signature, err := KeystoreSigner(ks).SignTransaction(context.Background(), mockAccountPrivateKey, hash)
+	if err != nil {
+		t.Fatal("sign should succeed, instead:", err)
+	}
+	verifyMockSignature(t, hash, signature)
+
+	if _, err := KeystoreSigner(ks).SignTransaction(context.Background(), "0x1", hash); err == nil {
+		t.Fatal("unknown sender should fail")
+	}
+	signer := multisigSigner{ks}
+	if _, ok := KeystoreSigner(wrappedSigner{Keystore: ks, Signer: signer}).(wrappedSigner); !ok {
+		t.Fatal("keystores implementing Signer should not be wrapped")
+	}
+}
+
+// wrappedSigner is a Keystore that also implements Signer.
+type wrappedSigner struct {
+	Keystore
+	Signer
+}
+
+func TestRPCAccount_Signer(t *testing.T) {
+	ks := NewMemKeystore()
+	ks.Put(mockAccountPrivateKey, types.HexToBN(mockAccountPrivateKey))
+	account, mock := newMockRPCAccount(t, AccountVersion1, AccountSigner(multisigSigner{ks, ks}))
+	call := types.FunctionCall{
+		ContractAddress:    utils.TestHexToFelt(t, mockAccountAddress),
+		EntryPointSelector: types.GetSelectorFromNameFelt("increment"),
+	}
+	_, err := account.Execute(context.Background(), []types.FunctionCall{call}, types.ExecuteDetails{MaxFee: big.NewInt(1000)})
+	if err != nil {
+		t.Fatal("execute should succeed, instead:", err)
+	}
+	var tx rpc.BroadcastedInvokeV1Transaction
+	mock.lastReceived(t, &tx)
+	if len(tx.Signature) != 4 {
+		t.Fatalf("signature should have 4 elements, instead: %d", len(tx.Signature))
+	}
+	hash, err := account.TransactionHash([]types.FunctionCall{call}, types.ExecuteDetails{Nonce: big.NewInt(0), MaxFee: big.NewInt(1000)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	verifyMockSignature(t, hash, tx.Signature[:2])
+	verifyMockSignature(t, hash, tx.Signature[2:])
+
+	if _, err := newAccount(nil, nil, nil, AccountSigner(nil)); err == nil {
+		t.Fatal("nil signer should fail")
+	}
+	noSigner, err := newAccount(utils.TestHexToFelt(t, "0x1"), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := noSigner.signRequest(context.Background(), SignRequest{Hash: hash}); err == nil {
+		t.Fatal("account without signer should fail")
+	}
+}
@@ -1,20 +1,76 @@
 package starknetgo
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"math/big"
+	"sort"
+
+	"github.com/sjxqqq/starknet-go/types"
+)
+
+// MerkleHashType is the hash of the pairs of nodes of a FixedSizeMerkleTree.
+type MerkleHashType string
+
+const (
+	// MerkleHashPedersen hashes the sorted pairs with HashElements, as
+	// MerkleHash.
+	MerkleHashPedersen MerkleHashType = "pedersen"
+	// MerkleHashPoseidon hashes the sorted pairs with PoseidonHash.
+	MerkleHashPoseidon MerkleHashType = "poseidon"
 )
 
+// Hash returns the hash of the pair of x and y, in ascending order so the
+// proofs do not depend on the side of the nodes.
+func (h MerkleHashType) Hash(x, y *big.Int) (*big.Int, error) {
+	if x.Cmp(y) > 0 {
+		x, y = y, x
+	}
+	switch h {
+	case "", MerkleHashPedersen:
+		return Curve.HashElements([]*big.Int{x, y})
+	case MerkleHashPoseidon:
+		return Curve.PoseidonHash(x, y)
+	}
+	return nil, fmt.Errorf("unknown merkle hash %q", string(h))
+}
+
+// VerifyProof returns true when path leads from leaf to root.
+func (h MerkleHashType) VerifyProof(root, leaf *big.Int, path []*big.Int) bool {
+	node := leaf
+	for _, sibling := range path {
+		var err error
+		if node, err = h.Hash(node, sibling); err != nil {
+			return false
+		}
+	}
+	return root.Cmp(node) == 0
+}
+
+/*
+FixedSizeMerkleTree is a Merkle tree of the sorted pairs of its nodes. A node
+without sibling is paired with 0. Branches holds the levels of the tree from
+the leaves, without the root.
+*/
 type FixedSizeMerkleTree struct {
 	Leaves   []*big.Int
 	Branches [][]*big.Int
 	Root     *big.Int
+	Hash     MerkleHashType
 }
 
+// NewFixedSizeMerkleTree returns the tree of leaves hashed with Pedersen.
 func NewFixedSizeMerkleTree(leaves ...*big.Int) (*FixedSizeMerkleTree, error) {
+	return NewFixedSizeMerkleTreeWithHash(MerkleHashPedersen, leaves...)
+}
+
+// NewFixedSizeMerkleTreeWithHash returns the tree of leaves hashed with hash.
+func NewFixedSizeMerkleTreeWithHash(hash MerkleHashType, leaves ...*big.Int) (*FixedSizeMerkleTree, error) {
 	mt := &FixedSizeMerkleTree{
 		Leaves:   leaves,
 		Branches: [][]*big.Int{},
+		Hash:     hash,
 	}
 	root, err := mt.build(leaves)
 	if err != nil {
@@ -25,77 +81,334 @@ func NewFixedSizeMerkleTree(leaves ...*big.Int) (*FixedSizeMerkleTree, error) {
 }
 
 func MerkleHash(x, y *big.Int) (*big.Int, error) {
-	if x.Cmp(y) <= 0 {
-		return Curve.HashElements([]*big.Int{x, y})
-	}
-	return Curve.HashElements([]*big.Int{y, x})
+	return MerkleHashPedersen.Hash(x, y)
 }
 
 func (mt *FixedSizeMerkleTree) build(leaves []*big.Int) (*big.Int, error) {
-	if len(leaves) == 1 {
-		return leaves[0], nil
-	}
-	mt.Branches = append(mt.Branches, leaves)
-	newLeaves := []*big.Int{}
-	for i := 0; i < len(leaves); i += 2 {
-		if i+1 == len(leaves) {
-			hash, err := MerkleHash(leaves[i], big.NewInt(0))
+	if len(leaves) == 0 {
+		return nil, errors.New("merkle tree without leaves")
+	}
+	level := leaves
+	for len(level) > 1 {
+		mt.Branches = append(mt.Branches, level)
+		next := make([]*big.Int, 0, (len(level)+1)/2)
+		for i := 0; i < len(level); i += 2 {
+			sibling := big.NewInt(0)
+			if i+1 < len(level) {
+				sibling = level[i+1]
+			}
+			hash, err := mt.Hash.Hash(level[i], sibling)
 			if err != nil {
 				return nil, err
 			}
-			newLeaves = append(newLeaves, hash)
-			break
+			next = append(next, hash)
 		}
-		hash, err := MerkleHash(leaves[i], leaves[i+1])
-		if err != nil {
-			return nil, err
-		}
-		newLeaves = append(newLeaves, hash)
+		level = next
 	}
-	return mt.build(newLeaves)
+	return level[0], nil
 }
 
+// Proof returns the path of the first leaf of value leaf. Use ProofAt for the
+// trees with duplicate leaves.
 func (mt *FixedSizeMerkleTree) Proof(leaf *big.Int) ([]*big.Int, error) {
-	return mt.recursiveProof(leaf, 0, []*big.Int{})
+	for index, value := range mt.Leaves {
+		if value.Cmp(leaf) == 0 {
+			proof, err := mt.ProofAt(index)
+			if err != nil {
+				return nil, err
+			}
+			return proof.Path, nil
+		}
+	}
+	return nil, fmt.Errorf("key 0x%s not found in branch", leaf.Text(16))
 }
 
-func (mt *FixedSizeMerkleTree) recursiveProof(leaf *big.Int, branchIndex int, hashPath []*big.Int) ([]*big.Int, error) {
-	if branchIndex >= len(mt.Branches) {
-		return hashPath, nil
+// MerkleProof is the path of the leaf at Index of a tree.
+type MerkleProof struct {
+	Hash  MerkleHashType
+	Index int
+	Leaf  *big.Int
+	Path  []*big.Int
+}
+
+// ProofAt returns the proof of the leaf at index.
+func (mt *FixedSizeMerkleTree) ProofAt(index int) (*MerkleProof, error) {
+	if index < 0 || index >= len(mt.Leaves) {
+		return nil, fmt.Errorf("leaf index %d out of range [0, %d)", index, len(mt.Leaves))
+	}
+	proof := &MerkleProof{
+		Hash:  mt.Hash,
+		Index: index,
+		Leaf:  mt.Leaves[index],
+		Path:  make([]*big.Int, 0, len(mt.Branches)),
+	}
+	for _, branch := range mt.Branches {
+		proof.Path = append(proof.Path, merkleSibling(branch, index))
+		index /= 2
 	}
-	branch := mt.Branches[branchIndex]
-	index := -1
-	for k, v := range branch {
-		if v.Cmp(leaf) == 0 {
-			index = k
-			break
+	return proof, nil
+}
+
+// Verify returns true when the proof leads to root.
+func (p *MerkleProof) Verify(root *big.Int) bool {
+	return p.Hash.VerifyProof(root, p.Leaf, p.Path)
+}
+
+/*
+MerkleMultiProof proves several leaves of a tree of LeafCount leaves at once.
+Hashes are the siblings the leaves and their parents cannot compute, level by
+level from the leaves and by ascending index in each level.
+*/
+type MerkleMultiProof struct {
+	Hash      MerkleHashType
+	LeafCount int
+	Indices   []int
+	Leaves    []*big.Int
+	Hashes    []*big.Int
+}
+
+// MultiProof returns the proof of the leaves at indices.
+func (mt *FixedSizeMerkleTree) MultiProof(indices ...int) (*MerkleMultiProof, error) {
+	proof := &MerkleMultiProof{
+		Hash:      mt.Hash,
+		LeafCount: len(mt.Leaves),
+		Indices:   append([]int{}, indices...),
+		Leaves:    make([]*big.Int, len(indices)),
+		Hashes:    []*big.Int{},
+	}
+	for i, index := range indices {
+		if index < 0 || index >= len(mt.Leaves) {
+			return nil, fmt.Errorf("leaf index %d out of range [0, %d)", index, len(mt.Leaves))
 		}
+		proof.Leaves[i] = mt.Leaves[index]
 	}
-	if index == -1 {
-		return nil, fmt.Errorf("key 0x%s not found in branch", leaf.Text(16))
+	known, err := sortedMerkleIndices(indices)
+	if err != nil {
+		return nil, err
 	}
-	nextProof := big.NewInt(0)
-	if index%2 == 0 && index < len(branch) {
-		nextProof = branch[index+1]
+	for _, branch := range mt.Branches {
+		next := []int{}
+		for i := 0; i < len(known); i++ {
+			index := known[i]
+			if index%2 == 0 && i+1 < len(known) && known[i+1] == index+1 {
+				// both children are known
+				i++
+			} else if index^1 < len(branch) {
+				proof.Hashes = append(proof.Hashes, branch[index^1])
+			}
+			next = append(next, index/2)
+		}
+		known = next
+	}
+	return proof, nil
+}
+
+// Verify returns true when the leaves and the hashes of the proof lead to
+// root.
+func (p *MerkleMultiProof) Verify(root *big.Int) bool {
+	if len(p.Indices) != len(p.Leaves) || len(p.Indices) == 0 {
+		return false
 	}
-	if index%2 != 0 {
-		nextProof = branch[index-1]
+	nodes := map[int]*big.Int{}
+	for i, index := range p.Indices {
+		if index < 0 || index >= p.LeafCount {
+			return false
+		}
+		if known, ok := nodes[index]; ok && known.Cmp(p.Leaves[i]) != 0 {
+			return false
+		}
+		nodes[index] = p.Leaves[i]
 	}
-	newLeaf, err := MerkleHash(leaf, nextProof)
+	known, err := sortedMerkleIndices(p.Indices)
 	if err != nil {
-		return nil, fmt.Errorf("nextproof error: %v", err)
+		return false
+	}
+	hashes := p.Hashes
+	for size := p.LeafCount; size > 1; size = (size + 1) / 2 {
+		next := []int{}
+		parents := map[int]*big.Int{}
+		for i := 0; i < len(known); i++ {
+			index := known[i]
+			var sibling *big.Int
+			switch {
+			case index%2 == 0 && i+1 < len(known) && known[i+1] == index+1:
+				sibling = nodes[index+1]
+				i++
+			case index^1 >= size:
+				sibling = big.NewInt(0)
+			default:
+				if len(hashes) == 0 {
+					return false
+				}
+				sibling, hashes = hashes[0], hashes[1:]
+			}
+			parent, err := p.Hash.Hash(nodes[index], sibling)
+			if err != nil {
+				return false
+			}
+			parents[index/2] = parent
+			next = append(next, index/2)
+		}
+		nodes, known = parents, next
 	}
-	newHashPath := append(hashPath, nextProof)
-	return mt.recursiveProof(newLeaf, branchIndex+1, newHashPath)
+	return len(hashes) == 0 && nodes[0] != nil && nodes[0].Cmp(root) == 0
+}
+
+// merkleSibling returns the sibling of the node at index of branch, 0 for the
+// last node of an odd branch.
+func merkleSibling(branch []*big.Int, index int) *big.Int {
+	if index^1 < len(branch) {
+		return branch[index^1]
+	}
+	return big.NewInt(0)
+}
+
+// sortedMerkleIndices returns the distinct indices in ascending order.
+func sortedMerkleIndices(indices []int) ([]int, error) {
+	if len(indices) == 0 {
+		return nil, errors.New("multiproof without leaves")
+	}
+	sorted := append([]int{}, indices...)
+	sort.Ints(sorted)
+	distinct := sorted[:1]
+	for _, index := range sorted[1:] {
+		if index != distinct[len(distinct)-1] {
+			distinct = append(distinct, index)
+		}
+	}
+	return distinct, nil
 }
 
 func ProofMerklePath(root *big.Int, leaf *big.Int, path []*big.Int) bool {
-	if len(path) == 0 {
-		return root.Cmp(leaf) == 0
+	return MerkleHashPedersen.VerifyProof(root, leaf, path)
+}
+
+type merkleTreeJSON struct {
+	Hash   MerkleHashType `json:"hash"`
+	Leaves []string       `json:"leaves"`
+	Root   string         `json:"root"`
+}
+
+// MarshalJSON encodes the hash, leaves and root of the tree, the branches
+// being rebuilt when it is decoded.
+func (mt FixedSizeMerkleTree) MarshalJSON() ([]byte, error) {
+	hash := mt.Hash
+	if hash == "" {
+		hash = MerkleHashPedersen
+	}
+	return json.Marshal(merkleTreeJSON{
+		Hash:   hash,
+		Leaves: bigsToHex(mt.Leaves),
+		Root:   types.BigToHex(mt.Root),
+	})
+}
+
+func (mt *FixedSizeMerkleTree) UnmarshalJSON(data []byte) error {
+	var raw merkleTreeJSON
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
 	}
-	nexLeaf, err := MerkleHash(leaf, path[0])
+	leaves, err := hexToBigs(raw.Leaves)
 	if err != nil {
-		return false
+		return err
+	}
+	tree, err := NewFixedSizeMerkleTreeWithHash(raw.Hash, leaves...)
+	if err != nil {
+		return err
+	}
+	if raw.Root != "" {
+		root, err := hexToBigs([]string{raw.Root})
+		if err != nil {
+			return err
+		}
+		if root[0].Cmp(tree.Root) != 0 {
+			return fmt.Errorf("merkle root %s does not match the leaves", raw.Root)
+		}
+	}
+	*mt = *tree
+	return nil
+}
+
+type merkleProofJSON struct {
+	Hash  MerkleHashType `json:"hash"`
+	Index int            `json:"index"`
+	Leaf  string         `json:"leaf"`
+	Path  []string       `json:"path"`
+}
+
+func (p MerkleProof) MarshalJSON() ([]byte, error) {
+	return json.Marshal(merkleProofJSON{
+		Hash:  p.Hash,
+		Index: p.Index,
+		Leaf:  types.BigToHex(p.Leaf),
+		Path:  bigsToHex(p.Path),
+	})
+}
+
+func (p *MerkleProof) UnmarshalJSON(data []byte) error {
+	var raw merkleProofJSON
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	values, err := hexToBigs(append([]string{raw.Leaf}, raw.Path...))
+	if err != nil {
+		return err
+	}
+	*p = MerkleProof{Hash: raw.Hash, Index: raw.Index, Leaf: values[0], Path: values[1:]}
+	return nil
+}
+
+type merkleMultiProofJSON struct {
+	Hash      MerkleHashType `json:"hash"`
+	LeafCount int            `json:"leaf_count"`
+	Indices   []int          `json:"indices"`
+	Leaves    []string       `json:"leaves"`
+	Hashes    []string       `json:"hashes"`
+}
+
+func (p MerkleMultiProof) MarshalJSON() ([]byte, error) {
+	return json.Marshal(merkleMultiProofJSON{
+		Hash:      p.Hash,
+		LeafCount: p.LeafCount,
+		Indices:   p.Indices,
+		Leaves:    bigsToHex(p.Leaves),
+		Hashes:    bigsToHex(p.Hashes),
+	})
+}
+
+func (p *MerkleMultiProof) UnmarshalJSON(data []byte) error {
+	var raw merkleMultiProofJSON
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	leaves, err := hexToBigs(raw.Leaves)
+	if err != nil {
+		return err
+	}
+	hashes, err := hexToBigs(raw.Hashes)
+	if err != nil {
+		return err
+	}
+	*p = MerkleMultiProof{Hash: raw.Hash, LeafCount: raw.LeafCount, Indices: raw.Indices, Leaves: leaves, Hashes: hashes}
+	return nil
+}
+
+func bigsToHex(values []*big.Int) []string {
+	hexes := make([]string, len(values))
+	for i, value := range values {
+		hexes[i] = types.BigToHex(value)
+	}
+	return hexes
+}
+
+func hexToBigs(hexes []string) ([]*big.Int, error) {
+	values := make([]*big.Int, len(hexes))
+	for i, hex := range hexes {
+		value, ok := new(big.Int).SetString(hex, 0)
+		if !ok {
+			return nil, fmt.Errorf("invalid merkle value %q", hex)
+		}
+		values[i] = value
 	}
-	return ProofMerklePath(root, nexLeaf, path[1:])
+	return values, nil
 }
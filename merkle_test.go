@@ -1,6 +1,7 @@
 package starknetgo
 
 import (
+	"encoding/json"
 	"math/big"
 	"testing"
 )
@@ -46,3 +47,126 @@ func TestGeneral_FixedSizeMerkleTree_Check1(t *testing.T) {
 		t.Fatal("root should match proof. it does not")
 	}
 }
+
+func TestGeneral_FixedSizeMerkleTree_ProofAt(t *testing.T) {
+	type testSetType struct {
+		Hash    MerkleHashType
+		Leaves  []int64
+		Indices []int
+	}
+	testSet := map[string][]testSetType{
+		"mock": {
+			{Hash: MerkleHashPedersen, Leaves: []int64{1}, Indices: []int{0}},
+			{Hash: MerkleHashPedersen, Leaves: []int64{1, 2, 3, 4, 5, 6, 7}, Indices: []int{0, 3, 6}},
+			{Hash: MerkleHashPedersen, Leaves: []int64{5, 5, 9, 5, 9}, Indices: []int{1, 3, 4}},
+			{Hash: MerkleHashPoseidon, Leaves: []int64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11}, Indices: []int{0, 1, 5, 10}},
+		},
+	}[testEnv]
+
+	for _, test := range testSet {
+		leaves := []*big.Int{}
+		for _, leaf := range test.Leaves {
+			leaves = append(leaves, big.NewInt(leaf))
+		}
+		tree, err := NewFixedSizeMerkleTreeWithHash(test.Hash, leaves...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, index := range test.Indices {
+			proof, err := tree.ProofAt(index)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !proof.Verify(tree.Root) {
+				t.Fatalf("%s proof of leaf %d should match the root", test.Hash, index)
+			}
+			proof.Leaf = big.NewInt(0xbad)
+			if proof.Verify(tree.Root) {
+				t.Fatalf("%s proof of a tampered leaf %d should not match the root", test.Hash, index)
+			}
+		}
+
+		multiProof, err := tree.MultiProof(test.Indices...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !multiProof.Verify(tree.Root) {
+			t.Fatalf("%s multiproof of %v should match the root", test.Hash, test.Indices)
+		}
+		encoded, err := json.Marshal(multiProof)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var decoded MerkleMultiProof
+		if err := json.Unmarshal(encoded, &decoded); err != nil {
+			t.Fatal(err)
+		}
+		if !decoded.Verify(tree.Root) {
+			t.Fatalf("%s decoded multiproof %s should match the root", test.Hash, encoded)
+		}
+		decoded.Leaves[0] = big.NewInt(0xbad)
+		if decoded.Verify(tree.Root) {
+			t.Fatalf("%s multiproof of a tampered leaf should not match the root", test.Hash)
+		}
+	}
+}
+
+func TestGeneral_FixedSizeMerkleTree_JSON(t *testing.T) {
+	tree, err := NewFixedSizeMerkleTreeWithHash(MerkleHashPoseidon, big.NewInt(1), big.NewInt(2), big.NewInt(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	encoded, err := json.Marshal(tree)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded FixedSizeMerkleTree
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Hash != MerkleHashPoseidon || decoded.Root.Cmp(tree.Root) != 0 || len(decoded.Branches) != len(tree.Branches) {
+		t.Fatalf("decoded tree %s should match the tree", encoded)
+	}
+
+	proof, err := tree.ProofAt(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encoded, err = json.Marshal(proof)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decodedProof MerkleProof
+	if err := json.Unmarshal(encoded, &decodedProof); err != nil {
+		t.Fatal(err)
+	}
+	if !decodedProof.Verify(tree.Root) {
+		t.Fatalf("decoded proof %s should match the root", encoded)
+	}
+
+	tampered := []byte(`{"hash":"poseidon","leaves":["0x1","0x2","0x3"],"root":"0x1"}`)
+	if err := json.Unmarshal(tampered, &decoded); err == nil {
+		t.Fatal("a root not matching the leaves should fail")
+	}
+}
+
+func TestGeneral_FixedSizeMerkleTree_Large(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping the large tree in short mode")
+	}
+	leaves := make([]*big.Int, 100_000)
+	for i := range leaves {
+		leaves[i] = big.NewInt(int64(i))
+	}
+	tree, err := NewFixedSizeMerkleTreeWithHash(MerkleHashPoseidon, leaves...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	proof, err := tree.ProofAt(99_999)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(proof.Path) != 17 || !proof.Verify(tree.Root) {
+		t.Fatalf("proof of the last leaf should match the root, got %d nodes", len(proof.Path))
+	}
+}
@@ -0,0 +1,109 @@
+package starknetgo
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	_ "embed"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+//go:embed bip39_english.txt
+var bip39English string
+
+// bip39Words is the BIP-39 English wordlist, and bip39Indexes the index of
+// each word in it.
+var (
+	bip39Words   = strings.Fields(bip39English)
+	bip39Indexes = func() map[string]int {
+		indexes := make(map[string]int, len(bip39Words))
+		for i, word := range bip39Words {
+			indexes[word] = i
+		}
+		return indexes
+	}()
+)
+
+var ErrInvalidMnemonic = errors.New("invalid mnemonic")
+
+// NewMnemonic returns a BIP-39 mnemonic of bits random bits of entropy: 128
+// bits give 12 words and 256 bits give 24 words.
+func NewMnemonic(bits int) (string, error) {
+	if bits < 128 || bits > 256 || bits%32 != 0 {
+		return "", fmt.Errorf("entropy of %d bits unsupported", bits)
+	}
+	entropy := make([]byte, bits/8)
+	if _, err := rand.Read(entropy); err != nil {
+		return "", err
+	}
+	return MnemonicFromEntropy(entropy)
+}
+
+// MnemonicFromEntropy returns the BIP-39 mnemonic encoding entropy, which must
+// be 16 to 32 bytes long and a multiple of 4 bytes.
+func MnemonicFromEntropy(entropy []byte) (string, error) {
+	if len(entropy) < 16 || len(entropy) > 32 || len(entropy)%4 != 0 {
+		return "", fmt.Errorf("entropy of %d bytes unsupported", len(entropy))
+	}
+	checksumBits := len(entropy) / 4
+	checksum := sha256.Sum256(entropy)
+	// the entropy followed by the first bits of its hash, in groups of 11 bits
+	value := new(big.Int).SetBytes(entropy)
+	value.Lsh(value, uint(checksumBits))
+	value.Or(value, big.NewInt(int64(checksum[0]>>(8-checksumBits))))
+	words := make([]string, (len(entropy)*8+checksumBits)/11)
+	mask := big.NewInt(2047)
+	for i := len(words) - 1; i >= 0; i-- {
+		words[i] = bip39Words[new(big.Int).And(value, mask).Int64()]
+		value.Rsh(value, 11)
+	}
+	return strings.Join(words, " "), nil
+}
+
+// MnemonicToEntropy returns the entropy encoded by mnemonic, checking its
+// words and checksum.
+func MnemonicToEntropy(mnemonic string) ([]byte, error) {
+	words := strings.Fields(mnemonic)
+	if len(words) < 12 || len(words) > 24 || len(words)%3 != 0 {
+		return nil, fmt.Errorf("%w: %d words", ErrInvalidMnemonic, len(words))
+	}
+	value := new(big.Int)
+	for _, word := range words {
+		index, ok := bip39Indexes[word]
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown word %q", ErrInvalidMnemonic, word)
+		}
+		value.Lsh(value, 11)
+		value.Or(value, big.NewInt(int64(index)))
+	}
+	checksumBits := len(words) / 3
+	checksum := new(big.Int).And(value, big.NewInt(int64(1<<checksumBits-1)))
+	value.Rsh(value, uint(checksumBits))
+	entropy := value.FillBytes(make([]byte, checksumBits*4))
+	hash := sha256.Sum256(entropy)
+	if int64(hash[0]>>(8-checksumBits)) != checksum.Int64() {
+		return nil, fmt.Errorf("%w: checksum mismatch", ErrInvalidMnemonic)
+	}
+	return entropy, nil
+}
+
+// ValidateMnemonic checks the words and checksum of a BIP-39 mnemonic.
+func ValidateMnemonic(mnemonic string) error {
+	_, err := MnemonicToEntropy(mnemonic)
+	return err
+}
+
+// MnemonicToSeed returns the BIP-39 seed of mnemonic, protected by the
+// optional passphrase. Non-ASCII passphrases must be NFKD normalized.
+func MnemonicToSeed(mnemonic, passphrase string) ([]byte, error) {
+	if err := ValidateMnemonic(mnemonic); err != nil {
+		return nil, err
+	}
+	normalized := strings.Join(strings.Fields(mnemonic), " ")
+	return pbkdf2.Key([]byte(normalized), []byte("mnemonic"+passphrase), 2048, 64, sha512.New), nil
+}
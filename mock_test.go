@@ -0,0 +1,279 @@
+package starknetgo
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/NethermindEth/juno/core/felt"
+	ethrpc "github.com/ethereum/go-ethereum/rpc"
+	"github.com/sjxqqq/starknet-go/rpc"
+	"github.com/sjxqqq/starknet-go/types"
+	"github.com/sjxqqq/starknet-go/utils"
+)
+
+const (
+	mockAccountAddress    = "0x043784df59268c02b716e20bf77797bd96c68c2f100b2a634e448c35e3ad363e"
+	mockAccountPrivateKey = "0x4b2a5ce2f6c9d2d3ae4f3cd1f0b7b2b6a6bd0b0ae1ec8bb0a3c6b1cfa1a7b2c"
+	mockTransactionHash   = "0xdeadbeef"
+)
+
+// rpcMockError is returned by the mock node to reproduce the JSON-RPC error
+// objects a real Starknet node sends back.
+type rpcMockError struct {
+	code    int
+	message string
+}
+
+func (e rpcMockError) Error() string {
+	return e.message
+}
+
+func (e rpcMockError) ErrorCode() int {
+	return e.code
+}
+
+// rpcMock is a minimal in-process Starknet JSON-RPC node used to test the
+// account without a devnet. Methods are registered under the "starknet"
+// namespace, e.g. AddDeclareTransaction serves starknet_addDeclareTransaction.
+type rpcMock struct {
+	mu        sync.Mutex
+	nonce     uint64
+	nonceTags []string
+	classHash string
+	err       error
+	received  []json.RawMessage
+	// estimate is returned by EstimateFee for every transaction.
+	estimate rpc.FeeEstimate
+	// gasPrices holds the L1 gas price in wei of each block, the last one
+	// being the latest block.
+	gasPrices []uint64
+	// friPrices holds the L1 gas price in fri of each block, if any.
+	friPrices []uint64
+	// answerDelay delays the answer to the invoke transactions received.
+	answerDelay time.Duration
+	// revertReason makes the simulated executions revert.
+	revertReason    string
+	simulationFlags []string
+}
+
+func (m *rpcMock) ChainId() (string, error) {
+	return fmt.Sprintf("0x%x", types.UTF8StrToBig("SN_GOERLI")), nil
+}
+
+func (m *rpcMock) GetNonce(blockID json.RawMessage, address string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var tag string
+	if err := json.Unmarshal(blockID, &tag); err == nil {
+		m.nonceTags = append(m.nonceTags, tag)
+	}
+	return fmt.Sprintf("0x%x", m.nonce), nil
+}
+
+func (m *rpcMock) AddDeclareTransaction(tx json.RawMessage) (*rpc.AddDeclareTransactionResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.err != nil {
+		return nil, m.err
+	}
+	m.received = append(m.received, tx)
+	txHash, err := utils.HexToFelt(mockTransactionHash)
+	if err != nil {
+		return nil, err
+	}
+	classHash, err := utils.HexToFelt(m.classHash)
+	if err != nil {
+		return nil, err
+	}
+	return &rpc.AddDeclareTransactionResponse{
+		TransactionHash: txHash,
+		ClassHash:       classHash,
+	}, nil
+}
+
+func (m *rpcMock) AddInvokeTransaction(tx json.RawMessage) (*rpc.AddInvokeTransactionResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.err != nil {
+		return nil, m.err
+	}
+	m.received = append(m.received, tx)
+	time.Sleep(m.answerDelay)
+	txHash, err := utils.HexToFelt(mockTransactionHash)
+	if err != nil {
+		return nil, err
+	}
+	return &rpc.AddInvokeTransactionResponse{TransactionHash: txHash}, nil
+}
+
+func (m *rpcMock) AddDeployAccountTransaction(tx json.RawMessage) (*rpc.AddDeployAccountTransactionResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.err != nil {
+		return nil, m.err
+	}
+	m.received = append(m.received, tx)
+	var deployAccount rpc.BroadcastedDeployAccountTransaction
+	if err := json.Unmarshal(tx, &deployAccount); err != nil {
+		return nil, err
+	}
+	address, err := PrecomputeAccountAddress(deployAccount.ContractAddressSalt, deployAccount.ClassHash, deployAccount.ConstructorCalldata)
+	if err != nil {
+		return nil, err
+	}
+	txHash, err := utils.HexToFelt(mockTransactionHash)
+	if err != nil {
+		return nil, err
+	}
+	return &rpc.AddDeployAccountTransactionResponse{
+		TransactionHash: txHash,
+		ContractAddress: address,
+	}, nil
+}
+
+func (m *rpcMock) EstimateFee(txs []json.RawMessage, blockID json.RawMessage) ([]rpc.FeeEstimate, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.received = append(m.received, txs...)
+	estimates := []rpc.FeeEstimate{}
+	for range txs {
+		estimates = append(estimates, m.estimate)
+	}
+	return estimates, nil
+}
+
+func (m *rpcMock) SimulateTransactions(blockID json.RawMessage, txs []json.RawMessage, flags []string) ([]map[string]interface{}, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.received = append(m.received, txs...)
+	m.simulationFlags = flags
+	simulated := []map[string]interface{}{}
+	for _, raw := range txs {
+		var tx struct {
+			SenderAddress string   `json:"sender_address"`
+			Calldata      []string `json:"calldata"`
+		}
+		if err := json.Unmarshal(raw, &tx); err != nil {
+			return nil, err
+		}
+		execute := map[string]interface{}{
+			"contract_address":     tx.SenderAddress,
+			"entry_point_selector": types.GetSelectorFromNameFelt("__execute__").String(),
+			"calldata":             tx.Calldata,
+			"result":               []string{"0x1"},
+		}
+		if m.revertReason != "" {
+			execute = map[string]interface{}{"revert_reason": m.revertReason}
+		}
+		simulated = append(simulated, map[string]interface{}{
+			"transaction_trace": map[string]interface{}{
+				"validate_invocation":     map[string]interface{}{},
+				"execute_invocation":      execute,
+				"fee_transfer_invocation": map[string]interface{}{},
+			},
+			"gas_consumed": m.estimate.GasConsumed,
+			"gas_price":    m.estimate.GasPrice,
+			"overall_fee":  m.estimate.OverallFee,
+		})
+	}
+	return simulated, nil
+}
+
+func (m *rpcMock) BlockNumber() (uint64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.gasPrices) == 0 {
+		return 0, rpcMockError{code: 32, message: "There are no blocks"}
+	}
+	return uint64(len(m.gasPrices) - 1), nil
+}
+
+func (m *rpcMock) GetBlockWithTxHashes(blockID json.RawMessage) (map[string]interface{}, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var id struct {
+		BlockNumber *uint64 `json:"block_number"`
+	}
+	if err := json.Unmarshal(blockID, &id); err != nil || id.BlockNumber == nil || *id.BlockNumber >= uint64(len(m.gasPrices)) {
+		return nil, rpcMockError{code: 24, message: "Block not found"}
+	}
+	gasPrice := map[string]string{
+		"price_in_wei": fmt.Sprintf("0x%x", m.gasPrices[*id.BlockNumber]),
+	}
+	if *id.BlockNumber < uint64(len(m.friPrices)) {
+		gasPrice["price_in_fri"] = fmt.Sprintf("0x%x", m.friPrices[*id.BlockNumber])
+	}
+	return map[string]interface{}{
+		"block_hash":        fmt.Sprintf("0x%x", *id.BlockNumber+1),
+		"parent_hash":       fmt.Sprintf("0x%x", *id.BlockNumber),
+		"block_number":      *id.BlockNumber,
+		"new_root":          "0x0",
+		"timestamp":         0,
+		"sequencer_address": "0x1",
+		"l1_gas_price":      gasPrice,
+		"status":            "ACCEPTED_ON_L2",
+		"transactions":      []string{},
+	}, nil
+}
+
+// lastReceived unmarshals the last transaction received by the mock into v.
+func (m *rpcMock) lastReceived(t *testing.T, v interface{}) {
+	t.Helper()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.received) == 0 {
+		t.Fatal("mock should have received a transaction")
+	}
+	if err := json.Unmarshal(m.received[len(m.received)-1], v); err != nil {
+		t.Fatal("should unmarshal the received transaction, instead:", err)
+	}
+}
+
+// newMockRPCAccount returns an account connected to a fresh in-process mock.
+func newMockRPCAccount(t *testing.T, options ...AccountOptionFunc) (*Account, *rpcMock) {
+	t.Helper()
+	mock := &rpcMock{classHash: "0x1"}
+	server := ethrpc.NewServer()
+	if err := server.RegisterName("starknet", mock); err != nil {
+		t.Fatal("should register the mock, instead:", err)
+	}
+	client := ethrpc.DialInProc(server)
+	t.Cleanup(func() {
+		client.Close()
+		server.Stop()
+	})
+	ks := NewMemKeystore()
+	ks.Put(mockAccountPrivateKey, types.HexToBN(mockAccountPrivateKey))
+	account, err := NewRPCAccount(
+		utils.TestHexToFelt(t, mockAccountPrivateKey),
+		utils.TestHexToFelt(t, mockAccountAddress),
+		ks,
+		rpc.NewProvider(client),
+		options...,
+	)
+	if err != nil {
+		t.Fatal("should create the account, instead:", err)
+	}
+	return account, mock
+}
+
+// verifyMockSignature checks the signature was produced by the mock account key.
+func verifyMockSignature(t *testing.T, hash *big.Int, signature []*felt.Felt) {
+	t.Helper()
+	if len(signature) != 2 {
+		t.Fatalf("signature should have 2 elements, instead: %d", len(signature))
+	}
+	x, y, err := Curve.PrivateToPoint(types.HexToBN(mockAccountPrivateKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := signature[0].BigInt(big.NewInt(0))
+	s := signature[1].BigInt(big.NewInt(0))
+	if !Curve.Verify(hash, r, s, x, y) {
+		t.Fatalf("signature should match hash 0x%x", hash)
+	}
+}
@@ -0,0 +1,210 @@
+package starknetgo
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sort"
+	"sync"
+
+	"github.com/sjxqqq/starknet-go/gateway"
+	"github.com/sjxqqq/starknet-go/rpc"
+)
+
+// nonceRejections are the errors of the node rejecting the nonce or the
+// transaction, after which the account nonce is read from the node again.
+var nonceRejections = []*rpc.RPCError{
+	rpc.ErrInvalidTransactionNonce,
+	rpc.ErrInsufficientMaxFee,
+	rpc.ErrInsufficientAccountBalance,
+	rpc.ErrValidationFailure,
+	rpc.ErrDuplicateTx,
+}
+
+// gatewayNonceRejections are the codes of the same errors on the gateway.
+var gatewayNonceRejections = []string{
+	"StarknetErrorCode.INVALID_TRANSACTION_NONCE",
+	"StarknetErrorCode.INSUFFICIENT_MAX_FEE",
+	"StarknetErrorCode.INSUFFICIENT_ACCOUNT_BALANCE",
+	"StarknetErrorCode.VALIDATE_FAILURE",
+	"StarknetErrorCode.DUPLICATED_TRANSACTION",
+}
+
+// isNonceRejection returns true when err is the node rejecting the nonce or
+// the transaction.
+func isNonceRejection(err error) bool {
+	for _, rejection := range nonceRejections {
+		if errors.Is(err, rejection) {
+			return true
+		}
+	}
+	var gatewayErr *gateway.Error
+	return errors.As(err, &gatewayErr) && containsString(gatewayNonceRejections, gatewayErr.Code)
+}
+
+// sentError is the error of the request sending a transaction to the node.
+// The node may have received the transaction before the request failed, e.g.
+// on a timeout, so the nonce of the transaction is not handed out again.
+type sentError struct {
+	err error
+}
+
+func (e sentError) Error() string {
+	return e.err.Error()
+}
+
+func (e sentError) Unwrap() error {
+	return e.err
+}
+
+// sendError marks err, the error of the request sending a transaction, as a
+// sentError.
+func sendError(err error) error {
+	if err == nil {
+		return nil
+	}
+	return sentError{err: err}
+}
+
+// unwrapSentError returns the error of the request sending a transaction
+// marked by sendError, or err.
+func unwrapSentError(err error) error {
+	var sent sentError
+	if errors.As(err, &sent) {
+		return sent.err
+	}
+	return err
+}
+
+// NonceManager hands out sequential nonces for one account so several
+// transactions can be sent in parallel without colliding. It reads the
+// pending nonce from the node on first use and after every resync, and never
+// hands out a nonce still in flight.
+type NonceManager struct {
+	mu       sync.Mutex
+	fetch    func(context.Context) (*big.Int, error)
+	next     *big.Int
+	inFlight map[string]*big.Int
+	// released are the nonces put back unused, in order. They are handed
+	// out again before next.
+	released []*big.Int
+}
+
+// NewNonceManager returns a NonceManager that reads the account nonce with
+// fetch. fetch should return the nonce of the pending block.
+func NewNonceManager(fetch func(context.Context) (*big.Int, error)) *NonceManager {
+	return &NonceManager{
+		fetch:    fetch,
+		inFlight: map[string]*big.Int{},
+	}
+}
+
+// sync reads the nonce from the node when the local state is unknown, and
+// moves the next nonce past the nonces in flight. It must be called with the
+// lock held.
+func (nm *NonceManager) sync(ctx context.Context) error {
+	if nm.next == nil {
+		nonce, err := nm.fetch(ctx)
+		if err != nil {
+			return err
+		}
+		nm.next = new(big.Int).Set(nonce)
+		nm.released = nil
+	}
+	for nm.inFlight[nm.next.String()] != nil {
+		nm.next.Add(nm.next, big.NewInt(1))
+	}
+	return nil
+}
+
+// Next reserves and returns the next nonce. The nonce stays in flight until
+// it is released with Confirm, Fail or Release.
+func (nm *NonceManager) Next(ctx context.Context) (*big.Int, error) {
+	nm.mu.Lock()
+	defer nm.mu.Unlock()
+	if err := nm.sync(ctx); err != nil {
+		return nil, err
+	}
+	var nonce *big.Int
+	if len(nm.released) > 0 {
+		nonce = nm.released[0]
+		nm.released = nm.released[1:]
+	} else {
+		nonce = new(big.Int).Set(nm.next)
+		nm.next.Add(nm.next, big.NewInt(1))
+	}
+	nm.inFlight[nonce.String()] = nonce
+	return new(big.Int).Set(nonce), nil
+}
+
+// Peek returns the nonce the next call to Next would reserve.
+func (nm *NonceManager) Peek(ctx context.Context) (*big.Int, error) {
+	nm.mu.Lock()
+	defer nm.mu.Unlock()
+	if err := nm.sync(ctx); err != nil {
+		return nil, err
+	}
+	if len(nm.released) > 0 {
+		return new(big.Int).Set(nm.released[0]), nil
+	}
+	return new(big.Int).Set(nm.next), nil
+}
+
+// Confirm releases a nonce once its transaction was accepted by the node.
+func (nm *NonceManager) Confirm(nonce *big.Int) {
+	nm.mu.Lock()
+	defer nm.mu.Unlock()
+	delete(nm.inFlight, nonce.String())
+}
+
+// Fail releases a nonce whose transaction the node rejected, and resyncs with
+// the node on the next call. The nonces still in flight are not handed out
+// again.
+func (nm *NonceManager) Fail(nonce *big.Int) {
+	nm.mu.Lock()
+	defer nm.mu.Unlock()
+	delete(nm.inFlight, nonce.String())
+	nm.next = nil
+}
+
+// Release puts back a nonce whose transaction was never sent, e.g. because it
+// could not be estimated or signed, so the next call to Next reuses it.
+func (nm *NonceManager) Release(nonce *big.Int) {
+	nm.mu.Lock()
+	defer nm.mu.Unlock()
+	if _, ok := nm.inFlight[nonce.String()]; !ok {
+		return
+	}
+	delete(nm.inFlight, nonce.String())
+	// the nonces below the node nonce are read again on resync
+	if nm.next == nil || nonce.Cmp(nm.next) >= 0 {
+		return
+	}
+	i := sort.Search(len(nm.released), func(i int) bool {
+		return nm.released[i].Cmp(nonce) > 0
+	})
+	nm.released = append(nm.released, nil)
+	copy(nm.released[i+1:], nm.released[i:])
+	nm.released[i] = new(big.Int).Set(nonce)
+}
+
+// Resync drops the local nonce so the next call reads it from the node again.
+func (nm *NonceManager) Resync() {
+	nm.mu.Lock()
+	defer nm.mu.Unlock()
+	nm.next = nil
+}
+
+// InFlight returns the nonces reserved and not released yet, in order.
+func (nm *NonceManager) InFlight() []*big.Int {
+	nm.mu.Lock()
+	defer nm.mu.Unlock()
+	nonces := make([]*big.Int, 0, len(nm.inFlight))
+	for _, nonce := range nm.inFlight {
+		nonces = append(nonces, new(big.Int).Set(nonce))
+	}
+	sort.Slice(nonces, func(i, j int) bool {
+		return nonces[i].Cmp(nonces[j]) < 0
+	})
+	return nonces
+}
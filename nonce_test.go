@@ -0,0 +1,221 @@
+package starknetgo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sjxqqq/starknet-go/gateway"
+	"github.com/sjxqqq/starknet-go/rpc"
+	"github.com/sjxqqq/starknet-go/types"
+	"github.com/sjxqqq/starknet-go/utils"
+)
+
+func TestNonceManager(t *testing.T) {
+	fetched := 0
+	chainNonce := big.NewInt(5)
+	nm := NewNonceManager(func(context.Context) (*big.Int, error) {
+		fetched++
+		return chainNonce, nil
+	})
+
+	var wg sync.WaitGroup
+	nonces := make(chan *big.Int, 20)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			nonce, err := nm.Next(context.Background())
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			nonces <- nonce
+		}()
+	}
+	wg.Wait()
+	close(nonces)
+	seen := map[int64]bool{}
+	for nonce := range nonces {
+		seen[nonce.Int64()] = true
+	}
+	for i := int64(5); i < 25; i++ {
+		if !seen[i] {
+			t.Fatalf("nonce %d should have been handed out", i)
+		}
+	}
+	if fetched != 1 {
+		t.Fatalf("nonce should be fetched once, instead: %d", fetched)
+	}
+	if inFlight := nm.InFlight(); len(inFlight) != 20 || inFlight[0].Int64() != 5 || inFlight[19].Int64() != 24 {
+		t.Fatalf("unexpected in flight nonces %v", inFlight)
+	}
+
+	for i := int64(5); i < 24; i++ {
+		nm.Confirm(big.NewInt(i))
+	}
+	// the transaction with nonce 24 fails, the node still expects 24
+	chainNonce = big.NewInt(24)
+	nm.Fail(big.NewInt(24))
+	if inFlight := nm.InFlight(); len(inFlight) != 0 {
+		t.Fatalf("no nonce should be in flight, instead: %v", inFlight)
+	}
+	nonce, err := nm.Peek(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if nonce.Int64() != 24 || fetched != 2 {
+		t.Fatalf("nonce should be resynced to 24, instead: %d (fetched %d)", nonce, fetched)
+	}
+}
+
+func TestNonceManager_FetchError(t *testing.T) {
+	errNode := errors.New("node unavailable")
+	nm := NewNonceManager(func(context.Context) (*big.Int, error) {
+		return nil, errNode
+	})
+	if _, err := nm.Next(context.Background()); !errors.Is(err, errNode) {
+		t.Fatalf("error should be %v, instead: %v", errNode, err)
+	}
+	if inFlight := nm.InFlight(); len(inFlight) != 0 {
+		t.Fatalf("no nonce should be in flight, instead: %v", inFlight)
+	}
+}
+
+// TestNonceManager_InFlight checks a resync and a released nonce never hand
+// out a nonce still in flight.
+func TestNonceManager_InFlight(t *testing.T) {
+	chainNonce := big.NewInt(5)
+	nm := NewNonceManager(func(context.Context) (*big.Int, error) {
+		return chainNonce, nil
+	})
+	next := func() int64 {
+		nonce, err := nm.Next(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return nonce.Int64()
+	}
+
+	// A gets 5 and B gets 6, then the node rejects A
+	a, b := next(), next()
+	if a != 5 || b != 6 {
+		t.Fatalf("nonces should be 5 and 6, instead: %d and %d", a, b)
+	}
+	nm.Fail(big.NewInt(a))
+	if c, d := next(), next(); c != 5 || d != 7 {
+		t.Fatalf("nonces should be 5 and 7 while 6 is in flight, instead: %d and %d", c, d)
+	}
+
+	// a nonce put back before its transaction is sent is reused first
+	nm.Release(big.NewInt(5))
+	nm.Release(big.NewInt(6))
+	nonce, err := nm.Peek(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if nonce.Int64() != 5 {
+		t.Fatalf("peek should return the released nonce 5, instead: %d", nonce)
+	}
+	if e, f, g := next(), next(), next(); e != 5 || f != 6 || g != 8 {
+		t.Fatalf("nonces should be 5, 6 and 8, instead: %d, %d and %d", e, f, g)
+	}
+	// releasing a nonce twice does not hand it out twice
+	nm.Release(big.NewInt(8))
+	nm.Release(big.NewInt(8))
+	if h, i := next(), next(); h != 8 || i != 9 {
+		t.Fatalf("nonces should be 8 and 9, instead: %d and %d", h, i)
+	}
+}
+
+// TestRPCAccount_NonceSentError checks the nonce of a transaction whose
+// request failed after it was sent is not handed out again, while the nonce of
+// a transaction that failed before being sent is reused.
+func TestRPCAccount_NonceSentError(t *testing.T) {
+	feeErr := errors.New("fee strategy failed")
+	failFee := false
+	strategy := FeeStrategyFunc(func(context.Context, FeeRequest) (*big.Int, string, error) {
+		if failFee {
+			return nil, "", feeErr
+		}
+		return big.NewInt(1000), "fixed", nil
+	})
+	account, mock := newMockRPCAccount(t, AccountVersion1, AccountNonceManager, AccountFeeStrategy(strategy))
+	mock.nonce = 10
+	calls := []types.FunctionCall{{
+		ContractAddress:    utils.TestHexToFelt(t, mockAccountAddress),
+		EntryPointSelector: types.GetSelectorFromNameFelt("increment"),
+	}}
+	peek := func() int64 {
+		nonce, err := account.NonceManager().Peek(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return nonce.Int64()
+	}
+
+	// the transaction fails before being sent
+	failFee = true
+	if _, err := account.Execute(context.Background(), calls, types.ExecuteDetails{}); !errors.Is(err, feeErr) {
+		t.Fatalf("error should be %v, instead: %v", feeErr, err)
+	}
+	failFee = false
+	if nonce := peek(); nonce != 10 {
+		t.Fatalf("nonce 10 should be reused, instead: %d", nonce)
+	}
+
+	// the node receives the transaction, but its answer is lost
+	mock.answerDelay = 100 * time.Millisecond
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := account.Execute(ctx, calls, types.ExecuteDetails{})
+	if err == nil {
+		t.Fatal("execute should fail")
+	}
+	var sent sentError
+	if errors.As(err, &sent) {
+		t.Fatalf("the provider error should be returned as is, instead: %#v", err)
+	}
+	var tx rpc.BroadcastedInvokeV1Transaction
+	mock.lastReceived(t, &tx)
+	if tx.Nonce.String() != "0xa" {
+		t.Fatalf("nonce 0xa should be sent, instead: %s", tx.Nonce)
+	}
+	// the node took the nonce 10
+	mock.mu.Lock()
+	mock.answerDelay = 0
+	mock.nonce = 11
+	mock.mu.Unlock()
+	if nonce := peek(); nonce != 11 {
+		t.Fatalf("nonce 10 should not be handed out again, instead: %d", nonce)
+	}
+	if inFlight := account.NonceManager().InFlight(); len(inFlight) != 0 {
+		t.Fatalf("no nonce should be in flight, instead: %v", inFlight)
+	}
+}
+
+func TestIsNonceRejection(t *testing.T) {
+	type testSetType struct {
+		Err      error
+		Expected bool
+	}
+	testSet := map[string][]testSetType{
+		"mock": {
+			{Err: rpc.ErrInvalidTransactionNonce, Expected: true},
+			{Err: fmt.Errorf("add invoke: %w", rpc.ErrValidationFailure), Expected: true},
+			{Err: &gateway.Error{Code: "StarknetErrorCode.INVALID_TRANSACTION_NONCE"}, Expected: true},
+			{Err: rpc.ErrContractError},
+			{Err: &gateway.Error{Code: "StarknetErrorCode.UNINITIALIZED_CONTRACT"}},
+			{Err: errors.New("account has no signer")},
+		},
+	}[testEnv]
+	for _, test := range testSet {
+		if isNonceRejection(test.Err) != test.Expected {
+			t.Fatalf("rejection of %v should be %v", test.Err, test.Expected)
+		}
+	}
+}
@@ -0,0 +1,369 @@
+package starknetgo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"sync"
+
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/sjxqqq/starknet-go/gateway"
+	"github.com/sjxqqq/starknet-go/rpc"
+	"github.com/sjxqqq/starknet-go/types"
+)
+
+// ProviderOffline is the provider type of the accounts created with
+// NewOfflineAccount. They sign transactions without any network access.
+const ProviderOffline ProviderType = "offline"
+
+var (
+	ErrMissingMaxFee   = errors.New("offline transactions need a max fee or resource bounds")
+	ErrChainIDMismatch = errors.New("transaction signed for another chain")
+)
+
+// SignedTransaction is a signed transaction envelope, ready to be sent with
+// Broadcast. Transaction holds the RPC broadcasted transaction of Type and
+// Version.
+type SignedTransaction struct {
+	Type        rpc.TransactionType    `json:"type"`
+	Version     rpc.TransactionVersion `json:"version"`
+	ChainID     string                 `json:"chain_id"`
+	Transaction json.RawMessage        `json:"transaction"`
+}
+
+// BroadcastOutput is the result of Broadcast. ClassHash is only set for
+// declare transactions and ContractAddress for deploy-account transactions.
+type BroadcastOutput struct {
+	TransactionHash string `json:"transaction_hash"`
+	ClassHash       string `json:"class_hash,omitempty"`
+	ContractAddress string `json:"contract_address,omitempty"`
+}
+
+// offlineNonce is the next nonce of an offline account. A nonce is reserved
+// before a transaction is signed and put back when the signature fails, so
+// concurrent signatures never share a nonce and a failed one does not skip
+// it.
+type offlineNonce struct {
+	mu   sync.Mutex
+	next *big.Int
+	// released are the nonces put back, in order. They are reserved again
+	// before next.
+	released []*big.Int
+}
+
+// reserve returns the nonce of a transaction signed without an explicit
+// nonce.
+func (n *offlineNonce) reserve() *big.Int {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if len(n.released) > 0 {
+		nonce := n.released[0]
+		n.released = n.released[1:]
+		return nonce
+	}
+	nonce := new(big.Int).Set(n.next)
+	n.next.Add(n.next, big.NewInt(1))
+	return nonce
+}
+
+// rollback puts back a reserved nonce whose transaction was not signed.
+func (n *offlineNonce) rollback(nonce *big.Int) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	i := sort.Search(len(n.released), func(i int) bool {
+		return n.released[i].Cmp(nonce) > 0
+	})
+	n.released = append(n.released, nil)
+	copy(n.released[i+1:], n.released[i:])
+	n.released[i] = nonce
+}
+
+// signed moves the next nonce past the explicit nonce of a signed
+// transaction.
+func (n *offlineNonce) signed(nonce *big.Int) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if nonce.Cmp(n.next) >= 0 {
+		n.next = new(big.Int).Add(nonce, big.NewInt(1))
+	}
+	kept := n.released[:0]
+	for _, released := range n.released {
+		if released.Cmp(nonce) != 0 {
+			kept = append(kept, released)
+		}
+	}
+	n.released = kept
+}
+
+// NewOfflineAccount returns an account that signs transactions for chainID
+// without reaching a node. nonce is the next nonce of the account; it is
+// incremented by every transaction signed without an explicit nonce.
+func NewOfflineAccount(sender, address *felt.Felt, ks Keystore, chainID string, nonce *big.Int, options ...AccountOptionFunc) (*Account, error) {
+	if chainID == "" {
+		return nil, errors.New("offline accounts need a chain id")
+	}
+	if nonce == nil || nonce.Sign() < 0 {
+		return nil, errors.New("offline accounts need a nonce")
+	}
+	account, err := newAccount(sender, address, ks, options...)
+	if err != nil {
+		return nil, err
+	}
+	if account.version != 1 && account.version != 3 {
+		return nil, fmt.Errorf("version %d unsupported", account.version)
+	}
+	account.chainId = chainID
+	account.provider = ProviderOffline
+	account.offline = &offlineNonce{next: new(big.Int).Set(nonce)}
+	return account, nil
+}
+
+// signOffline fills the nonce of details, checks the fee is set and runs sign.
+// details.MaxFee is required by version 1 accounts and details.ResourceBounds
+// by version 3 accounts.
+// The nonce of the account is reserved before sign runs and put back when
+// it fails.
+func (account *Account) signOffline(txType rpc.TransactionType, details types.ExecuteDetails, sign func(types.ExecuteDetails) (rpc.TransactionVersion, any, error)) (*SignedTransaction, error) {
+	if account.provider != ProviderOffline {
+		return nil, ErrUnsupportedAccount
+	}
+	if (account.version == 3 && details.ResourceBounds == nil) || (account.version != 3 && details.MaxFee == nil) {
+		return nil, ErrMissingMaxFee
+	}
+	reserved := details.Nonce == nil
+	if reserved {
+		details.Nonce = account.offline.reserve()
+	}
+	version, tx, err := sign(details)
+	var content []byte
+	if err == nil {
+		content, err = json.Marshal(tx)
+	}
+	if err != nil {
+		if reserved {
+			account.offline.rollback(details.Nonce)
+		}
+		return nil, err
+	}
+	if !reserved {
+		account.offline.signed(details.Nonce)
+	}
+	return &SignedTransaction{
+		Type:        txType,
+		Version:     version,
+		ChainID:     account.chainId,
+		Transaction: content,
+	}, nil
+}
+
+// SignInvoke returns the signed INVOKE transaction of calls. details must set
+// MaxFee for version 1 accounts and ResourceBounds for version 3 accounts.
+func (account *Account) SignInvoke(ctx context.Context, calls []types.FunctionCall, details types.ExecuteDetails) (*SignedTransaction, error) {
+	return account.signOffline(rpc.TransactionType_Invoke, details, func(details types.ExecuteDetails) (rpc.TransactionVersion, any, error) {
+		switch account.version {
+		case 1:
+			tx, err := account.prepFunctionInvokeRPC(ctx, "invoke", calls, details)
+			if err != nil {
+				return "", nil, err
+			}
+			tx.Type = "INVOKE"
+			return rpc.TransactionV1, *tx, nil
+		case 3:
+			tx, err := account.prepInvokeV3(ctx, calls, details, false)
+			if err != nil {
+				return "", nil, err
+			}
+			return rpc.TransactionV3, *tx, nil
+		}
+		return "", nil, ErrUnsupportedAccount
+	})
+}
+
+// SignDeclare returns the signed DECLARE transaction of a Cairo 1 class. It is
+// a version 2 transaction for version 1 accounts and a version 3 transaction
+// for version 3 accounts.
+func (account *Account) SignDeclare(ctx context.Context, contract rpc.ContractClass, casm rpc.CasmClass, details types.ExecuteDetails) (*SignedTransaction, error) {
+	return account.signOffline(rpc.TransactionType_Declare, details, func(details types.ExecuteDetails) (rpc.TransactionVersion, any, error) {
+		switch account.version {
+		case 1:
+			tx, _, err := account.signDeclareV2(ctx, contract, casm, details)
+			return rpc.TransactionV2, tx, err
+		case 3:
+			tx, _, err := account.signDeclareV3(ctx, contract, casm, details)
+			return rpc.TransactionV3, tx, err
+		}
+		return "", nil, ErrUnsupportedAccount
+	})
+}
+
+// SignDeployAccount returns the signed DEPLOY_ACCOUNT transaction of the
+// account. Its nonce is 0 unless details sets another one.
+func (account *Account) SignDeployAccount(ctx context.Context, classHash, salt *felt.Felt, constructorCalldata []*felt.Felt, details types.ExecuteDetails) (*SignedTransaction, error) {
+	if details.Nonce == nil {
+		details.Nonce = big.NewInt(0)
+	}
+	return account.signOffline(rpc.TransactionType_DeployAccount, details, func(details types.ExecuteDetails) (rpc.TransactionVersion, any, error) {
+		switch account.version {
+		case 1:
+			tx, err := account.signDeployAccount(ctx, classHash, salt, constructorCalldata, details)
+			return rpc.TransactionV1, tx, err
+		case 3:
+			tx, err := account.signDeployAccountV3(ctx, classHash, salt, constructorCalldata, details)
+			return rpc.TransactionV3, tx, err
+		}
+		return "", nil, ErrUnsupportedAccount
+	})
+}
+
+// Broadcast sends a signed transaction with provider, a *rpc.Provider or a
+// *gateway.GatewayProvider. It returns ErrChainIDMismatch without sending the
+// transaction when it was signed for another chain than the provider's. The
+// gateway only accepts version 1 invoke and deploy-account transactions.
+func Broadcast(ctx context.Context, provider interface{}, tx SignedTransaction) (*BroadcastOutput, error) {
+	var chainID string
+	var err error
+	switch p := provider.(type) {
+	case *rpc.Provider:
+		chainID, err = p.ChainID(ctx)
+	case *gateway.GatewayProvider:
+		chainID, err = p.ChainID(ctx)
+	default:
+		return nil, errors.New("unsupported provider")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if chainID != tx.ChainID {
+		return nil, fmt.Errorf("%w: signed for %q, provider on %q", ErrChainIDMismatch, tx.ChainID, chainID)
+	}
+	switch p := provider.(type) {
+	case *rpc.Provider:
+		return broadcastRPC(ctx, p, tx)
+	case *gateway.GatewayProvider:
+		return broadcastGateway(ctx, p, tx)
+	}
+	return nil, errors.New("unsupported provider")
+}
+
+func broadcastRPC(ctx context.Context, provider *rpc.Provider, tx SignedTransaction) (*BroadcastOutput, error) {
+	switch {
+	case tx.Type == rpc.TransactionType_Invoke && tx.Version == rpc.TransactionV1:
+		var invoke rpc.BroadcastedInvokeV1Transaction
+		if err := json.Unmarshal(tx.Transaction, &invoke); err != nil {
+			return nil, err
+		}
+		resp, err := provider.AddInvokeTransaction(ctx, invoke)
+		if err != nil {
+			return nil, err
+		}
+		return &BroadcastOutput{TransactionHash: resp.TransactionHash.String()}, nil
+	case tx.Type == rpc.TransactionType_Invoke && tx.Version == rpc.TransactionV3:
+		var invoke rpc.BroadcastedInvokeV3Transaction
+		if err := json.Unmarshal(tx.Transaction, &invoke); err != nil {
+			return nil, err
+		}
+		resp, err := provider.AddInvokeTransaction(ctx, invoke)
+		if err != nil {
+			return nil, err
+		}
+		return &BroadcastOutput{TransactionHash: resp.TransactionHash.String()}, nil
+	case tx.Type == rpc.TransactionType_Declare && tx.Version == rpc.TransactionV2:
+		var declare rpc.BroadcastedDeclareTransactionV2
+		if err := json.Unmarshal(tx.Transaction, &declare); err != nil {
+			return nil, err
+		}
+		return broadcastDeclareRPC(ctx, provider, declare)
+	case tx.Type == rpc.TransactionType_Declare && tx.Version == rpc.TransactionV3:
+		var declare rpc.BroadcastedDeclareTransactionV3
+		if err := json.Unmarshal(tx.Transaction, &declare); err != nil {
+			return nil, err
+		}
+		return broadcastDeclareRPC(ctx, provider, declare)
+	case tx.Type == rpc.TransactionType_DeployAccount && tx.Version == rpc.TransactionV1:
+		var deploy rpc.BroadcastedDeployAccountTransaction
+		if err := json.Unmarshal(tx.Transaction, &deploy); err != nil {
+			return nil, err
+		}
+		return broadcastDeployAccountRPC(ctx, provider, deploy)
+	case tx.Type == rpc.TransactionType_DeployAccount && tx.Version == rpc.TransactionV3:
+		var deploy rpc.BroadcastedDeployAccountTransactionV3
+		if err := json.Unmarshal(tx.Transaction, &deploy); err != nil {
+			return nil, err
+		}
+		return broadcastDeployAccountRPC(ctx, provider, deploy)
+	}
+	return nil, fmt.Errorf("%s version %s unsupported", tx.Type, tx.Version)
+}
+
+func broadcastDeclareRPC(ctx context.Context, provider *rpc.Provider, declare rpc.BroadcastedDeclareTransaction) (*BroadcastOutput, error) {
+	resp, err := provider.AddDeclareTransaction(ctx, declare)
+	if err != nil {
+		return nil, err
+	}
+	return &BroadcastOutput{
+		TransactionHash: resp.TransactionHash.String(),
+		ClassHash:       resp.ClassHash.String(),
+	}, nil
+}
+
+func broadcastDeployAccountRPC(ctx context.Context, provider *rpc.Provider, deploy rpc.BroadcastedDeployAccountTxn) (*BroadcastOutput, error) {
+	resp, err := provider.AddDeployAccountTransaction(ctx, deploy)
+	if err != nil {
+		return nil, err
+	}
+	return &BroadcastOutput{
+		TransactionHash: resp.TransactionHash.String(),
+		ContractAddress: resp.ContractAddress.String(),
+	}, nil
+}
+
+func broadcastGateway(ctx context.Context, provider *gateway.GatewayProvider, tx SignedTransaction) (*BroadcastOutput, error) {
+	if tx.Version != rpc.TransactionV1 {
+		return nil, fmt.Errorf("%s version %s unsupported by the gateway", tx.Type, tx.Version)
+	}
+	switch tx.Type {
+	case rpc.TransactionType_Invoke:
+		var invoke rpc.BroadcastedInvokeV1Transaction
+		if err := json.Unmarshal(tx.Transaction, &invoke); err != nil {
+			return nil, err
+		}
+		resp, err := provider.Invoke(ctx, gatewayInvoke(invoke))
+		if err != nil {
+			return nil, err
+		}
+		return &BroadcastOutput{TransactionHash: resp.TransactionHash.String()}, nil
+	case rpc.TransactionType_DeployAccount:
+		var deploy rpc.BroadcastedDeployAccountTransaction
+		if err := json.Unmarshal(tx.Transaction, &deploy); err != nil {
+			return nil, err
+		}
+		resp, err := provider.DeployAccount(ctx, gatewayDeployAccountRequest(deploy))
+		if err != nil {
+			return nil, err
+		}
+		return &BroadcastOutput{
+			TransactionHash: resp.TransactionHash,
+			ContractAddress: resp.ContractAddress,
+		}, nil
+	}
+	return nil, fmt.Errorf("%s unsupported by the gateway", tx.Type)
+}
+
+// gatewayInvoke converts an INVOKE v1 transaction to its gateway request.
+func gatewayInvoke(tx rpc.BroadcastedInvokeV1Transaction) types.FunctionInvoke {
+	calldata := make([]string, len(tx.Calldata))
+	for i, value := range tx.Calldata {
+		calldata[i] = value.String()
+	}
+	return types.FunctionInvoke{
+		MaxFee:        tx.MaxFee.BigInt(big.NewInt(0)),
+		Version:       big.NewInt(1),
+		Signature:     bigSignature(tx.Signature),
+		Nonce:         tx.Nonce.BigInt(big.NewInt(0)),
+		SenderAddress: tx.SenderAddress,
+		Calldata:      calldata,
+	}
+}
@@ -0,0 +1,288 @@
+package starknetgo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/sjxqqq/starknet-go/gateway"
+	"github.com/sjxqqq/starknet-go/rpc"
+	"github.com/sjxqqq/starknet-go/types"
+	"github.com/sjxqqq/starknet-go/utils"
+)
+
+// newTestOfflineAccount returns an offline account signing with the mock
+// account key.
+func newTestOfflineAccount(t *testing.T, nonce int64, options ...AccountOptionFunc) *Account {
+	t.Helper()
+	ks := NewMemKeystore()
+	ks.Put(mockAccountPrivateKey, types.HexToBN(mockAccountPrivateKey))
+	account, err := NewOfflineAccount(
+		utils.TestHexToFelt(t, mockAccountPrivateKey),
+		utils.TestHexToFelt(t, mockAccountAddress),
+		ks,
+		"SN_GOERLI",
+		big.NewInt(nonce),
+		options...,
+	)
+	if err != nil {
+		t.Fatal("should create the account, instead:", err)
+	}
+	return account
+}
+
+func TestOfflineAccount_SignInvoke(t *testing.T) {
+	type testSetType struct {
+		Details       types.ExecuteDetails
+		ExpectedNonce string
+		ExpectedError error
+	}
+	testSet := map[string][]testSetType{
+		"mock": {
+			{Details: types.ExecuteDetails{MaxFee: big.NewInt(1000)}, ExpectedNonce: "0x7"},
+			{Details: types.ExecuteDetails{}, ExpectedError: ErrMissingMaxFee},
+			{Details: types.ExecuteDetails{MaxFee: big.NewInt(1000)}, ExpectedNonce: "0x8"},
+			// an explicit nonce does not move the account nonce backwards
+			{Details: types.ExecuteDetails{MaxFee: big.NewInt(1000), Nonce: big.NewInt(2)}, ExpectedNonce: "0x2"},
+			{Details: types.ExecuteDetails{MaxFee: big.NewInt(1000)}, ExpectedNonce: "0x9"},
+		},
+	}[testEnv]
+
+	call := types.FunctionCall{
+		ContractAddress:    utils.TestHexToFelt(t, mockAccountAddress),
+		EntryPointSelector: types.GetSelectorFromNameFelt("increment"),
+	}
+	offline := newTestOfflineAccount(t, 7, AccountVersion1)
+	online, mock := newMockRPCAccount(t, AccountVersion1)
+	for _, test := range testSet {
+		signed, err := offline.SignInvoke(context.Background(), []types.FunctionCall{call}, test.Details)
+		if test.ExpectedError != nil {
+			if !errors.Is(err, test.ExpectedError) {
+				t.Fatalf("error should be %v, instead: %v", test.ExpectedError, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal("sign should succeed, instead:", err)
+		}
+		content, err := json.Marshal(signed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var envelope SignedTransaction
+		if err := json.Unmarshal(content, &envelope); err != nil {
+			t.Fatal("should unmarshal the envelope, instead:", err)
+		}
+		if envelope.Type != rpc.TransactionType_Invoke || envelope.Version != rpc.TransactionV1 || envelope.ChainID != "SN_GOERLI" {
+			t.Fatalf("unexpected envelope %s", content)
+		}
+
+		output, err := Broadcast(context.Background(), online.rpc, envelope)
+		if err != nil {
+			t.Fatal("broadcast should succeed, instead:", err)
+		}
+		if output.TransactionHash != mockTransactionHash {
+			t.Fatalf("transaction hash should be %s, instead: %s", mockTransactionHash, output.TransactionHash)
+		}
+		var tx rpc.BroadcastedInvokeV1Transaction
+		mock.lastReceived(t, &tx)
+		if tx.Nonce.String() != test.ExpectedNonce || tx.Type != "INVOKE" {
+			t.Fatalf("nonce should be %s, instead: %s", test.ExpectedNonce, tx.Nonce)
+		}
+		hash, err := online.TransactionHash([]types.FunctionCall{call}, types.ExecuteDetails{
+			Nonce:  tx.Nonce.BigInt(big.NewInt(0)),
+			MaxFee: tx.MaxFee.BigInt(big.NewInt(0)),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		verifyMockSignature(t, hash, tx.Signature)
+	}
+}
+
+func TestOfflineAccount_SignDeclareV3(t *testing.T) {
+	content, err := os.ReadFile("./rpc/tests/0x4e70b19333ae94bd958625f7b61ce9eec631653597e68645e13780061b2136c.json")
+	if err != nil {
+		t.Fatal("should read the class, instead:", err)
+	}
+	var class rpc.ContractClass
+	if err := json.Unmarshal(content, &class); err != nil {
+		t.Fatal("should unmarshal the class, instead:", err)
+	}
+	casm := rpc.CasmClass{Bytecode: []*felt.Felt{utils.TestHexToFelt(t, "0x1")}}
+
+	offline := newTestOfflineAccount(t, 4, AccountVersion3)
+	if _, err := offline.SignDeclare(context.Background(), class, casm, types.ExecuteDetails{MaxFee: big.NewInt(1)}); !errors.Is(err, ErrMissingMaxFee) {
+		t.Fatalf("error should be %v, instead: %v", ErrMissingMaxFee, err)
+	}
+	signed, err := offline.SignDeclare(context.Background(), class, casm, types.ExecuteDetails{ResourceBounds: &testResourceBounds})
+	if err != nil {
+		t.Fatal("sign should succeed, instead:", err)
+	}
+	if signed.Type != rpc.TransactionType_Declare || signed.Version != rpc.TransactionV3 {
+		t.Fatalf("unexpected declare %s version %s", signed.Type, signed.Version)
+	}
+
+	online, mock := newMockRPCAccount(t, AccountVersion3)
+	mock.classHash = "0x4e70b19333ae94bd958625f7b61ce9eec631653597e68645e13780061b2136c"
+	output, err := Broadcast(context.Background(), online.rpc, *signed)
+	if err != nil {
+		t.Fatal("broadcast should succeed, instead:", err)
+	}
+	if output.ClassHash != mock.classHash {
+		t.Fatalf("class hash should be %s, instead: %s", mock.classHash, output.ClassHash)
+	}
+	var tx rpc.BroadcastedDeclareTransactionV3
+	mock.lastReceived(t, &tx)
+	if tx.Nonce.String() != "0x4" {
+		t.Fatalf("nonce should be 0x4, instead: %s", tx.Nonce)
+	}
+	hash, err := online.declareV3Hash(utils.TestHexToFelt(t, mock.classHash), tx.CompiledClassHash, types.ExecuteDetails{
+		Nonce:          big.NewInt(4),
+		ResourceBounds: &testResourceBounds,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	verifyMockSignature(t, hash.BigInt(big.NewInt(0)), tx.Signature)
+
+	if _, err := Broadcast(context.Background(), gateway.NewProvider(), *signed); err == nil {
+		t.Fatal("gateway should not broadcast declare v3 transactions")
+	}
+}
+
+func TestOfflineAccount_SignDeployAccount(t *testing.T) {
+	classHash := utils.TestHexToFelt(t, "0x2794ce20e5f2ff0d40e632cb53845b9f4e526ebd8471983f7dbd355b721d5a")
+	salt := utils.TestHexToFelt(t, "0x4b2a5ce2f6c9d2d3ae4f3cd1f0b7b2b6a6bd0b0ae1ec8bb0a3c6b1cfa1a7b2c")
+	calldata := []*felt.Felt{salt}
+	address, err := PrecomputeAccountAddress(salt, classHash, calldata)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	offline := newTestOfflineAccount(t, 0, AccountVersion1)
+	offline.AccountAddress = address
+	signed, err := offline.SignDeployAccount(context.Background(), classHash, salt, calldata, types.ExecuteDetails{MaxFee: big.NewInt(1000)})
+	if err != nil {
+		t.Fatal("sign should succeed, instead:", err)
+	}
+
+	online, mock := newMockRPCAccount(t, AccountVersion1)
+	output, err := Broadcast(context.Background(), online.rpc, *signed)
+	if err != nil {
+		t.Fatal("broadcast should succeed, instead:", err)
+	}
+	if output.ContractAddress != address.String() {
+		t.Fatalf("contract address should be %s, instead: %s", address, output.ContractAddress)
+	}
+	var tx rpc.BroadcastedDeployAccountTransaction
+	mock.lastReceived(t, &tx)
+	online.AccountAddress = address
+	hash, err := online.deployAccountHash(classHash, salt, calldata, big.NewInt(1000), big.NewInt(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	verifyMockSignature(t, hash, tx.Signature)
+
+	if _, err := online.SignDeployAccount(context.Background(), classHash, salt, calldata, types.ExecuteDetails{MaxFee: big.NewInt(1000)}); !errors.Is(err, ErrUnsupportedAccount) {
+		t.Fatalf("online accounts should not sign offline, instead: %v", err)
+	}
+}
+
+// signedNonce returns the nonce of a signed INVOKE v1 transaction.
+func signedNonce(t *testing.T, signed *SignedTransaction) string {
+	t.Helper()
+	var tx rpc.BroadcastedInvokeV1Transaction
+	if err := json.Unmarshal(signed.Transaction, &tx); err != nil {
+		t.Fatal(err)
+	}
+	return tx.Nonce.String()
+}
+
+func TestOfflineAccount_Nonce(t *testing.T) {
+	call := types.FunctionCall{
+		ContractAddress:    utils.TestHexToFelt(t, mockAccountAddress),
+		EntryPointSelector: types.GetSelectorFromNameFelt("increment"),
+	}
+	details := types.ExecuteDetails{MaxFee: big.NewInt(1000)}
+	offline := newTestOfflineAccount(t, 0, AccountVersion1)
+
+	// concurrent signatures never share a nonce
+	var wg sync.WaitGroup
+	nonces := make(chan string, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			signed, err := offline.SignInvoke(context.Background(), []types.FunctionCall{call}, details)
+			if err != nil {
+				t.Error("sign should succeed, instead:", err)
+				return
+			}
+			nonces <- signedNonce(t, signed)
+		}()
+	}
+	wg.Wait()
+	close(nonces)
+	seen := map[string]bool{}
+	for nonce := range nonces {
+		if seen[nonce] {
+			t.Fatalf("nonce %s signed twice", nonce)
+		}
+		seen[nonce] = true
+	}
+	if len(seen) != 10 {
+		t.Fatalf("10 nonces should be signed, instead: %v", seen)
+	}
+
+	// a failed signature puts its nonce back
+	signer := offline.signer
+	offline.signer = multisigSigner{NewMemKeystore()}
+	if _, err := offline.SignInvoke(context.Background(), []types.FunctionCall{call}, details); err == nil {
+		t.Fatal("sign without the key should fail")
+	}
+	offline.signer = signer
+	signed, err := offline.SignInvoke(context.Background(), []types.FunctionCall{call}, details)
+	if err != nil {
+		t.Fatal("sign should succeed, instead:", err)
+	}
+	if nonce := signedNonce(t, signed); nonce != "0xa" {
+		t.Fatalf("nonce should be 0xa, instead: %s", nonce)
+	}
+}
+
+func TestBroadcast_ChainID(t *testing.T) {
+	call := types.FunctionCall{
+		ContractAddress:    utils.TestHexToFelt(t, mockAccountAddress),
+		EntryPointSelector: types.GetSelectorFromNameFelt("increment"),
+	}
+	ks := NewMemKeystore()
+	ks.Put(mockAccountPrivateKey, types.HexToBN(mockAccountPrivateKey))
+	offline, err := NewOfflineAccount(
+		utils.TestHexToFelt(t, mockAccountPrivateKey),
+		utils.TestHexToFelt(t, mockAccountAddress),
+		ks,
+		"SN_MAIN",
+		big.NewInt(0),
+		AccountVersion1,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signed, err := offline.SignInvoke(context.Background(), []types.FunctionCall{call}, types.ExecuteDetails{MaxFee: big.NewInt(1000)})
+	if err != nil {
+		t.Fatal("sign should succeed, instead:", err)
+	}
+	online, mock := newMockRPCAccount(t, AccountVersion1)
+	if _, err := Broadcast(context.Background(), online.rpc, *signed); !errors.Is(err, ErrChainIDMismatch) {
+		t.Fatalf("error should be %v, instead: %v", ErrChainIDMismatch, err)
+	}
+	if len(mock.received) != 0 {
+		t.Fatalf("no transaction should be sent, instead: %d", len(mock.received))
+	}
+}
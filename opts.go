@@ -1,5 +1,11 @@
 package starknetgo
 
+import (
+	"crypto/tls"
+	"net/http"
+	"time"
+)
+
 type curveOptions struct {
 	initConstants bool
 	paramsPath    string
@@ -36,3 +42,67 @@ func WithConstants(paramsPath ...string) CurveOption {
 		}
 	})
 }
+
+type remoteKeystoreOptions struct {
+	httpClient *http.Client
+	tlsConfig  *tls.Config
+	timeout    time.Duration
+	retries    int
+	backoff    time.Duration
+}
+
+// funcRemoteKeystoreOption wraps a function that modifies
+// remoteKeystoreOptions into an implementation of the RemoteKeystoreOption
+// interface.
+type funcRemoteKeystoreOption struct {
+	f func(*remoteKeystoreOptions)
+}
+
+func (fso *funcRemoteKeystoreOption) apply(do *remoteKeystoreOptions) {
+	fso.f(do)
+}
+
+func newFuncRemoteKeystoreOption(f func(*remoteKeystoreOptions)) *funcRemoteKeystoreOption {
+	return &funcRemoteKeystoreOption{
+		f: f,
+	}
+}
+
+// RemoteKeystoreOption configures how a RemoteKeystore reaches its signer.
+type RemoteKeystoreOption interface {
+	apply(*remoteKeystoreOptions)
+}
+
+// WithRemoteHTTPClient sends the signing requests with client. It takes
+// precedence over WithRemoteTLS.
+func WithRemoteHTTPClient(client *http.Client) RemoteKeystoreOption {
+	return newFuncRemoteKeystoreOption(func(o *remoteKeystoreOptions) {
+		o.httpClient = client
+	})
+}
+
+// WithRemoteTLS sets the TLS configuration of the connection to the signer.
+// Set its Certificates for mutual TLS and its RootCAs to trust the signer.
+func WithRemoteTLS(config *tls.Config) RemoteKeystoreOption {
+	return newFuncRemoteKeystoreOption(func(o *remoteKeystoreOptions) {
+		o.tlsConfig = config
+	})
+}
+
+// WithRemoteTimeout bounds the duration of each signing request. The default
+// is 10 seconds.
+func WithRemoteTimeout(timeout time.Duration) RemoteKeystoreOption {
+	return newFuncRemoteKeystoreOption(func(o *remoteKeystoreOptions) {
+		o.timeout = timeout
+	})
+}
+
+// WithRemoteRetries retries a signing request up to retries times when the
+// signer can not be reached, waiting backoff before the first retry and
+// doubling it after every attempt.
+func WithRemoteRetries(retries int, backoff time.Duration) RemoteKeystoreOption {
+	return newFuncRemoteKeystoreOption(func(o *remoteKeystoreOptions) {
+		o.retries = retries
+		o.backoff = backoff
+	})
+}
@@ -0,0 +1,120 @@
+package starknetgo
+
+import (
+	"fmt"
+	"math/big"
+	"sync"
+
+	starkcurve "github.com/consensys/gnark-crypto/ecc/stark-curve"
+	"github.com/consensys/gnark-crypto/ecc/stark-curve/fp"
+)
+
+const (
+	// pedersenBits is the number of constant points of each hashed element.
+	pedersenBits = 252
+	// pedersenWindow is the number of bits of an element added with a single
+	// lookup in the precomputed tables.
+	pedersenWindow  = 4
+	pedersenWindows = pedersenBits / pedersenWindow
+)
+
+// pedersenTable holds, for every window of an element, the sums of the
+// constant points of the bits set in each value of the window.
+type pedersenTable [pedersenWindows][1 << pedersenWindow]starkcurve.G1Affine
+
+var (
+	pedersenTablesOnce sync.Once
+	pedersenShift      starkcurve.G1Affine
+	pedersenTables     []pedersenTable
+)
+
+// initPedersenTables precomputes the tables of the constant points of
+// PedersenParams. They are read-only once computed.
+func initPedersenTables() {
+	points := make([]starkcurve.G1Affine, len(PedersenParams.ConstantPoints))
+	for i, point := range PedersenParams.ConstantPoints {
+		points[i].X.SetBigInt(point[0])
+		points[i].Y.SetBigInt(point[1])
+	}
+	pedersenShift = points[0]
+
+	count := (len(points) - 2) / pedersenBits
+	sums := make([]starkcurve.G1Jac, count*pedersenWindows<<pedersenWindow)
+	for element := 0; element < count; element++ {
+		for window := 0; window < pedersenWindows; window++ {
+			offset := (element*pedersenWindows + window) << pedersenWindow
+			first := 2 + element*pedersenBits + window*pedersenWindow
+			// the sum of value is the sum of value without its lowest bit
+			// plus the constant point of that bit
+			for value := 1; value < 1<<pedersenWindow; value++ {
+				lowest := value & -value
+				bit := 0
+				for 1<<bit != lowest {
+					bit++
+				}
+				sums[offset+value].Set(&sums[offset+value-lowest])
+				sums[offset+value].AddMixed(&points[first+bit])
+			}
+		}
+	}
+	affine := starkcurve.BatchJacobianToAffineG1(sums)
+	pedersenTables = make([]pedersenTable, count)
+	for element := range pedersenTables {
+		for window := 0; window < pedersenWindows; window++ {
+			offset := (element*pedersenWindows + window) << pedersenWindow
+			copy(pedersenTables[element][window][:], affine[offset:offset+1<<pedersenWindow])
+		}
+	}
+}
+
+// usesPedersenTables returns true when the hash of elems can be computed with
+// the precomputed tables, i.e. sc holds the constant points of PedersenParams
+// and the elements are in the field.
+func (sc StarkCurve) usesPedersenTables(elems []*big.Int) bool {
+	constants := PedersenParams.ConstantPoints
+	if len(sc.ConstantPoints) != len(constants) || &sc.ConstantPoints[0] != &constants[0] {
+		return false
+	}
+	if sc.Gx.Cmp(constants[0][0]) != 0 || sc.Gy.Cmp(constants[0][1]) != 0 || sc.P.Cmp(PedersenParams.FieldPrime) != 0 {
+		return false
+	}
+	if len(elems) > (len(constants)-2)/pedersenBits {
+		return false
+	}
+	for _, elem := range elems {
+		if elem.Sign() < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// pedersenHashTables computes the Pedersen hash of elems by adding, for each
+// window of bits of the elements, the precomputed sum of their constant
+// points. The points are added in Jacobian coordinates so a single inversion
+// is needed.
+func (sc StarkCurve) pedersenHashTables(elems []*big.Int) (*big.Int, error) {
+	pedersenTablesOnce.Do(initPedersenTables)
+
+	var acc starkcurve.G1Jac
+	acc.FromAffine(&pedersenShift)
+	var buf [32]byte
+	for i, elem := range elems {
+		if elem.Cmp(sc.P) >= 0 {
+			return nil, fmt.Errorf("invalid x: %v", elem)
+		}
+		elem.FillBytes(buf[:])
+		table := &pedersenTables[i]
+		for window := 0; window < pedersenWindows; window++ {
+			value := buf[len(buf)-1-window/2] >> (pedersenWindow * (window % 2)) & 0xf
+			if value != 0 {
+				acc.AddMixed(&table[window][value])
+			}
+		}
+	}
+
+	// the affine x coordinate is X/Z^2
+	var x fp.Element
+	x.Inverse(&acc.Z).Square(&x).Mul(&acc.X, &x)
+	return x.BigInt(new(big.Int)), nil
+}
@@ -64,7 +64,7 @@ func getMerkleProof(policies []Policy, call ctypes.FunctionCall) ([]string, erro
 	callkey, err := starknetgo.Curve.ComputeHashOnElements([]*big.Int{
 		POLICY_TYPE_HASH,
 		call.ContractAddress.BigInt(big.NewInt(0)),
-		ctypes.GetSelectorFromName(call.EntryPointSelector.String()),
+		call.EntryPointSelector.BigInt(big.NewInt(0)),
 	})
 	if err != nil {
 		return nil, err
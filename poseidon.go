@@ -0,0 +1,174 @@
+package starknetgo
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/big"
+
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/consensys/gnark-crypto/ecc/stark-curve/fp"
+)
+
+//go:embed poseidon_params.json
+var PoseidonParamsRaw []byte
+var PoseidonParams PoseidonPayload
+
+// struct definition for parsing 'poseidon_params.json'
+type PoseidonPayload struct {
+	Comment       string       `json:"_comment"`
+	FieldPrime    *big.Int     `json:"FIELD_PRIME"`
+	Rate          int          `json:"RATE"`
+	Capacity      int          `json:"CAPACITY"`
+	FullRounds    int          `json:"N_ROUNDS_F"`
+	PartialRounds int          `json:"N_ROUNDS_P"`
+	RoundKeys     [][]*big.Int `json:"ROUND_KEYS"`
+}
+
+// poseidonRoundKeys are the round keys of PoseidonParams in the field.
+var poseidonRoundKeys [][3]fp.Element
+
+func init() {
+	if err := json.Unmarshal(PoseidonParamsRaw, &PoseidonParams); err != nil {
+		log.Fatalf("unmarshalling poseidon params: %v", err)
+	}
+	if PoseidonParams.Rate+PoseidonParams.Capacity != 3 || len(PoseidonParams.RoundKeys) != PoseidonParams.FullRounds+PoseidonParams.PartialRounds {
+		panic("decoding poseidon params json")
+	}
+	poseidonRoundKeys = make([][3]fp.Element, len(PoseidonParams.RoundKeys))
+	for i, keys := range PoseidonParams.RoundKeys {
+		for j, key := range keys {
+			poseidonRoundKeys[i][j].SetBigInt(key)
+		}
+	}
+}
+
+/*
+Applies the Hades permutation of the Starknet Poseidon hash to state: full
+rounds applying the x^3 S-box to the whole state surrounding partial rounds
+applying it to the last element only, each followed by the MDS matrix
+((3, 1, 1), (1, -1, 1), (1, 1, -2)).
+
+(ref: https://github.com/starkware-libs/cairo-lang/blob/master/src/starkware/cairo/common/poseidon_utils.py)
+*/
+func hadesPermutation(state *[3]fp.Element) {
+	totalRounds := PoseidonParams.FullRounds + PoseidonParams.PartialRounds
+	var squared, sum fp.Element
+	for round := 0; round < totalRounds; round++ {
+		keys := &poseidonRoundKeys[round]
+		state[0].Add(&state[0], &keys[0])
+		state[1].Add(&state[1], &keys[1])
+		state[2].Add(&state[2], &keys[2])
+
+		full := round < PoseidonParams.FullRounds/2 || round >= totalRounds-PoseidonParams.FullRounds/2
+		if full {
+			state[0].Mul(&state[0], squared.Square(&state[0]))
+			state[1].Mul(&state[1], squared.Square(&state[1]))
+		}
+		state[2].Mul(&state[2], squared.Square(&state[2]))
+
+		sum.Add(&state[0], &state[1]).Add(&sum, &state[2])
+		state[0].Double(&state[0]).Add(&state[0], &sum)
+		state[1].Double(&state[1]).Sub(&sum, &state[1])
+		squared.Double(&state[2]).Add(&squared, &state[2])
+		state[2].Sub(&sum, &squared)
+	}
+}
+
+// PoseidonHash returns the Poseidon hash of x and y, poseidon_hash in
+// cairo-lang.
+func PoseidonHash(x, y *felt.Felt) *felt.Felt {
+	state := [3]fp.Element{*x.Impl(), *y.Impl()}
+	state[2].SetUint64(2)
+	hadesPermutation(&state)
+	return felt.NewFelt(&state[0])
+}
+
+// PoseidonHashMany returns the Poseidon hash of elems, poseidon_hash_many in
+// cairo-lang. It is the hash of the class and transaction hashes of Cairo 1.
+func PoseidonHashMany(elems ...*felt.Felt) *felt.Felt {
+	sponge := NewPoseidonSponge()
+	sponge.Absorb(elems...)
+	return sponge.Sum()
+}
+
+// PoseidonSponge absorbs elements two at a time into the Hades permutation,
+// so they can be hashed as they are produced.
+type PoseidonSponge struct {
+	state   [3]fp.Element
+	pending *fp.Element
+}
+
+// NewPoseidonSponge returns an empty sponge.
+func NewPoseidonSponge() *PoseidonSponge {
+	return &PoseidonSponge{}
+}
+
+// Absorb adds elems to the elements hashed by the sponge.
+func (s *PoseidonSponge) Absorb(elems ...*felt.Felt) {
+	for _, elem := range elems {
+		if s.pending == nil {
+			value := *elem.Impl()
+			s.pending = &value
+			continue
+		}
+		s.state[0].Add(&s.state[0], s.pending)
+		s.state[1].Add(&s.state[1], elem.Impl())
+		hadesPermutation(&s.state)
+		s.pending = nil
+	}
+}
+
+// Sum returns the PoseidonHashMany of the elements absorbed so far. The
+// elements are padded with 1, and 0 if needed, to an even length. The sponge
+// can keep absorbing elements.
+func (s *PoseidonSponge) Sum() *felt.Felt {
+	state := s.state
+	if s.pending != nil {
+		state[0].Add(&state[0], s.pending)
+		state[1].Add(&state[1], new(fp.Element).SetOne())
+	} else {
+		state[0].Add(&state[0], new(fp.Element).SetOne())
+	}
+	hadesPermutation(&state)
+	return felt.NewFelt(&state[0])
+}
+
+// Reset empties the sponge.
+func (s *PoseidonSponge) Reset() {
+	*s = PoseidonSponge{}
+}
+
+// PoseidonHash returns the Poseidon hash of x and y, which must be in the
+// field like the elements of PedersenHash.
+func (sc StarkCurve) PoseidonHash(x, y *big.Int) (*big.Int, error) {
+	elems, err := poseidonFelts([]*big.Int{x, y})
+	if err != nil {
+		return nil, err
+	}
+	return PoseidonHash(elems[0], elems[1]).BigInt(new(big.Int)), nil
+}
+
+// PoseidonHashMany returns the Poseidon hash of elems, which must be in the
+// field.
+func (sc StarkCurve) PoseidonHashMany(elems []*big.Int) (*big.Int, error) {
+	felts, err := poseidonFelts(elems)
+	if err != nil {
+		return nil, err
+	}
+	return PoseidonHashMany(felts...).BigInt(new(big.Int)), nil
+}
+
+func poseidonFelts(elems []*big.Int) ([]*felt.Felt, error) {
+	felts := make([]*felt.Felt, len(elems))
+	for i, elem := range elems {
+		if elem.Sign() < 0 || elem.Cmp(PoseidonParams.FieldPrime) >= 0 {
+			return nil, fmt.Errorf("invalid x: %v", elem)
+		}
+		var value fp.Element
+		value.SetBigInt(elem)
+		felts[i] = felt.NewFelt(&value)
+	}
+	return felts, nil
+}
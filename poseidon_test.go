@@ -0,0 +1,89 @@
+package starknetgo
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/sjxqqq/starknet-go/types"
+)
+
+// TestPoseidonHashMany checks the vectors of poseidon_hash and
+// poseidon_hash_many in cairo-lang.
+func TestPoseidonHashMany(t *testing.T) {
+	type testSetType struct {
+		Elems    []*felt.Felt
+		Expected string
+	}
+	felts := func(values ...uint64) []*felt.Felt {
+		elems := []*felt.Felt{}
+		for _, value := range values {
+			elems = append(elems, new(felt.Felt).SetUint64(value))
+		}
+		return elems
+	}
+	testSet := map[string][]testSetType{
+		"mock": {
+			{Elems: felts(), Expected: "0x2272be0f580fd156823304800919530eaa97430e972d7213ee13f4fbf7a5dbc"},
+			{Elems: felts(0, 1, 2), Expected: "0x7a01142da8aecae3782ba66fc3285fd02fcd2c55aa868fe50fd95c089068d16"},
+			{Elems: felts(0, 1, 2, 3), Expected: "0x7b8f30ac298ea12d170c0873f1fa631a18c00756c6e7d1fd273b9a239d0d413"},
+		},
+	}[testEnv]
+
+	for _, test := range testSet {
+		if hash := PoseidonHashMany(test.Elems...); hash.String() != test.Expected {
+			t.Fatalf("hash of %v should be %s, instead: %s", test.Elems, test.Expected, hash)
+		}
+		// absorbing the elements one by one gives the same hash
+		sponge := NewPoseidonSponge()
+		for _, elem := range test.Elems {
+			sponge.Absorb(elem)
+		}
+		if hash := sponge.Sum(); hash.String() != test.Expected {
+			t.Fatalf("sponge hash of %v should be %s, instead: %s", test.Elems, test.Expected, hash)
+		}
+		// Sum does not change the sponge
+		sponge.Absorb(new(felt.Felt).SetUint64(42))
+		if hash := sponge.Sum(); !hash.Equal(PoseidonHashMany(append(test.Elems, new(felt.Felt).SetUint64(42))...)) {
+			t.Fatalf("sponge should keep absorbing after Sum")
+		}
+		sponge.Reset()
+		if hash := sponge.Sum(); hash.String() != "0x2272be0f580fd156823304800919530eaa97430e972d7213ee13f4fbf7a5dbc" {
+			t.Fatalf("reset sponge should be empty, instead: %s", hash)
+		}
+	}
+}
+
+func TestPoseidonHash(t *testing.T) {
+	expected := "0x5d44a3decb2b2e0cc71071f7b802f45dd792d064f0fc7316c46514f70f9891a"
+	if hash := PoseidonHash(new(felt.Felt).SetUint64(1), new(felt.Felt).SetUint64(2)); hash.String() != expected {
+		t.Fatalf("hash should be %s, instead: %s", expected, hash)
+	}
+	hash, err := Curve.PoseidonHash(big.NewInt(1), big.NewInt(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if types.BigToHex(hash) != expected {
+		t.Fatalf("hash should be %s, instead: %x", expected, hash)
+	}
+	if _, err := Curve.PoseidonHash(Curve.P, big.NewInt(1)); err == nil {
+		t.Fatal("elements out of the field should fail")
+	}
+	hash, err = Curve.PoseidonHashMany([]*big.Int{big.NewInt(0), big.NewInt(1), big.NewInt(2)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if types.BigToHex(hash) != "0x7a01142da8aecae3782ba66fc3285fd02fcd2c55aa868fe50fd95c089068d16" {
+		t.Fatalf("unexpected hash %x", hash)
+	}
+}
+
+func BenchmarkPoseidonHashMany(b *testing.B) {
+	elems := make([]*felt.Felt, 100)
+	for i := range elems {
+		elems[i] = new(felt.Felt).SetUint64(uint64(i))
+	}
+	for i := 0; i < b.N; i++ {
+		PoseidonHashMany(elems...)
+	}
+}
@@ -29,12 +29,26 @@ func Err(code int, data any) *RPCError {
 	}
 }
 
+// errorCoder is implemented by the errors the go-ethereum client returns when
+// the node answers with a JSON-RPC error object.
+type errorCoder interface {
+	ErrorCode() int
+}
+
 func tryUnwrapToRPCErr(err error, rpcErrors ...*RPCError) error {
 	for _, rpcErr := range rpcErrors {
 		if errors.Is(err, rpcErr) {
 			return rpcErr
 		}
 	}
+	var coder errorCoder
+	if errors.As(err, &coder) {
+		for _, rpcErr := range rpcErrors {
+			if coder.ErrorCode() == rpcErr.code {
+				return rpcErr
+			}
+		}
+	}
 
 	return Err(InternalError, err.Error())
 }
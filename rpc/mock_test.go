@@ -74,6 +74,10 @@ func (r *rpcMock) CallContext(ctx context.Context, result interface{}, method st
 		return mock_starknet_traceBlockTransactions(result, method, args...)
 	case "starknet_traceTransaction":
 		return mock_starknet_traceTransaction(result, method, args...)
+	case "starknet_getStorageProof":
+		return mock_starknet_getStorageProof(result, method, args...)
+	case "pathfinder_getProof":
+		return mock_pathfinder_getProof(result, method, args...)
 	default:
 		return errNotFound
 	}
@@ -726,3 +730,61 @@ func mock_starknet_traceTransaction(result interface{}, method string, args ...i
 		return ErrInvalidTxnHash
 	}
 }
+
+func mock_starknet_getStorageProof(result interface{}, method string, args ...interface{}) error {
+	r, ok := result.(*json.RawMessage)
+	if !ok {
+		return errWrongType
+	}
+	if len(args) != 4 {
+		return errWrongArgs
+	}
+	if _, ok := args[0].(BlockID); !ok {
+		return errWrongArgs
+	}
+	if _, ok := args[3].([]ContractStorageKeys); !ok {
+		return errWrongArgs
+	}
+	output := `{
+		"classes_proof": [],
+		"contracts_proof": {
+			"nodes": [
+				{"node_hash": "0x10", "node": {"left": "0x1", "right": "0x2"}},
+				{"node_hash": "0x1", "node": {"path": "0x3", "length": 250, "child": "0x4"}}
+			],
+			"contract_leaves_data": [{"nonce": "0x0", "class_hash": "0x5", "storage_root": "0x6"}]
+		},
+		"contracts_storage_proofs": [[]],
+		"global_roots": {"contracts_tree_root": "0x10", "classes_tree_root": "0x0", "block_hash": "0xbeef"}
+	}`
+	return json.Unmarshal([]byte(output), r)
+}
+
+func mock_pathfinder_getProof(result interface{}, method string, args ...interface{}) error {
+	r, ok := result.(*json.RawMessage)
+	if !ok {
+		return errWrongType
+	}
+	if len(args) != 3 {
+		return errWrongArgs
+	}
+	if _, ok := args[1].(*felt.Felt); !ok {
+		return errWrongArgs
+	}
+	output := `{
+		"state_commitment": "0x10",
+		"class_commitment": "0x0",
+		"contract_proof": [
+			{"binary": {"left": "0x1", "right": "0x2"}},
+			{"edge": {"child": "0x4", "path": {"value": "0x3", "len": 250}}}
+		],
+		"contract_data": {
+			"class_hash": "0x5",
+			"nonce": "0x0",
+			"root": "0x6",
+			"contract_state_hash_version": "0x0",
+			"storage_proofs": [[{"edge": {"child": "0xdeadbeef", "path": {"value": "0x7", "len": 251}}}]]
+		}
+	}`
+	return json.Unmarshal([]byte(output), r)
+}
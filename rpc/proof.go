@@ -0,0 +1,47 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+
+	"github.com/NethermindEth/juno/core/felt"
+)
+
+// StorageProof gets the Merkle proofs of the classes, contracts and storage
+// keys at the given block, to be verified against its global state root.
+func (provider *Provider) StorageProof(ctx context.Context, blockID BlockID, classHashes, contractAddresses []*felt.Felt, contractsStorageKeys []ContractStorageKeys) (*StorageProof, error) {
+	if classHashes == nil {
+		classHashes = []*felt.Felt{}
+	}
+	if contractAddresses == nil {
+		contractAddresses = []*felt.Felt{}
+	}
+	if contractsStorageKeys == nil {
+		contractsStorageKeys = []ContractStorageKeys{}
+	}
+	var proof StorageProof
+	if err := do(ctx, provider.c, "starknet_getStorageProof", &proof, blockID, classHashes, contractAddresses, contractsStorageKeys); err != nil {
+		if errors.Is(err, ErrBlockNotFound) {
+			return nil, ErrBlockNotFound
+		}
+		return nil, err
+	}
+	return &proof, nil
+}
+
+// PathfinderProof gets the Merkle proofs of a contract and of its storage
+// keys at the given block with the pathfinder_getProof method of pathfinder
+// nodes.
+func (provider *Provider) PathfinderProof(ctx context.Context, blockID BlockID, contractAddress *felt.Felt, keys []*felt.Felt) (*PathfinderProof, error) {
+	if keys == nil {
+		keys = []*felt.Felt{}
+	}
+	var proof PathfinderProof
+	if err := do(ctx, provider.c, "pathfinder_getProof", &proof, blockID, contractAddress, keys); err != nil {
+		if errors.Is(err, ErrBlockNotFound) {
+			return nil, ErrBlockNotFound
+		}
+		return nil, err
+	}
+	return &proof, nil
+}
@@ -0,0 +1,81 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/sjxqqq/starknet-go/utils"
+	"github.com/test-go/testify/require"
+)
+
+// TestStorageProof tests the nodes of starknet_getStorageProof are decoded.
+func TestStorageProof(t *testing.T) {
+	testConfig := beforeEach(t)
+
+	type testSetType struct {
+		ContractAddress *felt.Felt
+		ExpectedRoot    *felt.Felt
+	}
+	testSet := map[string][]testSetType{
+		"mock": {
+			{
+				ContractAddress: utils.TestHexToFelt(t, "0xdeadbeef"),
+				ExpectedRoot:    utils.TestHexToFelt(t, "0x10"),
+			},
+		},
+	}[testEnv]
+
+	for _, test := range testSet {
+		proof, err := testConfig.provider.StorageProof(context.Background(), WithBlockTag("latest"), nil, []*felt.Felt{test.ContractAddress}, []ContractStorageKeys{{ContractAddress: test.ContractAddress, StorageKeys: []*felt.Felt{}}})
+		require.NoError(t, err)
+		require.Equal(t, test.ExpectedRoot, proof.GlobalRoots.ContractsTreeRoot)
+		require.Len(t, proof.ContractsProof.Nodes, 2)
+		require.NotNil(t, proof.ContractsProof.Nodes[0].Node.Binary)
+		edge := proof.ContractsProof.Nodes[1].Node.Edge
+		require.NotNil(t, edge)
+		require.Equal(t, uint64(250), edge.Length)
+		require.Len(t, proof.ContractsProof.ContractLeavesData, 1)
+	}
+}
+
+// TestPathfinderProof tests the nodes of pathfinder_getProof are decoded.
+func TestPathfinderProof(t *testing.T) {
+	testConfig := beforeEach(t)
+
+	type testSetType struct {
+		ContractAddress *felt.Felt
+		Key             *felt.Felt
+		ExpectedRoot    *felt.Felt
+	}
+	testSet := map[string][]testSetType{
+		"mock": {
+			{
+				ContractAddress: utils.TestHexToFelt(t, "0xdeadbeef"),
+				Key:             utils.TestHexToFelt(t, "0x7"),
+				ExpectedRoot:    utils.TestHexToFelt(t, "0x10"),
+			},
+		},
+	}[testEnv]
+
+	for _, test := range testSet {
+		proof, err := testConfig.provider.PathfinderProof(context.Background(), WithBlockTag("latest"), test.ContractAddress, []*felt.Felt{test.Key})
+		require.NoError(t, err)
+		require.Equal(t, test.ExpectedRoot, proof.StateCommitment)
+		require.Len(t, proof.ContractProof, 2)
+		require.NotNil(t, proof.ContractProof[0].Binary)
+		require.NotNil(t, proof.ContractProof[1].Edge)
+		require.Equal(t, uint64(250), proof.ContractProof[1].Edge.Length)
+		require.NotNil(t, proof.ContractData)
+		require.Len(t, proof.ContractData.StorageProofs, 1)
+		require.Equal(t, uint64(251), proof.ContractData.StorageProofs[0][0].Edge.Length)
+
+		// the nodes are encoded in the format of starknet_getStorageProof
+		encoded, err := json.Marshal(proof.ContractProof[1])
+		require.NoError(t, err)
+		var node MerkleNode
+		require.NoError(t, json.Unmarshal(encoded, &node))
+		require.Equal(t, proof.ContractProof[1], node)
+	}
+}
@@ -17,6 +17,11 @@ func (provider *Provider) TransactionTrace(ctx context.Context, transactionHash
 		return nil, tryUnwrapToRPCErr(err, ErrInvalidTxnHash)
 	}
 
+	return unmarshalTxnTrace(rawTxnTrace)
+}
+
+// unmarshalTxnTrace returns the trace with the type matching the transaction.
+func unmarshalTxnTrace(rawTxnTrace map[string]any) (TxnTrace, error) {
 	rawTraceByte, err := json.Marshal(rawTxnTrace)
 	if err != nil {
 		return nil, err
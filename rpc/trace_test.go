@@ -167,3 +167,33 @@ func TestTraceBlockTransactions(t *testing.T) {
 
 	}
 }
+
+// TestSimulatedTransaction_UnmarshalJSON tests the simulated traces are typed
+func TestSimulatedTransaction_UnmarshalJSON(t *testing.T) {
+	var output SimulateTransactionOutput
+	content, err := os.ReadFile("./tests/simulateInvokeTxResp.json")
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(content, &output))
+	require.NotEmpty(t, output.Txns)
+	trace, ok := output.Txns[0].TxnTrace.(InvokeTxnTrace)
+	require.True(t, ok, "trace should be an InvokeTxnTrace, instead: %T", output.Txns[0].TxnTrace)
+	require.False(t, trace.ExecuteInvocation.IsReverted())
+	require.NotNil(t, trace.ExecuteInvocation.ContractAddress)
+
+	var reverted SimulatedTransaction
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"transaction_trace": {
+			"validate_invocation": {},
+			"execute_invocation": {"revert_reason": "Error in the called contract"},
+			"fee_transfer_invocation": {}
+		},
+		"gas_consumed": "0x1",
+		"gas_price": "0x2",
+		"overall_fee": "0x2"
+	}`), &reverted))
+	trace, ok = reverted.TxnTrace.(InvokeTxnTrace)
+	require.True(t, ok, "trace should be an InvokeTxnTrace, instead: %T", reverted.TxnTrace)
+	require.True(t, trace.ExecuteInvocation.IsReverted())
+	require.Equal(t, "Error in the called contract", trace.ExecuteInvocation.RevertReason)
+	require.Equal(t, NumAsHex("0x2"), reverted.OverallFee)
+}
@@ -112,4 +112,13 @@ type BlockHeader struct {
 	Timestamp uint64 `json:"timestamp"`
 	// SequencerAddress the StarkNet identity of the sequencer submitting this block
 	SequencerAddress *felt.Felt `json:"sequencer_address"`
+	// L1GasPrice the price of L1 gas in the block, only returned from RPC v0.5
+	L1GasPrice *ResourcePrice `json:"l1_gas_price,omitempty"`
+}
+
+type ResourcePrice struct {
+	// PriceInFRI the price of one unit of the given resource, denominated in fri (10^-18 strk)
+	PriceInFRI *felt.Felt `json:"price_in_fri,omitempty"`
+	// PriceInWei the price of one unit of the given resource, denominated in wei
+	PriceInWei *felt.Felt `json:"price_in_wei"`
 }
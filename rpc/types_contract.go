@@ -50,7 +50,35 @@ type ContractClass struct {
 
 	EntryPointsByType EntryPointsByType `json:"entry_points_by_type"`
 
-	ABI *ABI `json:"abi,omitempty"`
+	// ABI is the class ABI as a string. Starknet hashes the exact string, so
+	// it is kept verbatim rather than decoded into ABIEntry values.
+	ABI string `json:"abi,omitempty"`
+}
+
+func (c *ContractClass) UnmarshalJSON(content []byte) error {
+	type contractClass ContractClass
+	v := struct {
+		contractClass
+		ABI json.RawMessage `json:"abi,omitempty"`
+	}{}
+	if err := json.Unmarshal(content, &v); err != nil {
+		return err
+	}
+	*c = ContractClass(v.contractClass)
+
+	// process 'abi'. If it is a string, keep it, otherwise format it the way
+	// the Starknet tooling serializes it.
+	if len(v.ABI) == 0 || string(v.ABI) == "null" {
+		return nil
+	}
+	if err := json.Unmarshal(v.ABI, &c.ABI); err != nil {
+		abi, err := formatABI(v.ABI)
+		if err != nil {
+			return err
+		}
+		c.ABI = abi
+	}
+	return nil
 }
 
 func (c *DeprecatedContractClass) UnmarshalJSON(content []byte) error {
@@ -227,6 +255,82 @@ type TypedParameter struct {
 	Type string `json:"type"`
 }
 
+// CasmEntryPoint is an entry point of a compiled (CASM) class.
+type CasmEntryPoint struct {
+	// A unique identifier of the entry point (function) in the program
+	Selector *felt.Felt `json:"selector"`
+	// The offset of the entry point in the bytecode
+	Offset uint64 `json:"offset"`
+	// The builtins used by the entry point
+	Builtins []string `json:"builtins"`
+}
+
+type CasmEntryPointsByType struct {
+	Constructor []CasmEntryPoint `json:"CONSTRUCTOR"`
+	External    []CasmEntryPoint `json:"EXTERNAL"`
+	L1Handler   []CasmEntryPoint `json:"L1_HANDLER"`
+}
+
+// SegmentLengths describes how the bytecode of a CasmClass is split. It is
+// either a single segment Length or a list of nested Segments.
+type SegmentLengths struct {
+	Length   uint64
+	Segments []SegmentLengths
+}
+
+func (s SegmentLengths) MarshalJSON() ([]byte, error) {
+	if s.Segments != nil {
+		return json.Marshal(s.Segments)
+	}
+	return json.Marshal(s.Length)
+}
+
+func (s *SegmentLengths) UnmarshalJSON(content []byte) error {
+	if len(content) > 0 && content[0] == '[' {
+		s.Segments = []SegmentLengths{}
+		return json.Unmarshal(content, &s.Segments)
+	}
+	return json.Unmarshal(content, &s.Length)
+}
+
+// CasmClass is the compiled form of a ContractClass, as produced by the
+// Sierra to CASM compiler. It is required to compute the compiled class hash
+// of a DECLARE v2 transaction.
+type CasmClass struct {
+	Prime                  NumAsHex              `json:"prime"`
+	CompilerVersion        string                `json:"compiler_version"`
+	Bytecode               []*felt.Felt          `json:"bytecode"`
+	BytecodeSegmentLengths *SegmentLengths       `json:"bytecode_segment_lengths,omitempty"`
+	Hints                  json.RawMessage       `json:"hints,omitempty"`
+	PythonicHints          json.RawMessage       `json:"pythonic_hints,omitempty"`
+	EntryPointsByType      CasmEntryPointsByType `json:"entry_points_by_type"`
+}
+
+// formatABI serializes a JSON ABI the way Python's json.dumps does, with a
+// space after every ',' and ':' outside of strings.
+func formatABI(content []byte) (string, error) {
+	buf := bytes.NewBuffer(nil)
+	if err := json.Compact(buf, content); err != nil {
+		return "", err
+	}
+	out := make([]byte, 0, buf.Len()*5/4)
+	inString, escaped := false, false
+	for _, b := range buf.Bytes() {
+		out = append(out, b)
+		switch {
+		case escaped:
+			escaped = false
+		case inString && b == '\\':
+			escaped = true
+		case b == '"':
+			inString = !inString
+		case !inString && (b == ',' || b == ':'):
+			out = append(out, ' ')
+		}
+	}
+	return string(out), nil
+}
+
 // encodeProgram compress a program to send it to the API
 func encodeProgram(content []byte) (string, error) {
 	buf := bytes.NewBuffer(nil)
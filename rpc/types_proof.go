@@ -0,0 +1,136 @@
+package rpc
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/NethermindEth/juno/core/felt"
+)
+
+// BinaryNode is a node of a Merkle-Patricia trie with two children.
+type BinaryNode struct {
+	Left  *felt.Felt `json:"left"`
+	Right *felt.Felt `json:"right"`
+}
+
+// EdgeNode is a node of a Merkle-Patricia trie skipping the Length bits of
+// Path to its child.
+type EdgeNode struct {
+	Path   *felt.Felt `json:"path"`
+	Length uint64     `json:"length"`
+	Child  *felt.Felt `json:"child"`
+}
+
+// MerkleNode is a node of a Starknet Merkle-Patricia trie proof, either a
+// binary or an edge node. It decodes the nodes of starknet_getStorageProof
+// and of pathfinder_getProof.
+type MerkleNode struct {
+	Binary *BinaryNode
+	Edge   *EdgeNode
+}
+
+func (n MerkleNode) MarshalJSON() ([]byte, error) {
+	switch {
+	case n.Binary != nil:
+		return json.Marshal(n.Binary)
+	case n.Edge != nil:
+		return json.Marshal(n.Edge)
+	}
+	return nil, errors.New("empty merkle node")
+}
+
+func (n *MerkleNode) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		// starknet_getStorageProof nodes
+		Left   *felt.Felt `json:"left"`
+		Right  *felt.Felt `json:"right"`
+		Path   *felt.Felt `json:"path"`
+		Length *uint64    `json:"length"`
+		Child  *felt.Felt `json:"child"`
+		// pathfinder_getProof nodes
+		Binary *BinaryNode `json:"binary"`
+		Edge   *struct {
+			Child *felt.Felt `json:"child"`
+			Path  struct {
+				Value *felt.Felt `json:"value"`
+				Len   uint64     `json:"len"`
+			} `json:"path"`
+		} `json:"edge"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	switch {
+	case raw.Left != nil && raw.Right != nil:
+		*n = MerkleNode{Binary: &BinaryNode{Left: raw.Left, Right: raw.Right}}
+	case raw.Path != nil && raw.Length != nil && raw.Child != nil:
+		*n = MerkleNode{Edge: &EdgeNode{Path: raw.Path, Length: *raw.Length, Child: raw.Child}}
+	case raw.Binary != nil && raw.Binary.Left != nil && raw.Binary.Right != nil:
+		*n = MerkleNode{Binary: raw.Binary}
+	case raw.Edge != nil && raw.Edge.Child != nil && raw.Edge.Path.Value != nil:
+		*n = MerkleNode{Edge: &EdgeNode{Path: raw.Edge.Path.Value, Length: raw.Edge.Path.Len, Child: raw.Edge.Child}}
+	default:
+		return errors.New("unknown merkle node")
+	}
+	return nil
+}
+
+// NodeHashToNode is a node of a starknet_getStorageProof proof with its hash.
+type NodeHashToNode struct {
+	NodeHash *felt.Felt `json:"node_hash"`
+	Node     MerkleNode `json:"node"`
+}
+
+// ContractLeafData is the state of a contract of a storage proof, hashed in
+// the leaf of the contract in the contracts trie.
+type ContractLeafData struct {
+	Nonce       *felt.Felt `json:"nonce"`
+	ClassHash   *felt.Felt `json:"class_hash"`
+	StorageRoot *felt.Felt `json:"storage_root,omitempty"`
+}
+
+// ContractsProof is the proof of the contracts of a storage proof.
+type ContractsProof struct {
+	Nodes              []NodeHashToNode   `json:"nodes"`
+	ContractLeavesData []ContractLeafData `json:"contract_leaves_data"`
+}
+
+// GlobalRoots are the roots of the tries of a block.
+type GlobalRoots struct {
+	ContractsTreeRoot *felt.Felt `json:"contracts_tree_root"`
+	ClassesTreeRoot   *felt.Felt `json:"classes_tree_root"`
+	BlockHash         *felt.Felt `json:"block_hash"`
+}
+
+// StorageProof is the response of starknet_getStorageProof.
+type StorageProof struct {
+	ClassesProof           []NodeHashToNode   `json:"classes_proof"`
+	ContractsProof         ContractsProof     `json:"contracts_proof"`
+	ContractsStorageProofs [][]NodeHashToNode `json:"contracts_storage_proofs"`
+	GlobalRoots            GlobalRoots        `json:"global_roots"`
+}
+
+// ContractStorageKeys are the storage keys of a contract to prove.
+type ContractStorageKeys struct {
+	ContractAddress *felt.Felt   `json:"contract_address"`
+	StorageKeys     []*felt.Felt `json:"storage_keys"`
+}
+
+// PathfinderContractData is the state of the contract of a
+// pathfinder_getProof proof.
+type PathfinderContractData struct {
+	ClassHash                *felt.Felt     `json:"class_hash"`
+	Nonce                    *felt.Felt     `json:"nonce"`
+	Root                     *felt.Felt     `json:"root"`
+	ContractStateHashVersion *felt.Felt     `json:"contract_state_hash_version"`
+	StorageProofs            [][]MerkleNode `json:"storage_proofs"`
+}
+
+// PathfinderProof is the response of pathfinder_getProof. The nodes of the
+// proofs are ordered from the root.
+type PathfinderProof struct {
+	StateCommitment *felt.Felt              `json:"state_commitment"`
+	ClassCommitment *felt.Felt              `json:"class_commitment"`
+	ContractProof   []MerkleNode            `json:"contract_proof"`
+	ContractData    *PathfinderContractData `json:"contract_data"`
+}
@@ -1,6 +1,10 @@
 package rpc
 
-import "github.com/NethermindEth/juno/core/felt"
+import (
+	"encoding/json"
+
+	"github.com/NethermindEth/juno/core/felt"
+)
 
 type SimulateTransactionInput struct {
 	//a sequence of transactions to simulate, running each transaction on the state resulting from applying all the previous ones
@@ -14,6 +18,7 @@ type SimulationFlag string
 const (
 	SKIP_FEE_CHARGE SimulationFlag = "SKIP_FEE_CHARGE"
 	SKIP_EXECUTE    SimulationFlag = "SKIP_EXECUTE"
+	SKIP_VALIDATE   SimulationFlag = "SKIP_VALIDATE"
 )
 
 // The execution trace and consumed resources of the required transactions
@@ -26,6 +31,27 @@ type SimulatedTransaction struct {
 	FeeEstimate
 }
 
+// UnmarshalJSON decodes the trace into the type matching the transaction,
+// e.g. InvokeTxnTrace for an invoke transaction.
+func (txn *SimulatedTransaction) UnmarshalJSON(data []byte) error {
+	var dec struct {
+		TxnTrace map[string]any `json:"transaction_trace"`
+		FeeEstimate
+	}
+	if err := json.Unmarshal(data, &dec); err != nil {
+		return err
+	}
+	trace, err := unmarshalTxnTrace(dec.TxnTrace)
+	if err != nil {
+		return err
+	}
+	*txn = SimulatedTransaction{
+		TxnTrace:    trace,
+		FeeEstimate: dec.FeeEstimate,
+	}
+	return nil
+}
+
 type TxnTrace interface{}
 
 var _ TxnTrace = InvokeTxnTrace{}
@@ -37,8 +63,21 @@ var _ TxnTrace = L1HandlerTxnTrace{}
 type InvokeTxnTrace struct {
 	ValidateInvocation FnInvocation `json:"validate_invocation"`
 	//the trace of the __execute__ call or constructor call, depending on the transaction type (none for declare transactions)
-	ExecuteInvocation     FnInvocation `json:"execute_invocation"`
-	FeeTransferInvocation FnInvocation `json:"fee_transfer_invocation"`
+	ExecuteInvocation     ExecuteInvocation `json:"execute_invocation"`
+	FeeTransferInvocation FnInvocation      `json:"fee_transfer_invocation"`
+}
+
+// ExecuteInvocation is the trace of the __execute__ call of an invoke
+// transaction. RevertReason is set instead when the execution reverted.
+type ExecuteInvocation struct {
+	FnInvocation
+	// RevertReason the revert reason for the failed execution
+	RevertReason string `json:"revert_reason,omitempty"`
+}
+
+// IsReverted returns true when the execution reverted.
+func (invocation ExecuteInvocation) IsReverted() bool {
+	return invocation.RevertReason != ""
 }
 
 // the execution trace of a declare transaction
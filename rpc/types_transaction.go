@@ -77,6 +77,21 @@ func (tx InvokeTxnV1) Hash() *felt.Felt {
 	return tx.TransactionHash
 }
 
+// InvokeTxnV3 is a version 3 invoke transaction, paying the fee within
+// resource bounds rather than a max fee.
+type InvokeTxnV3 struct {
+	TransactionHash *felt.Felt `json:"transaction_hash,omitempty"`
+	BroadcastedTxnV3CommonProperties
+	SenderAddress *felt.Felt `json:"sender_address"`
+	// Calldata The parameters passed to the function
+	Calldata              []*felt.Felt `json:"calldata"`
+	AccountDeploymentData []*felt.Felt `json:"account_deployment_data"`
+}
+
+func (tx InvokeTxnV3) Hash() *felt.Felt {
+	return tx.TransactionHash
+}
+
 type InvokeTxn interface{}
 
 type L1HandlerTxn struct {
@@ -123,8 +138,20 @@ type DeclareTxnV2 struct {
 
 	CompiledClassHash *felt.Felt `json:"compiled_class_hash"`
 
-	ContractClass `json:"contract_class,omitempty"`
-	ClassHash     *felt.Felt `json:"class_hash,omitempty"`
+	ContractClass ContractClass `json:"contract_class,omitempty"`
+	ClassHash     *felt.Felt    `json:"class_hash,omitempty"`
+}
+
+type DeclareTxnV3 struct {
+	TransactionHash *felt.Felt `json:"transaction_hash,omitempty"`
+	BroadcastedTxnV3CommonProperties
+
+	// SenderAddress the address of the account contract sending the declaration transaction
+	SenderAddress *felt.Felt `json:"sender_address"`
+
+	CompiledClassHash     *felt.Felt   `json:"compiled_class_hash"`
+	ClassHash             *felt.Felt   `json:"class_hash,omitempty"`
+	AccountDeploymentData []*felt.Felt `json:"account_deployment_data"`
 }
 
 func (tx DeclareTxnV0) Hash() *felt.Felt {
@@ -137,6 +164,9 @@ func (tx DeclareTxnV1) Hash() *felt.Felt {
 func (tx DeclareTxnV2) Hash() *felt.Felt {
 	return tx.TransactionHash
 }
+func (tx DeclareTxnV3) Hash() *felt.Felt {
+	return tx.TransactionHash
+}
 
 type Transaction interface {
 	Hash() *felt.Felt
@@ -176,6 +206,17 @@ func (tx DeployAccountTxn) Hash() *felt.Felt {
 	return tx.TransactionHash
 }
 
+// DeployAccountTxnV3 The structure of a version 3 deployAccount transaction.
+type DeployAccountTxnV3 struct {
+	TransactionHash *felt.Felt `json:"transaction_hash,omitempty"`
+	BroadcastedTxnV3CommonProperties
+	DeployAccountTransactionProperties
+}
+
+func (tx DeployAccountTxnV3) Hash() *felt.Felt {
+	return tx.TransactionHash
+}
+
 type Transactions []Transaction
 
 func (txns *Transactions) UnmarshalJSON(data []byte) error {
@@ -240,6 +281,10 @@ func unmarshalTxn(t interface{}) (Transaction, error) {
 				var txn DeclareTxnV2
 				remarshal(casted, &txn)
 				return txn, nil
+			case "0x3":
+				var txn DeclareTxnV3
+				remarshal(casted, &txn)
+				return txn, nil
 			default:
 				return nil, errors.New("Internal error with Declare transaction version and unmarshalTxn()")
 			}
@@ -248,15 +293,25 @@ func unmarshalTxn(t interface{}) (Transaction, error) {
 			remarshal(casted, &txn)
 			return txn, nil
 		case TransactionType_DeployAccount:
+			if casted["version"] == "0x3" {
+				var txn DeployAccountTxnV3
+				remarshal(casted, &txn)
+				return txn, nil
+			}
 			var txn DeployAccountTxn
 			remarshal(casted, &txn)
 			return txn, nil
 		case TransactionType_Invoke:
-			if casted["version"].(string) == "0x0" {
+			switch casted["version"].(string) {
+			case "0x0":
 				var txn InvokeTxnV0
 				remarshal(casted, &txn)
 				return txn, nil
-			} else {
+			case "0x3":
+				var txn InvokeTxnV3
+				remarshal(casted, &txn)
+				return txn, nil
+			default:
 				var txn InvokeTxnV1
 				remarshal(casted, &txn)
 				return txn, nil
@@ -291,17 +346,57 @@ const (
 	TransactionV0 TransactionVersion = "0x0"
 	TransactionV1 TransactionVersion = "0x1"
 	TransactionV2 TransactionVersion = "0x2"
+	TransactionV3 TransactionVersion = "0x3"
+
+	// The query versions are the versions with the query bit 2**128 set. They
+	// are only accepted to estimate the fee or simulate a transaction, so a
+	// signed query can not be sent as a real transaction.
+	TransactionV0WithQueryBit TransactionVersion = "0x100000000000000000000000000000000"
+	TransactionV1WithQueryBit TransactionVersion = "0x100000000000000000000000000000001"
+	TransactionV2WithQueryBit TransactionVersion = "0x100000000000000000000000000000002"
+	TransactionV3WithQueryBit TransactionVersion = "0x100000000000000000000000000000003"
 )
 
+// queryBit is added to the version of the transactions that are only queried.
+var queryBit = new(big.Int).Lsh(big.NewInt(1), 128)
+
 func (v *TransactionVersion) BigInt() (*big.Int, error) {
 	switch *v {
 	case TransactionV0:
 		return big.NewInt(0), nil
 	case TransactionV1:
 		return big.NewInt(1), nil
+	case TransactionV2:
+		return big.NewInt(2), nil
+	case TransactionV3:
+		return big.NewInt(3), nil
+	case TransactionV0WithQueryBit, TransactionV1WithQueryBit, TransactionV2WithQueryBit, TransactionV3WithQueryBit:
+		version, _ := new(big.Int).SetString(string(*v), 0)
+		return version, nil
 	default:
-		return big.NewInt(-1), errors.New(fmt.Sprint("TransactionVersion %i not supported", *v))
+		return big.NewInt(-1), fmt.Errorf("TransactionVersion %s not supported", *v)
+	}
+}
+
+// WithQueryBit returns the query version of v.
+func (v TransactionVersion) WithQueryBit() (TransactionVersion, error) {
+	if v.IsQuery() {
+		return v, nil
 	}
+	version, err := v.BigInt()
+	if err != nil {
+		return "", err
+	}
+	return TransactionVersion(fmt.Sprintf("0x%x", version.Add(version, queryBit))), nil
+}
+
+// IsQuery returns true for the query versions.
+func (v TransactionVersion) IsQuery() bool {
+	switch v {
+	case TransactionV0WithQueryBit, TransactionV1WithQueryBit, TransactionV2WithQueryBit, TransactionV3WithQueryBit:
+		return true
+	}
+	return false
 }
 
 type BroadcastedTransaction interface{}
@@ -316,6 +411,61 @@ type BroadcastedTxnCommonProperties struct {
 	Type      TransactionType `json:"type"`
 }
 
+// ResourceBounds is the maximum amount of a resource a transaction can use,
+// and the maximum price it pays per unit.
+type ResourceBounds struct {
+	// MaxAmount the max amount of the resource that can be used in the tx
+	MaxAmount NumAsHex `json:"max_amount"`
+	// MaxPricePerUnit the max price per unit of this resource for this tx
+	MaxPricePerUnit NumAsHex `json:"max_price_per_unit"`
+}
+
+type ResourceBoundsMapping struct {
+	// L1Gas The max amount and max price per unit of L1 gas used in this tx
+	L1Gas ResourceBounds `json:"l1_gas"`
+	// L2Gas The max amount and max price per unit of L2 gas used in this tx
+	L2Gas ResourceBounds `json:"l2_gas"`
+}
+
+// DataAvailabilityMode specifies a storage domain in Starknet. Each domain has
+// different guarantees regarding availability.
+type DataAvailabilityMode string
+
+const (
+	DAModeL1 DataAvailabilityMode = "L1"
+	DAModeL2 DataAvailabilityMode = "L2"
+)
+
+// UInt64 returns the value of the mode in the transaction hash.
+func (mode DataAvailabilityMode) UInt64() (uint64, error) {
+	switch mode {
+	case DAModeL1:
+		return 0, nil
+	case DAModeL2:
+		return 1, nil
+	}
+	return 0, fmt.Errorf("unknown data availability mode %q", string(mode))
+}
+
+// BroadcastedTxnV3CommonProperties are the properties shared by the version 3
+// transactions, which pay the fee within resource bounds.
+type BroadcastedTxnV3CommonProperties struct {
+	Version TransactionVersion `json:"version"`
+	// Signature
+	Signature      []*felt.Felt          `json:"signature"`
+	Nonce          *felt.Felt            `json:"nonce"`
+	Type           TransactionType       `json:"type"`
+	ResourceBounds ResourceBoundsMapping `json:"resource_bounds"`
+	// Tip the tip for the transaction
+	Tip NumAsHex `json:"tip"`
+	// PaymasterData data needed to allow the paymaster to pay for the transaction in native tokens
+	PaymasterData []*felt.Felt `json:"paymaster_data"`
+	// NonceDataAvailabilityMode the storage domain of the account's nonce
+	NonceDataAvailabilityMode DataAvailabilityMode `json:"nonce_data_availability_mode"`
+	// FeeDataAvailabilityMode the storage domain of the account's balance from which fee will be charged
+	FeeDataAvailabilityMode DataAvailabilityMode `json:"fee_data_availability_mode"`
+}
+
 // BroadcastedInvokeV1Transaction is BROADCASTED_INVOKE_TXN
 // since we only support InvokeV1 transactions
 type BroadcastedInvokeV1Transaction struct {
@@ -324,10 +474,20 @@ type BroadcastedInvokeV1Transaction struct {
 	Calldata      []*felt.Felt `json:"calldata"`
 }
 
+// BroadcastedInvokeV3Transaction is a version 3 BROADCASTED_INVOKE_TXN
+type BroadcastedInvokeV3Transaction struct {
+	BroadcastedTxnV3CommonProperties
+	SenderAddress *felt.Felt   `json:"sender_address"`
+	Calldata      []*felt.Felt `json:"calldata"`
+	// AccountDeploymentData data needed to deploy the account contract from which this tx will be initiated
+	AccountDeploymentData []*felt.Felt `json:"account_deployment_data"`
+}
+
 type BroadcastedDeclareTransaction interface{}
 
 var _ BroadcastedDeclareTransaction = BroadcastedDeclareTransactionV1{}
 var _ BroadcastedDeclareTransaction = BroadcastedDeclareTransactionV2{}
+var _ BroadcastedDeclareTransaction = BroadcastedDeclareTransactionV3{}
 
 type BroadcastedDeclareTransactionV1 struct {
 	BroadcastedTxnCommonProperties
@@ -341,6 +501,14 @@ type BroadcastedDeclareTransactionV2 struct {
 	CompiledClassHash *felt.Felt    `json:"compiled_class_hash"`
 }
 
+type BroadcastedDeclareTransactionV3 struct {
+	BroadcastedTxnV3CommonProperties
+	ContractClass         ContractClass `json:"contract_class"`
+	SenderAddress         *felt.Felt    `json:"sender_address"`
+	CompiledClassHash     *felt.Felt    `json:"compiled_class_hash"`
+	AccountDeploymentData []*felt.Felt  `json:"account_deployment_data"`
+}
+
 type DeployTransactionProperties struct {
 	Version             TransactionVersion `json:"version"`
 	Type                TransactionType    `json:"type"`
@@ -348,9 +516,21 @@ type DeployTransactionProperties struct {
 	ConstructorCalldata []*felt.Felt       `json:"constructor_calldata"`
 }
 
+type BroadcastedDeployAccountTxn interface{}
+
+var _ BroadcastedDeployAccountTxn = BroadcastedDeployAccountTransaction{}
+var _ BroadcastedDeployAccountTxn = BroadcastedDeployAccountTransactionV3{}
+
 type BroadcastedDeployAccountTransaction struct {
 	BroadcastedTxnCommonProperties
 	ContractAddressSalt *felt.Felt   `json:"contract_address_salt"`
 	ConstructorCalldata []*felt.Felt `json:"constructor_calldata"`
 	ClassHash           *felt.Felt   `json:"class_hash"`
 }
+
+type BroadcastedDeployAccountTransactionV3 struct {
+	BroadcastedTxnV3CommonProperties
+	ContractAddressSalt *felt.Felt   `json:"contract_address_salt"`
+	ConstructorCalldata []*felt.Felt `json:"constructor_calldata"`
+	ClassHash           *felt.Felt   `json:"class_hash"`
+}
@@ -2,6 +2,7 @@ package rpc
 
 import (
 	"encoding/json"
+	"math/big"
 	"testing"
 
 	"github.com/sjxqqq/starknet-go/utils"
@@ -24,3 +25,82 @@ func TestTransaction(t *testing.T) {
 	require.Equal(t, b, marshalled)
 
 }
+
+func TestTransactionVersion_BigInt(t *testing.T) {
+	for version, expected := range map[TransactionVersion]int64{
+		TransactionV0: 0,
+		TransactionV1: 1,
+		TransactionV2: 2,
+		TransactionV3: 3,
+	} {
+		value, err := version.BigInt()
+		require.NoError(t, err)
+		require.Equal(t, expected, value.Int64())
+
+		query, err := version.WithQueryBit()
+		require.NoError(t, err)
+		require.True(t, query.IsQuery())
+		require.False(t, version.IsQuery())
+		value, err = query.BigInt()
+		require.NoError(t, err)
+		require.Equal(t, new(big.Int).Add(new(big.Int).Lsh(big.NewInt(1), 128), big.NewInt(expected)), value)
+	}
+	require.Equal(t, TransactionV1WithQueryBit, TransactionVersion("0x100000000000000000000000000000001"))
+	unknown := TransactionVersion("0x4")
+	_, err := unknown.BigInt()
+	require.Error(t, err)
+}
+
+func TestUnmarshalTxnV3(t *testing.T) {
+	content := []byte(`[{
+		"transaction_hash": "0x1",
+		"type": "INVOKE",
+		"version": "0x3",
+		"sender_address": "0x2",
+		"calldata": ["0x3"],
+		"signature": [],
+		"nonce": "0x4",
+		"resource_bounds": {
+			"l1_gas": {"max_amount": "0x186a0", "max_price_per_unit": "0x5af3107a4000"},
+			"l2_gas": {"max_amount": "0x0", "max_price_per_unit": "0x0"}
+		},
+		"tip": "0x0",
+		"paymaster_data": [],
+		"account_deployment_data": [],
+		"nonce_data_availability_mode": "L1",
+		"fee_data_availability_mode": "L2"
+	}, {
+		"transaction_hash": "0x5",
+		"type": "DEPLOY_ACCOUNT",
+		"version": "0x3",
+		"class_hash": "0x6",
+		"contract_address_salt": "0x7",
+		"constructor_calldata": [],
+		"signature": [],
+		"nonce": "0x0",
+		"resource_bounds": {
+			"l1_gas": {"max_amount": "0x1", "max_price_per_unit": "0x1"},
+			"l2_gas": {"max_amount": "0x0", "max_price_per_unit": "0x0"}
+		},
+		"tip": "0x0",
+		"paymaster_data": [],
+		"nonce_data_availability_mode": "L1",
+		"fee_data_availability_mode": "L1"
+	}]`)
+	var txns Transactions
+	require.NoError(t, json.Unmarshal(content, &txns))
+	require.Len(t, txns, 2)
+
+	invoke, ok := txns[0].(InvokeTxnV3)
+	require.True(t, ok, "should be an InvokeTxnV3, instead: %T", txns[0])
+	require.Equal(t, "0x1", invoke.Hash().String())
+	require.Equal(t, NumAsHex("0x186a0"), invoke.ResourceBounds.L1Gas.MaxAmount)
+	require.Equal(t, DAModeL2, invoke.FeeDataAvailabilityMode)
+	mode, err := invoke.FeeDataAvailabilityMode.UInt64()
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), mode)
+
+	deployAccount, ok := txns[1].(DeployAccountTxnV3)
+	require.True(t, ok, "should be a DeployAccountTxnV3, instead: %T", txns[1])
+	require.Equal(t, "0x6", deployAccount.ClassHash.String())
+}
@@ -9,7 +9,7 @@ type BroadcastedInvokeTransaction interface{}
 func (provider *Provider) AddInvokeTransaction(ctx context.Context, broadcastedInvoke BroadcastedInvokeTransaction) (*AddInvokeTransactionResponse, error) {
 	var output AddInvokeTransactionResponse
 	switch invoke := broadcastedInvoke.(type) {
-	case BroadcastedInvokeV1Transaction:
+	case BroadcastedInvokeV1Transaction, BroadcastedInvokeV3Transaction:
 		if err := do(ctx, provider.c, "starknet_addInvokeTransaction", &output, invoke); err != nil {
 			if unexpectedErr, ok := isErrUnexpectedError(err); ok {
 				return nil, unexpectedErr
@@ -55,7 +55,7 @@ func (provider *Provider) AddDeclareTransaction(ctx context.Context, declareTran
 	return &result, nil
 }
 
-func (provider *Provider) AddDeployAccountTransaction(ctx context.Context, deployAccountTransaction BroadcastedDeployAccountTransaction) (*AddDeployAccountTransactionResponse, error) {
+func (provider *Provider) AddDeployAccountTransaction(ctx context.Context, deployAccountTransaction BroadcastedDeployAccountTxn) (*AddDeployAccountTransactionResponse, error) {
 	var result AddDeployAccountTransactionResponse
 	if err := do(ctx, provider.c, "starknet_addDeployAccountTransaction", &result, deployAccountTransaction); err != nil {
 		if unexpectedErr, ok := isErrUnexpectedError(err); ok {
@@ -0,0 +1,74 @@
+package starknetgo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/sjxqqq/starknet-go/rpc"
+	"github.com/sjxqqq/starknet-go/types"
+)
+
+// SimulateOutput is the outcome of a simulated invoke transaction.
+type SimulateOutput struct {
+	Trace       rpc.InvokeTxnTrace
+	FeeEstimate types.FeeEstimate
+	// Reverted is true when the execution of the calls would revert, with
+	// RevertReason explaining why.
+	Reverted     bool
+	RevertReason string
+}
+
+// Simulate builds and signs the invoke transaction Execute would send for
+// calls and runs it on the latest block with SimulateTransactions, without
+// sending it. flags can skip the validation (rpc.SKIP_VALIDATE) or the fee
+// charge (rpc.SKIP_FEE_CHARGE). The transaction is signed with the query
+// version, so it can not be sent afterwards. Only the RPC provider supports
+// it.
+func (account *Account) Simulate(ctx context.Context, calls []types.FunctionCall, details types.ExecuteDetails, flags []rpc.SimulationFlag) (*SimulateOutput, error) {
+	if account.provider != ProviderRPC {
+		return nil, ErrUnsupportedAccount
+	}
+	var tx rpc.BroadcastedTransaction
+	switch account.version {
+	case 1:
+		call, err := account.prepFunctionInvokeRPC(ctx, "simulate", calls, details)
+		if err != nil {
+			return nil, err
+		}
+		call.Type = "INVOKE"
+		tx = *call
+	case 3:
+		call, err := account.prepInvokeV3(ctx, calls, details, true)
+		if err != nil {
+			return nil, err
+		}
+		tx = *call
+	default:
+		return nil, fmt.Errorf("version %d unsupported", account.version)
+	}
+	if flags == nil {
+		flags = []rpc.SimulationFlag{}
+	}
+	simulated, err := account.rpc.SimulateTransactions(ctx, rpc.WithBlockTag("latest"), []rpc.BroadcastedTransaction{tx}, flags)
+	if err != nil {
+		return nil, err
+	}
+	if len(simulated) != 1 {
+		return nil, fmt.Errorf("expected 1 simulated transaction, instead: %d", len(simulated))
+	}
+	trace, ok := simulated[0].TxnTrace.(rpc.InvokeTxnTrace)
+	if !ok {
+		return nil, errors.New("simulated trace is not an invoke trace")
+	}
+	return &SimulateOutput{
+		Trace: trace,
+		FeeEstimate: types.FeeEstimate{
+			GasConsumed: types.NumAsHex(simulated[0].GasConsumed),
+			GasPrice:    types.NumAsHex(simulated[0].GasPrice),
+			OverallFee:  types.NumAsHex(simulated[0].OverallFee),
+		},
+		Reverted:     trace.ExecuteInvocation.IsReverted(),
+		RevertReason: trace.ExecuteInvocation.RevertReason,
+	}, nil
+}
@@ -0,0 +1,106 @@
+package starknetgo
+
+import (
+	"context"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/sjxqqq/starknet-go/rpc"
+	"github.com/sjxqqq/starknet-go/types"
+	"github.com/sjxqqq/starknet-go/utils"
+)
+
+func TestRPCAccount_Simulate(t *testing.T) {
+	type testSetType struct {
+		Options              []AccountOptionFunc
+		Flags                []rpc.SimulationFlag
+		RevertReason         string
+		ExpectedVersion      rpc.TransactionVersion
+		ExpectedFlags        []string
+		ExpectedRevertReason string
+	}
+	testSet := map[string][]testSetType{
+		"mock": {
+			{
+				Options:         []AccountOptionFunc{AccountVersion1},
+				ExpectedVersion: rpc.TransactionV1WithQueryBit,
+				ExpectedFlags:   []string{},
+			},
+			{
+				Options:         []AccountOptionFunc{AccountVersion1},
+				Flags:           []rpc.SimulationFlag{rpc.SKIP_VALIDATE, rpc.SKIP_FEE_CHARGE},
+				ExpectedVersion: rpc.TransactionV1WithQueryBit,
+				ExpectedFlags:   []string{"SKIP_VALIDATE", "SKIP_FEE_CHARGE"},
+			},
+			{
+				Options:              []AccountOptionFunc{AccountVersion1},
+				RevertReason:         "Error in the called contract",
+				ExpectedVersion:      rpc.TransactionV1WithQueryBit,
+				ExpectedFlags:        []string{},
+				ExpectedRevertReason: "Error in the called contract",
+			},
+			{
+				Options:         []AccountOptionFunc{AccountVersion3},
+				Flags:           []rpc.SimulationFlag{rpc.SKIP_FEE_CHARGE},
+				ExpectedVersion: rpc.TransactionV3WithQueryBit,
+				ExpectedFlags:   []string{"SKIP_FEE_CHARGE"},
+			},
+		},
+	}[testEnv]
+
+	call := types.FunctionCall{
+		ContractAddress:    utils.TestHexToFelt(t, mockAccountAddress),
+		EntryPointSelector: types.GetSelectorFromNameFelt("increment"),
+	}
+	for _, test := range testSet {
+		account, mock := newMockRPCAccount(t, test.Options...)
+		mock.nonce = 8
+		mock.revertReason = test.RevertReason
+		mock.estimate = rpc.FeeEstimate{GasConsumed: "0xa", GasPrice: "0xa", OverallFee: "0x64"}
+		output, err := account.Simulate(context.Background(), []types.FunctionCall{call}, types.ExecuteDetails{}, test.Flags)
+		if err != nil {
+			t.Fatal("simulate should succeed, instead:", err)
+		}
+		if output.Reverted != (test.ExpectedRevertReason != "") || output.RevertReason != test.ExpectedRevertReason {
+			t.Fatalf("reverted should be %q, instead: %v %q", test.ExpectedRevertReason, output.Reverted, output.RevertReason)
+		}
+		if output.FeeEstimate.OverallFee != "0x64" {
+			t.Fatalf("overall fee should be 0x64, instead: %s", output.FeeEstimate.OverallFee)
+		}
+		if !reflect.DeepEqual(mock.simulationFlags, test.ExpectedFlags) {
+			t.Fatalf("flags should be %v, instead: %v", test.ExpectedFlags, mock.simulationFlags)
+		}
+
+		var tx struct {
+			Type     rpc.TransactionType    `json:"type"`
+			Version  rpc.TransactionVersion `json:"version"`
+			Nonce    string                 `json:"nonce"`
+			Calldata []string               `json:"calldata"`
+		}
+		mock.lastReceived(t, &tx)
+		if tx.Type != "INVOKE" || tx.Version != test.ExpectedVersion || tx.Nonce != "0x8" {
+			t.Fatalf("unexpected simulated type %s, version %s or nonce %s", tx.Type, tx.Version, tx.Nonce)
+		}
+		if test.ExpectedRevertReason != "" {
+			continue
+		}
+		// the trace is the one of the calls Execute would send
+		expected := fmtCalldataStrings(EncodeCallsCairo0([]types.FunctionCall{call}))
+		calldata := output.Trace.ExecuteInvocation.Calldata
+		if len(calldata) != len(expected) {
+			t.Fatalf("trace calldata should be %v, instead: %v", expected, calldata)
+		}
+		for i := range calldata {
+			if calldata[i].BigInt(big.NewInt(0)).Cmp(types.HexToBN(expected[i])) != 0 {
+				t.Fatalf("trace calldata should be %v, instead: %v", expected, calldata)
+			}
+		}
+	}
+
+	account, _ := newMockRPCAccount(t, AccountVersion1)
+	account.provider = ProviderGateway
+	if _, err := account.Simulate(context.Background(), []types.FunctionCall{call}, types.ExecuteDetails{}, nil); err != ErrUnsupportedAccount {
+		t.Fatalf("gateway simulation should fail with %v, instead: %v", ErrUnsupportedAccount, err)
+	}
+}
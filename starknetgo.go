@@ -7,6 +7,8 @@ import (
 	"fmt"
 	"hash"
 	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc/stark-curve/fr"
 )
 
 /*
@@ -14,9 +16,29 @@ Verifies the validity of the stark curve signature
 given the message hash, and public key (x, y) coordinates
 used to sign the message.
 
+The curve arithmetic of the Stark curve, i.e. Curve, runs on field elements
+in Jacobian coordinates.
+
 (ref: https://github.com/starkware-libs/cairo-lang/blob/master/src/starkware/crypto/starkware/crypto/signature/signature.py)
 */
 func (sc StarkCurve) Verify(msgHash, r, s, pubX, pubY *big.Int) bool {
+	if !sc.usesECBackend() {
+		return sc.verifyAir(msgHash, r, s, pubX, pubY)
+	}
+	if s.Sign() != 1 || s.Cmp(sc.N) != -1 {
+		return false
+	}
+	var w fr.Element
+	w.SetBigInt(s)
+	w.Inverse(&w)
+	if !sc.validSignature(msgHash, r, s, w.BigInt(new(big.Int)), pubX, pubY) {
+		return false
+	}
+	return verifyECBackend(msgHash, r, &w, pubX, pubY)
+}
+
+// verifyAir verifies a signature with the steps of the AIR on big integers.
+func (sc StarkCurve) verifyAir(msgHash, r, s, pubX, pubY *big.Int) bool {
 	w := sc.InvModCurveSize(s)
 
 	if s.Cmp(big.NewInt(0)) != 1 || s.Cmp(sc.N) != -1 {
@@ -84,6 +106,9 @@ Signs the hash value of contents with the provided private key.
 Secret is generated using a golang implementation of RFC 6979.
 Implementation does not yet include "extra entropy" or "retry gen".
 
+The curve arithmetic of the Stark curve, i.e. Curve, runs in constant time on
+field elements with a precomputed table of the generator.
+
 (ref: https://datatracker.ietf.org/doc/html/rfc6979)
 */
 func (sc StarkCurve) Sign(msgHash, privKey *big.Int, seed ...*big.Int) (x, y *big.Int, err error) {
@@ -101,6 +126,15 @@ func (sc StarkCurve) Sign(msgHash, privKey *big.Int, seed ...*big.Int) (x, y *bi
 	if len(seed) == 1 && inSeed != nil {
 		inSeed = seed[0]
 	}
+	if sc.usesECBackend() {
+		r, s := sc.signECBackend(msgHash, privKey, new(big.Int).Set(inSeed))
+		return r, s, nil
+	}
+	return sc.signBig(msgHash, privKey, inSeed)
+}
+
+// signBig signs msgHash with the affine curve arithmetic on big integers.
+func (sc StarkCurve) signBig(msgHash, privKey, inSeed *big.Int) (x, y *big.Int, err error) {
 	for {
 		k := sc.GenerateSecret(big.NewInt(0).Set(msgHash), big.NewInt(0).Set(privKey), big.NewInt(0).Set(inSeed))
 		// In case r is rejected k shall be generated with new seed
@@ -169,13 +203,24 @@ func (sc StarkCurve) ComputeHashOnElements(elems []*big.Int) (hash *big.Int, err
 Provides the pedersen hash of given array of big integers.
 NOTE: This function assumes the curve has been initialized with contant points
 
+The hash of the constant points of PedersenParams, i.e. with Curve, uses
+tables precomputed on first use and is safe for concurrent use.
+
 (ref: https://github.com/seanjameshan/starknet.js/blob/main/src/utils/ellipticCurve.ts)
 */
 func (sc StarkCurve) PedersenHash(elems []*big.Int) (hash *big.Int, err error) {
 	if len(sc.ConstantPoints) == 0 {
 		return hash, fmt.Errorf("must initiate precomputed constant points")
 	}
+	if sc.usesPedersenTables(elems) {
+		return sc.pedersenHashTables(elems)
+	}
+	return sc.pedersenHashBits(elems)
+}
 
+// pedersenHashBits computes the Pedersen hash of elems by adding the constant
+// point of each bit set in the elements.
+func (sc StarkCurve) pedersenHashBits(elems []*big.Int) (hash *big.Int, err error) {
 	ptx := new(big.Int).Set(sc.Gx)
 	pty := new(big.Int).Set(sc.Gy)
 	for i, elem := range elems {
@@ -22,13 +22,122 @@ func BenchmarkSignatureVerify(b *testing.B) {
 	r, s, _ := Curve.Sign(hash, private)
 
 	b.Run(fmt.Sprintf("sign_input_size_%d", hash.BitLen()), func(b *testing.B) {
-		Curve.Sign(hash, private)
+		for i := 0; i < b.N; i++ {
+			Curve.Sign(hash, private)
+		}
+	})
+	b.Run(fmt.Sprintf("sign_big_input_size_%d", hash.BitLen()), func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			Curve.signBig(hash, private, big.NewInt(0))
+		}
 	})
 	b.Run(fmt.Sprintf("verify_input_size_%d", hash.BitLen()), func(b *testing.B) {
-		Curve.Verify(hash, r, s, x, y)
+		for i := 0; i < b.N; i++ {
+			Curve.Verify(hash, r, s, x, y)
+		}
+	})
+	b.Run(fmt.Sprintf("verify_air_input_size_%d", hash.BitLen()), func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			Curve.verifyAir(hash, r, s, x, y)
+		}
+	})
+	inputs := make([]VerifyInput, 100)
+	for i := range inputs {
+		inputs[i] = VerifyInput{MsgHash: hash, R: r, S: s, PubX: x, PubY: y}
+	}
+	b.Run("batch_verify_100", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			Curve.BatchVerify(inputs)
+		}
 	})
 }
 
+// TestGeneral_SignatureBackend checks the field element backend gives the
+// signatures, keys and verifications of the big integer arithmetic.
+func TestGeneral_SignatureBackend(t *testing.T) {
+	for i := 0; i < 8; i++ {
+		priv, err := Curve.GetRandomPrivateKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		hash, err := Curve.GetRandomPrivateKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		hash.Rsh(hash, 1)
+
+		x, y, err := Curve.PrivateToPoint(priv)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expectedX, expectedY := Curve.EcMult(priv, Curve.EcGenX, Curve.EcGenY)
+		if x.Cmp(expectedX) != 0 || y.Cmp(expectedY) != 0 {
+			t.Fatalf("public key of %x should be %x, instead: %x", priv, expectedX, x)
+		}
+
+		r, s, err := Curve.Sign(hash, priv, big.NewInt(int64(i)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		expectedR, expectedS, err := Curve.signBig(hash, priv, big.NewInt(int64(i)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r.Cmp(expectedR) != 0 || s.Cmp(expectedS) != 0 {
+			t.Fatalf("signature should be %x %x, instead: %x %x", expectedR, expectedS, r, s)
+		}
+
+		for _, sig := range [][]*big.Int{
+			{hash, r, s, x, y},
+			{hash, r, s, x, new(big.Int).Sub(Curve.P, y)},
+			{new(big.Int).Add(hash, big.NewInt(1)), r, s, x, y},
+			{hash, new(big.Int).Add(r, big.NewInt(1)), s, x, y},
+			{hash, r, new(big.Int).Add(s, big.NewInt(1)), x, y},
+		} {
+			expected := Curve.verifyAir(sig[0], sig[1], sig[2], sig[3], sig[4])
+			if Curve.Verify(sig[0], sig[1], sig[2], sig[3], sig[4]) != expected {
+				t.Fatalf("verify of %x should be %v", sig, expected)
+			}
+		}
+	}
+}
+
+func TestGeneral_BatchVerify(t *testing.T) {
+	inputs := []VerifyInput{}
+	expected := []bool{}
+	for i := 0; i < 16; i++ {
+		priv, err := Curve.GetRandomPrivateKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		x, y, err := Curve.PrivateToPoint(priv)
+		if err != nil {
+			t.Fatal(err)
+		}
+		hash := big.NewInt(int64(1000 + i))
+		r, s, err := Curve.Sign(hash, priv)
+		if err != nil {
+			t.Fatal(err)
+		}
+		valid := i%3 != 0
+		if !valid {
+			hash = big.NewInt(int64(2000 + i))
+		}
+		inputs = append(inputs, VerifyInput{MsgHash: hash, R: r, S: s, PubX: x, PubY: y})
+		expected = append(expected, valid)
+	}
+	// an invalid s does not prevent the verification of the others
+	inputs = append(inputs, VerifyInput{MsgHash: big.NewInt(1), R: big.NewInt(1), S: big.NewInt(0), PubX: Curve.EcGenX, PubY: Curve.EcGenY})
+	expected = append(expected, false)
+
+	results := Curve.BatchVerify(inputs)
+	for i := range inputs {
+		if results[i] != expected[i] {
+			t.Fatalf("signature %d should be %v, instead: %v", i, expected[i], results[i])
+		}
+	}
+}
+
 func TestGeneral_ComputeHashOnElements(t *testing.T) {
 	hashEmptyArray, err := Curve.ComputeHashOnElements([]*big.Int{})
 	expectedHashEmmptyArray := types.HexToBN("0x49ee3eba8c1600700ee1b87eb599f16716b0b1022947733551fde4050ca6804")
@@ -0,0 +1,188 @@
+package starknetgo
+
+import (
+	"fmt"
+
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/sjxqqq/starknet-go/rpc"
+)
+
+var (
+	stateVersion     = new(felt.Felt).SetBytes([]byte("STARKNET_STATE_V0"))
+	classLeafVersion = new(felt.Felt).SetBytes([]byte("CONTRACT_CLASS_LEAF_V0"))
+)
+
+// StateRoot returns the global state root of a block from the roots of its
+// contracts and classes tries. It is the contracts root before the classes
+// trie was introduced.
+func StateRoot(contractsRoot, classesRoot *felt.Felt) *felt.Felt {
+	if classesRoot.IsZero() {
+		return new(felt.Felt).Set(contractsRoot)
+	}
+	return PoseidonHashMany(stateVersion, contractsRoot, classesRoot)
+}
+
+// ContractStateHash returns the leaf of a contract in the contracts trie.
+func ContractStateHash(classHash, storageRoot, nonce *felt.Felt) *felt.Felt {
+	return PedersenTrieHash(PedersenTrieHash(PedersenTrieHash(classHash, storageRoot), nonce), &felt.Zero)
+}
+
+// ClassTrieLeaf returns the leaf of a class of the given compiled class hash
+// in the classes trie.
+func ClassTrieLeaf(compiledClassHash *felt.Felt) *felt.Felt {
+	return PoseidonHash(classLeafVersion, compiledClassHash)
+}
+
+/*
+Verifies a pathfinder_getProof proof of the storage keys of address against
+stateRoot, the global state root of the block, and returns the values of the
+keys. It returns an error wrapping rpc.ErrContractNotFound when the proof
+shows the contract is not deployed.
+*/
+func VerifyPathfinderProof(stateRoot, address *felt.Felt, keys []*felt.Felt, proof *rpc.PathfinderProof) ([]*felt.Felt, error) {
+	contractsRoot := new(felt.Felt)
+	if len(proof.ContractProof) > 0 {
+		root, err := TrieNodeHash(proof.ContractProof[0], PedersenTrieHash)
+		if err != nil {
+			return nil, err
+		}
+		contractsRoot = root
+	}
+	classesRoot := proof.ClassCommitment
+	if classesRoot == nil {
+		classesRoot = new(felt.Felt)
+	}
+	if !StateRoot(contractsRoot, classesRoot).Equal(stateRoot) {
+		return nil, fmt.Errorf("%w: state root mismatch", ErrInvalidProof)
+	}
+
+	leaf, err := VerifyTrieProof(contractsRoot, address, proof.ContractProof, PedersenTrieHash)
+	if err != nil {
+		return nil, err
+	}
+	if leaf.IsZero() {
+		return nil, fmt.Errorf("%w: %s", rpc.ErrContractNotFound, address)
+	}
+	data := proof.ContractData
+	if data == nil || data.ClassHash == nil || data.Nonce == nil || data.Root == nil {
+		return nil, fmt.Errorf("%w: missing contract data", ErrInvalidProof)
+	}
+	if !ContractStateHash(data.ClassHash, data.Root, data.Nonce).Equal(leaf) {
+		return nil, fmt.Errorf("%w: contract state mismatch", ErrInvalidProof)
+	}
+	if len(data.StorageProofs) != len(keys) {
+		return nil, fmt.Errorf("%w: %d storage proofs for %d keys", ErrInvalidProof, len(data.StorageProofs), len(keys))
+	}
+
+	values := make([]*felt.Felt, len(keys))
+	for i, key := range keys {
+		values[i], err = VerifyTrieProof(data.Root, key, data.StorageProofs[i], PedersenTrieHash)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
+
+// VerifiedState is the state proven by a starknet_getStorageProof proof, in
+// the order of the request.
+type VerifiedState struct {
+	// ClassLeaves are the leaves of the classes, zero for the classes not
+	// declared. They are the ClassTrieLeaf of their compiled class hash.
+	ClassLeaves []*felt.Felt
+	// Contracts are the states of the contracts, nil for the contracts not
+	// deployed.
+	Contracts []*rpc.ContractLeafData
+	// Storage are the values of the storage keys of each contract.
+	Storage [][]*felt.Felt
+}
+
+/*
+Verifies a starknet_getStorageProof proof of the classes, contracts and
+storage keys of the request against stateRoot, the global state root of the
+block, and returns the proven state. The contracts of the storage keys must
+be part of contractAddresses, so their storage root is proven, and the
+contract leaves must include their storage_root.
+*/
+func VerifyStorageProof(stateRoot *felt.Felt, classHashes, contractAddresses []*felt.Felt, contractsStorageKeys []rpc.ContractStorageKeys, proof *rpc.StorageProof) (*VerifiedState, error) {
+	roots := proof.GlobalRoots
+	if roots.ContractsTreeRoot == nil || roots.ClassesTreeRoot == nil {
+		return nil, fmt.Errorf("%w: missing global roots", ErrInvalidProof)
+	}
+	if !StateRoot(roots.ContractsTreeRoot, roots.ClassesTreeRoot).Equal(stateRoot) {
+		return nil, fmt.Errorf("%w: state root mismatch", ErrInvalidProof)
+	}
+
+	state := &VerifiedState{
+		ClassLeaves: make([]*felt.Felt, len(classHashes)),
+		Contracts:   make([]*rpc.ContractLeafData, len(contractAddresses)),
+		Storage:     make([][]*felt.Felt, len(contractsStorageKeys)),
+	}
+	classNodes := proofNodes(proof.ClassesProof)
+	for i, classHash := range classHashes {
+		leaf, err := VerifyTrieProof(roots.ClassesTreeRoot, classHash, classNodes, PoseidonHash)
+		if err != nil {
+			return nil, err
+		}
+		state.ClassLeaves[i] = leaf
+	}
+
+	contractNodes := proofNodes(proof.ContractsProof.Nodes)
+	leaves := proof.ContractsProof.ContractLeavesData
+	for i, address := range contractAddresses {
+		leaf, err := VerifyTrieProof(roots.ContractsTreeRoot, address, contractNodes, PedersenTrieHash)
+		if err != nil {
+			return nil, err
+		}
+		if leaf.IsZero() {
+			continue
+		}
+		if i >= len(leaves) {
+			return nil, fmt.Errorf("%w: missing leaf data of contract %s", ErrInvalidProof, address)
+		}
+		data := leaves[i]
+		if data.ClassHash == nil || data.Nonce == nil || data.StorageRoot == nil {
+			return nil, fmt.Errorf("%w: incomplete leaf data of contract %s", ErrInvalidProof, address)
+		}
+		if !ContractStateHash(data.ClassHash, data.StorageRoot, data.Nonce).Equal(leaf) {
+			return nil, fmt.Errorf("%w: contract state mismatch of %s", ErrInvalidProof, address)
+		}
+		state.Contracts[i] = &data
+	}
+
+	if len(proof.ContractsStorageProofs) != len(contractsStorageKeys) {
+		return nil, fmt.Errorf("%w: %d storage proofs for %d contracts", ErrInvalidProof, len(proof.ContractsStorageProofs), len(contractsStorageKeys))
+	}
+	for i, storageKeys := range contractsStorageKeys {
+		var contract *rpc.ContractLeafData
+		for j, address := range contractAddresses {
+			if address.Equal(storageKeys.ContractAddress) {
+				contract = state.Contracts[j]
+				break
+			}
+		}
+		if contract == nil {
+			return nil, fmt.Errorf("%w: %s", rpc.ErrContractNotFound, storageKeys.ContractAddress)
+		}
+		storageNodes := proofNodes(proof.ContractsStorageProofs[i])
+		state.Storage[i] = make([]*felt.Felt, len(storageKeys.StorageKeys))
+		for j, key := range storageKeys.StorageKeys {
+			value, err := VerifyTrieProof(contract.StorageRoot, key, storageNodes, PedersenTrieHash)
+			if err != nil {
+				return nil, err
+			}
+			state.Storage[i][j] = value
+		}
+	}
+	return state, nil
+}
+
+// proofNodes returns the nodes of a starknet_getStorageProof proof. Their
+// hashes are recomputed when verifying them.
+func proofNodes(nodes []rpc.NodeHashToNode) []rpc.MerkleNode {
+	merkleNodes := make([]rpc.MerkleNode, len(nodes))
+	for i, node := range nodes {
+		merkleNodes[i] = node.Node
+	}
+	return merkleNodes
+}
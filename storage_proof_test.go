@@ -0,0 +1,201 @@
+package starknetgo
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/sjxqqq/starknet-go/rpc"
+	"github.com/sjxqqq/starknet-go/utils"
+)
+
+// testState is a block state of a contract with storage and a declared class.
+type testState struct {
+	contracts     *Trie
+	classes       *Trie
+	storage       *Trie
+	address       *felt.Felt
+	classHash     *felt.Felt
+	compiledClass *felt.Felt
+	nonce         *felt.Felt
+	stateRoot     *felt.Felt
+}
+
+func newTestState(t *testing.T) *testState {
+	t.Helper()
+	state := &testState{
+		contracts:     NewTrie(PedersenTrieHash),
+		classes:       NewTrie(PoseidonHash),
+		storage:       NewTrie(PedersenTrieHash),
+		address:       utils.TestHexToFelt(t, "0x49d36570d4e46f48e99674bd3fcc84644ddd6b96f7c741b1562b82f9e004dc7"),
+		classHash:     utils.TestHexToFelt(t, "0x5ffbcfeb50d200a0677c48a129a11245a3fc519d1d98d76882d1c9a1b19c6ed"),
+		compiledClass: utils.TestHexToFelt(t, "0x6506976af042088c9ea49e6cc9c9a12838ee6920bb989dce02f5c6467bf1b1d"),
+		nonce:         utils.TestHexToFelt(t, "0x3"),
+	}
+	for _, slot := range [][2]string{{"0x1", "0xa"}, {"0x2", "0xb"}, {"0x3c0ffee", "0xc"}} {
+		if err := state.storage.Put(utils.TestHexToFelt(t, slot[0]), utils.TestHexToFelt(t, slot[1])); err != nil {
+			t.Fatal(err)
+		}
+	}
+	leaf := ContractStateHash(state.classHash, state.storage.Root(), state.nonce)
+	for _, address := range []*felt.Felt{state.address, utils.TestHexToFelt(t, "0x1234"), utils.TestHexToFelt(t, "0x7e5")} {
+		if err := state.contracts.Put(address, leaf); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := state.classes.Put(state.classHash, ClassTrieLeaf(state.compiledClass)); err != nil {
+		t.Fatal(err)
+	}
+	state.stateRoot = StateRoot(state.contracts.Root(), state.classes.Root())
+	return state
+}
+
+func (state *testState) pathfinderProof(address *felt.Felt, keys []*felt.Felt) *rpc.PathfinderProof {
+	proof := &rpc.PathfinderProof{
+		StateCommitment: state.stateRoot,
+		ClassCommitment: state.classes.Root(),
+		ContractProof:   state.contracts.Proof(address),
+	}
+	if state.contracts.Get(address).IsZero() {
+		return proof
+	}
+	proof.ContractData = &rpc.PathfinderContractData{
+		ClassHash:                state.classHash,
+		Nonce:                    state.nonce,
+		Root:                     state.storage.Root(),
+		ContractStateHashVersion: new(felt.Felt),
+	}
+	for _, key := range keys {
+		proof.ContractData.StorageProofs = append(proof.ContractData.StorageProofs, state.storage.Proof(key))
+	}
+	return proof
+}
+
+func hashedNodes(nodes []rpc.MerkleNode, hash TrieHash) []rpc.NodeHashToNode {
+	hashed := []rpc.NodeHashToNode{}
+	for _, node := range nodes {
+		nodeHash, _ := TrieNodeHash(node, hash)
+		hashed = append(hashed, rpc.NodeHashToNode{NodeHash: nodeHash, Node: node})
+	}
+	return hashed
+}
+
+func (state *testState) storageProof(address *felt.Felt, keys []*felt.Felt) *rpc.StorageProof {
+	proof := &rpc.StorageProof{
+		ClassesProof: hashedNodes(state.classes.Proof(state.classHash), PoseidonHash),
+		ContractsProof: rpc.ContractsProof{
+			Nodes: hashedNodes(state.contracts.Proof(address), PedersenTrieHash),
+			ContractLeavesData: []rpc.ContractLeafData{{
+				Nonce:       state.nonce,
+				ClassHash:   state.classHash,
+				StorageRoot: state.storage.Root(),
+			}},
+		},
+		GlobalRoots: rpc.GlobalRoots{
+			ContractsTreeRoot: state.contracts.Root(),
+			ClassesTreeRoot:   state.classes.Root(),
+			BlockHash:         new(felt.Felt).SetUint64(0xb10c),
+		},
+	}
+	storageNodes := []rpc.MerkleNode{}
+	for _, key := range keys {
+		storageNodes = append(storageNodes, state.storage.Proof(key)...)
+	}
+	proof.ContractsStorageProofs = [][]rpc.NodeHashToNode{hashedNodes(storageNodes, PedersenTrieHash)}
+	return proof
+}
+
+// TestVerifyPathfinderProof checks the values of pathfinder_getProof proofs.
+func TestVerifyPathfinderProof(t *testing.T) {
+	state := newTestState(t)
+
+	type testSetType struct {
+		Address        string
+		Keys           []string
+		ExpectedValues []string
+		ExpectedError  error
+	}
+	testSet := map[string][]testSetType{
+		"mock": {
+			{
+				Address:        state.address.String(),
+				Keys:           []string{"0x1", "0x3c0ffee", "0x4"},
+				ExpectedValues: []string{"0xa", "0xc", "0x0"},
+			},
+			{
+				Address:       "0x7e6",
+				Keys:          []string{"0x1"},
+				ExpectedError: rpc.ErrContractNotFound,
+			},
+		},
+	}[testEnv]
+
+	for _, test := range testSet {
+		address := utils.TestHexToFelt(t, test.Address)
+		keys := utils.TestHexArrToFelt(t, test.Keys)
+		values, err := VerifyPathfinderProof(state.stateRoot, address, keys, state.pathfinderProof(address, keys))
+		if test.ExpectedError != nil {
+			if !errors.Is(err, test.ExpectedError) {
+				t.Fatalf("expected %v, got %v", test.ExpectedError, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, value := range values {
+			if value.String() != test.ExpectedValues[i] {
+				t.Fatalf("key %s: expected %s, got %s", test.Keys[i], test.ExpectedValues[i], value)
+			}
+		}
+	}
+
+	address := state.address
+	keys := utils.TestHexArrToFelt(t, []string{"0x2"})
+	proof := state.pathfinderProof(address, keys)
+	if _, err := VerifyPathfinderProof(new(felt.Felt).SetUint64(1), address, keys, proof); !errors.Is(err, ErrInvalidProof) {
+		t.Fatalf("expected a state root mismatch, got %v", err)
+	}
+	proof.ContractData.Nonce = new(felt.Felt).SetUint64(4)
+	if _, err := VerifyPathfinderProof(state.stateRoot, address, keys, proof); !errors.Is(err, ErrInvalidProof) {
+		t.Fatalf("expected a contract state mismatch, got %v", err)
+	}
+}
+
+// TestVerifyStorageProof checks the state of starknet_getStorageProof proofs.
+func TestVerifyStorageProof(t *testing.T) {
+	state := newTestState(t)
+	keys := utils.TestHexArrToFelt(t, []string{"0x2", "0x5"})
+	storageKeys := []rpc.ContractStorageKeys{{ContractAddress: state.address, StorageKeys: keys}}
+
+	proof := state.storageProof(state.address, keys)
+	verified, err := VerifyStorageProof(state.stateRoot, []*felt.Felt{state.classHash}, []*felt.Felt{state.address}, storageKeys, proof)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !verified.ClassLeaves[0].Equal(ClassTrieLeaf(state.compiledClass)) {
+		t.Fatalf("expected the leaf of the compiled class, got %s", verified.ClassLeaves[0])
+	}
+	if verified.Contracts[0] == nil || !verified.Contracts[0].Nonce.Equal(state.nonce) {
+		t.Fatalf("expected the contract nonce %s, got %+v", state.nonce, verified.Contracts[0])
+	}
+	if verified.Storage[0][0].String() != "0xb" || !verified.Storage[0][1].IsZero() {
+		t.Fatalf("expected the values 0xb and 0x0, got %v", verified.Storage[0])
+	}
+
+	proof.GlobalRoots.ClassesTreeRoot = new(felt.Felt).SetUint64(1)
+	if _, err := VerifyStorageProof(state.stateRoot, nil, []*felt.Felt{state.address}, storageKeys, proof); !errors.Is(err, ErrInvalidProof) {
+		t.Fatalf("expected a state root mismatch, got %v", err)
+	}
+
+	proof = state.storageProof(state.address, keys)
+	proof.ContractsProof.ContractLeavesData[0].StorageRoot = new(felt.Felt).SetUint64(1)
+	if _, err := VerifyStorageProof(state.stateRoot, nil, []*felt.Felt{state.address}, storageKeys, proof); !errors.Is(err, ErrInvalidProof) {
+		t.Fatalf("expected a contract state mismatch, got %v", err)
+	}
+
+	proof = state.storageProof(state.address, keys)
+	if _, err := VerifyStorageProof(state.stateRoot, nil, nil, storageKeys, proof); !errors.Is(err, rpc.ErrContractNotFound) {
+		t.Fatalf("expected the storage of an unproven contract to fail, got %v", err)
+	}
+}
@@ -0,0 +1,465 @@
+package starknetgo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/sjxqqq/starknet-go/rpc"
+	"github.com/sjxqqq/starknet-go/types"
+	"github.com/sjxqqq/starknet-go/utils"
+)
+
+const (
+	L1_GAS_NAME = "L1_GAS"
+	L2_GAS_NAME = "L2_GAS"
+)
+
+// maxPricePerUnit is the upper bound of the price of a resource, which is
+// packed on 128 bits in the transaction hash.
+var maxPricePerUnit = new(big.Int).Lsh(big.NewInt(1), 128)
+
+// resourceBoundsFelt packs the bounds of a resource the way the v3
+// transaction hash expects them: name (60 bits), max amount (64 bits) and
+// max price per unit (128 bits).
+func resourceBoundsFelt(name string, bounds types.ResourceBounds) (*felt.Felt, error) {
+	price := big.NewInt(0)
+	if bounds.MaxPricePerUnit != nil {
+		price = bounds.MaxPricePerUnit
+	}
+	if price.Sign() < 0 || price.Cmp(maxPricePerUnit) >= 0 {
+		return nil, fmt.Errorf("%s max price per unit 0x%x does not fit 128 bits", name, price)
+	}
+	packed := new(big.Int).Lsh(types.UTF8StrToBig(name), 192)
+	packed.Or(packed, new(big.Int).Lsh(new(big.Int).SetUint64(bounds.MaxAmount), 128))
+	packed.Or(packed, price)
+	return utils.BigIntToFelt(packed)
+}
+
+// v3HashData returns the fields hashed by every v3 transaction of sender, up
+// to the data availability modes. The transaction specific fields are
+// appended by the caller. version is TransactionV3, or its query version for
+// an estimate.
+func v3HashData(prefix string, version rpc.TransactionVersion, sender *felt.Felt, chainID string, details types.ExecuteDetails) ([]*felt.Felt, error) {
+	if details.ResourceBounds == nil {
+		return nil, errors.New("resource bounds are required for v3 transactions")
+	}
+	versionBig, err := version.BigInt()
+	if err != nil {
+		return nil, err
+	}
+	versionFelt, err := utils.BigIntToFelt(versionBig)
+	if err != nil {
+		return nil, err
+	}
+	l1Gas, err := resourceBoundsFelt(L1_GAS_NAME, details.ResourceBounds.L1Gas)
+	if err != nil {
+		return nil, err
+	}
+	l2Gas, err := resourceBoundsFelt(L2_GAS_NAME, details.ResourceBounds.L2Gas)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := utils.BigIntToFelt(details.Nonce)
+	if err != nil {
+		return nil, err
+	}
+	chainIDFelt, err := utils.BigIntToFelt(types.UTF8StrToBig(chainID))
+	if err != nil {
+		return nil, err
+	}
+	daModes := uint64(details.NonceDataAvailabilityMode)<<32 + uint64(details.FeeDataAvailabilityMode)
+	return []*felt.Felt{
+		new(felt.Felt).SetBytes([]byte(prefix)),
+		versionFelt,
+		sender,
+		PoseidonHashMany(new(felt.Felt).SetUint64(details.Tip), l1Gas, l2Gas),
+		PoseidonHashMany(details.PaymasterData...),
+		chainIDFelt,
+		nonce,
+		new(felt.Felt).SetUint64(daModes),
+	}, nil
+}
+
+// invokeV3Hash computes the hash of an INVOKE v3 transaction sending calldata
+// to the account __execute__ entry point.
+func (account *Account) invokeV3Hash(calldata []*felt.Felt, version rpc.TransactionVersion, details types.ExecuteDetails) (*felt.Felt, error) {
+	return hashInvokeV3(version, account.AccountAddress, account.chainId, calldata, details)
+}
+
+// hashInvokeV3 computes the hash of an INVOKE v3 transaction of sender.
+func hashInvokeV3(version rpc.TransactionVersion, sender *felt.Felt, chainID string, calldata []*felt.Felt, details types.ExecuteDetails) (*felt.Felt, error) {
+	data, err := v3HashData(TRANSACTION_PREFIX, version, sender, chainID, details)
+	if err != nil {
+		return nil, err
+	}
+	data = append(data,
+		PoseidonHashMany(details.AccountDeploymentData...),
+		PoseidonHashMany(calldata...),
+	)
+	return PoseidonHashMany(data...), nil
+}
+
+// declareV3Hash computes the hash of a DECLARE v3 transaction.
+func (account *Account) declareV3Hash(classHash, compiledClassHash *felt.Felt, details types.ExecuteDetails) (*felt.Felt, error) {
+	return hashDeclareV3(account.AccountAddress, account.chainId, classHash, compiledClassHash, details)
+}
+
+// hashDeclareV3 computes the hash of a DECLARE v3 transaction of sender.
+func hashDeclareV3(sender *felt.Felt, chainID string, classHash, compiledClassHash *felt.Felt, details types.ExecuteDetails) (*felt.Felt, error) {
+	data, err := v3HashData(DECLARE_PREFIX, rpc.TransactionV3, sender, chainID, details)
+	if err != nil {
+		return nil, err
+	}
+	data = append(data,
+		PoseidonHashMany(details.AccountDeploymentData...),
+		classHash,
+		compiledClassHash,
+	)
+	return PoseidonHashMany(data...), nil
+}
+
+// deployAccountV3Hash computes the hash of a DEPLOY_ACCOUNT v3 transaction for
+// the account address.
+func (account *Account) deployAccountV3Hash(classHash, salt *felt.Felt, constructorCalldata []*felt.Felt, details types.ExecuteDetails) (*felt.Felt, error) {
+	return hashDeployAccountV3(account.AccountAddress, account.chainId, classHash, salt, constructorCalldata, details)
+}
+
+// hashDeployAccountV3 computes the hash of a DEPLOY_ACCOUNT v3 transaction
+// deploying the account at address.
+func hashDeployAccountV3(address *felt.Felt, chainID string, classHash, salt *felt.Felt, constructorCalldata []*felt.Felt, details types.ExecuteDetails) (*felt.Felt, error) {
+	data, err := v3HashData(DEPLOY_ACCOUNT_PREFIX, rpc.TransactionV3, address, chainID, details)
+	if err != nil {
+		return nil, err
+	}
+	data = append(data,
+		PoseidonHashMany(constructorCalldata...),
+		classHash,
+		salt,
+	)
+	return PoseidonHashMany(data...), nil
+}
+
+// signV3 signs the hash of a v3 transaction described by req and details with
+// the account key.
+func (account *Account) signV3(ctx context.Context, hash *felt.Felt, req SignRequest, details types.ExecuteDetails) ([]*felt.Felt, error) {
+	req.Hash = hash.BigInt(big.NewInt(0))
+	req.MaxFee = maxFeeV3(details.ResourceBounds, details.Tip)
+	req.Nonce = details.Nonce
+	req.ResourceBounds = details.ResourceBounds
+	req.Tip = details.Tip
+	req.PaymasterData = details.PaymasterData
+	req.AccountDeploymentData = details.AccountDeploymentData
+	req.NonceDataAvailabilityMode = details.NonceDataAvailabilityMode
+	req.FeeDataAvailabilityMode = details.FeeDataAvailabilityMode
+	return account.signRequest(ctx, req)
+}
+
+func rpcDataAvailabilityMode(mode types.DataAvailabilityMode) (rpc.DataAvailabilityMode, error) {
+	switch mode {
+	case types.DAModeL1:
+		return rpc.DAModeL1, nil
+	case types.DAModeL2:
+		return rpc.DAModeL2, nil
+	}
+	return "", fmt.Errorf("unknown data availability mode %d", mode)
+}
+
+func rpcResourceBounds(bounds types.ResourceBounds) rpc.ResourceBounds {
+	price := big.NewInt(0)
+	if bounds.MaxPricePerUnit != nil {
+		price = bounds.MaxPricePerUnit
+	}
+	return rpc.ResourceBounds{
+		MaxAmount:       rpc.NumAsHex(fmt.Sprintf("0x%x", bounds.MaxAmount)),
+		MaxPricePerUnit: rpc.NumAsHex(fmt.Sprintf("0x%x", price)),
+	}
+}
+
+// rpcV3CommonProperties returns the RPC properties of a v3 transaction signed
+// with signature.
+func rpcV3CommonProperties(txType rpc.TransactionType, version rpc.TransactionVersion, signature []*felt.Felt, details types.ExecuteDetails) (rpc.BroadcastedTxnV3CommonProperties, error) {
+	nonce, err := utils.BigIntToFelt(details.Nonce)
+	if err != nil {
+		return rpc.BroadcastedTxnV3CommonProperties{}, err
+	}
+	nonceDAMode, err := rpcDataAvailabilityMode(details.NonceDataAvailabilityMode)
+	if err != nil {
+		return rpc.BroadcastedTxnV3CommonProperties{}, err
+	}
+	feeDAMode, err := rpcDataAvailabilityMode(details.FeeDataAvailabilityMode)
+	if err != nil {
+		return rpc.BroadcastedTxnV3CommonProperties{}, err
+	}
+	paymasterData := details.PaymasterData
+	if paymasterData == nil {
+		paymasterData = []*felt.Felt{}
+	}
+	return rpc.BroadcastedTxnV3CommonProperties{
+		Version:   version,
+		Signature: signature,
+		Nonce:     nonce,
+		Type:      txType,
+		ResourceBounds: rpc.ResourceBoundsMapping{
+			L1Gas: rpcResourceBounds(details.ResourceBounds.L1Gas),
+			L2Gas: rpcResourceBounds(details.ResourceBounds.L2Gas),
+		},
+		Tip:                       rpc.NumAsHex(fmt.Sprintf("0x%x", details.Tip)),
+		PaymasterData:             paymasterData,
+		NonceDataAvailabilityMode: nonceDAMode,
+		FeeDataAvailabilityMode:   feeDAMode,
+	}, nil
+}
+
+// maxFeeV3 is the most a v3 transaction can pay with its resource bounds and
+// tip, which is paid on each unit of L2 gas.
+func maxFeeV3(bounds *types.ResourceBoundsMapping, tip uint64) *big.Int {
+	maxFee := big.NewInt(0)
+	for _, resource := range []types.ResourceBounds{bounds.L1Gas, bounds.L2Gas} {
+		if resource.MaxPricePerUnit == nil {
+			continue
+		}
+		maxFee.Add(maxFee, new(big.Int).Mul(new(big.Int).SetUint64(resource.MaxAmount), resource.MaxPricePerUnit))
+	}
+	tipFee := new(big.Int).Mul(new(big.Int).SetUint64(bounds.L2Gas.MaxAmount), new(big.Int).SetUint64(tip))
+	return maxFee.Add(maxFee, tipFee)
+}
+
+func accountDeploymentData(details types.ExecuteDetails) []*felt.Felt {
+	if details.AccountDeploymentData == nil {
+		return []*felt.Felt{}
+	}
+	return details.AccountDeploymentData
+}
+
+// prepInvokeV3 signs the INVOKE v3 transaction of calls. A query transaction
+// is signed with the query version so it can only be estimated or simulated.
+// The resource bounds default to 0, which is what the fee estimation expects.
+func (account *Account) prepInvokeV3(ctx context.Context, calls []types.FunctionCall, details types.ExecuteDetails, query bool) (*rpc.BroadcastedInvokeV3Transaction, error) {
+	version := rpc.TransactionV3
+	if query {
+		version = rpc.TransactionV3WithQueryBit
+	}
+	if details.Nonce == nil {
+		nonce, err := account.currentNonce(ctx)
+		if err != nil {
+			return nil, err
+		}
+		details.Nonce = nonce
+	}
+	if details.ResourceBounds == nil {
+		details.ResourceBounds = &types.ResourceBoundsMapping{}
+	}
+	if account.plugin != nil {
+		call, err := account.plugin.PluginCall(calls)
+		if err != nil {
+			return nil, err
+		}
+		calls = append([]types.FunctionCall{call}, calls...)
+	}
+	calldata, err := utils.HexArrToFelt(fmtCalldataStrings(account.encodeCalls(calls)))
+	if err != nil {
+		return nil, err
+	}
+	hash, err := account.invokeV3Hash(calldata, version, details)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := account.signV3(ctx, hash, SignRequest{
+		Type:     rpc.TransactionType_Invoke,
+		Version:  version,
+		Calls:    calls,
+		Calldata: calldata,
+	}, details)
+	if err != nil {
+		return nil, err
+	}
+	common, err := rpcV3CommonProperties("INVOKE", version, signature, details)
+	if err != nil {
+		return nil, err
+	}
+	return &rpc.BroadcastedInvokeV3Transaction{
+		BroadcastedTxnV3CommonProperties: common,
+		SenderAddress:                    account.AccountAddress,
+		Calldata:                         calldata,
+		AccountDeploymentData:            accountDeploymentData(details),
+	}, nil
+}
+
+// executeV3 sends calls with an INVOKE v3 transaction. Without resource bounds
+// in details, the max fee of the account FeeStrategy is spent on the estimated
+// L1 gas: the max amount is the estimated gas and the max price per unit the
+// max fee divided by that amount.
+func (account *Account) executeV3(ctx context.Context, calls []types.FunctionCall, details types.ExecuteDetails) (*types.AddInvokeTransactionOutput, error) {
+	if account.provider != ProviderRPC {
+		return nil, ErrUnsupportedAccount
+	}
+	feeReason := "resource bounds set in the execute details"
+	if details.ResourceBounds == nil {
+		var estimate *types.FeeEstimate
+		estimateFee := func(ctx context.Context) (*types.FeeEstimate, error) {
+			if estimate != nil {
+				return estimate, nil
+			}
+			var err error
+			estimate, err = account.EstimateFee(ctx, calls, details)
+			return estimate, err
+		}
+		maxFee, reason, err := account.feeStrategy.MaxFee(ctx, FeeRequest{
+			Account:  account,
+			Version:  3,
+			Estimate: estimateFee,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if _, err := estimateFee(ctx); err != nil {
+			return nil, err
+		}
+		bounds, err := l1GasBounds(estimate, maxFee)
+		if err != nil {
+			return nil, err
+		}
+		details.ResourceBounds = &types.ResourceBoundsMapping{L1Gas: bounds}
+		feeReason = reason
+	}
+	tx, err := account.prepInvokeV3(ctx, calls, details, false)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := account.rpc.AddInvokeTransaction(ctx, *tx)
+	if err != nil {
+		return nil, sendError(err)
+	}
+	return &types.AddInvokeTransactionOutput{
+		TransactionHash: resp.TransactionHash,
+		MaxFee:          maxFeeV3(details.ResourceBounds, details.Tip),
+		FeeReason:       feeReason,
+	}, nil
+}
+
+// l1GasBounds returns the L1 gas bounds spending up to maxFee on the gas of
+// estimate. The max amount covers the overall fee of the estimate at its gas
+// price.
+func l1GasBounds(estimate *types.FeeEstimate, maxFee *big.Int) (types.ResourceBounds, error) {
+	gasConsumed, ok := new(big.Int).SetString(string(estimate.GasConsumed), 0)
+	if !ok {
+		return types.ResourceBounds{}, errors.New("could not match GasConsumed to big.Int")
+	}
+	gasPrice, ok := new(big.Int).SetString(string(estimate.GasPrice), 0)
+	if !ok {
+		return types.ResourceBounds{}, errors.New("could not match GasPrice to big.Int")
+	}
+	overallFee, ok := new(big.Int).SetString(string(estimate.OverallFee), 0)
+	if !ok {
+		return types.ResourceBounds{}, errors.New("could not match OverallFee to big.Int")
+	}
+	amount := gasConsumed
+	if gasPrice.Sign() > 0 {
+		// the overall fee also pays for the data gas
+		covered := new(big.Int).Add(overallFee, new(big.Int).Sub(gasPrice, big.NewInt(1)))
+		covered.Div(covered, gasPrice)
+		if covered.Cmp(amount) > 0 {
+			amount = covered
+		}
+	}
+	if amount.Sign() <= 0 || !amount.IsUint64() {
+		return types.ResourceBounds{}, fmt.Errorf("invalid estimated gas 0x%x", amount)
+	}
+	return types.ResourceBounds{
+		MaxAmount:       amount.Uint64(),
+		MaxPricePerUnit: new(big.Int).Div(maxFee, amount),
+	}, nil
+}
+
+// signDeclareV3 returns the signed DECLARE v3 transaction of contract and its
+// class hash.
+func (account *Account) signDeclareV3(ctx context.Context, contract rpc.ContractClass, casm rpc.CasmClass, details types.ExecuteDetails) (rpc.BroadcastedDeclareTransactionV3, *felt.Felt, error) {
+	if details.Nonce == nil {
+		nonce, err := account.currentNonce(ctx)
+		if err != nil {
+			return rpc.BroadcastedDeclareTransactionV3{}, nil, err
+		}
+		details.Nonce = nonce
+	}
+	classHash := ClassHash(contract)
+	compiledClassHash, err := CompiledClassHash(casm)
+	if err != nil {
+		return rpc.BroadcastedDeclareTransactionV3{}, nil, err
+	}
+	hash, err := account.declareV3Hash(classHash, compiledClassHash, details)
+	if err != nil {
+		return rpc.BroadcastedDeclareTransactionV3{}, nil, err
+	}
+	signature, err := account.signV3(ctx, hash, SignRequest{
+		Type:              rpc.TransactionType_Declare,
+		Version:           rpc.TransactionV3,
+		ClassHash:         classHash,
+		CompiledClassHash: compiledClassHash,
+	}, details)
+	if err != nil {
+		return rpc.BroadcastedDeclareTransactionV3{}, nil, err
+	}
+	common, err := rpcV3CommonProperties("DECLARE", rpc.TransactionV3, signature, details)
+	if err != nil {
+		return rpc.BroadcastedDeclareTransactionV3{}, nil, err
+	}
+	return rpc.BroadcastedDeclareTransactionV3{
+		BroadcastedTxnV3CommonProperties: common,
+		ContractClass:                    contract,
+		SenderAddress:                    account.AccountAddress,
+		CompiledClassHash:                compiledClassHash,
+		AccountDeploymentData:            accountDeploymentData(details),
+	}, classHash, nil
+}
+
+// deployAccountV3 deploys the account with a DEPLOY_ACCOUNT v3 transaction.
+func (account *Account) deployAccountV3(ctx context.Context, classHash, salt *felt.Felt, constructorCalldata []*felt.Felt, details types.ExecuteDetails) (*types.AddDeployResponse, error) {
+	if account.provider != ProviderRPC {
+		return nil, ErrUnsupportedAccount
+	}
+	tx, err := account.signDeployAccountV3(ctx, classHash, salt, constructorCalldata, details)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := account.rpc.AddDeployAccountTransaction(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+	return &types.AddDeployResponse{
+		Code:            "TRANSACTION_RECEIVED",
+		TransactionHash: resp.TransactionHash.String(),
+		ContractAddress: resp.ContractAddress.String(),
+	}, nil
+}
+
+// signDeployAccountV3 returns the signed DEPLOY_ACCOUNT v3 transaction of the
+// account. details.Nonce must be set.
+func (account *Account) signDeployAccountV3(ctx context.Context, classHash, salt *felt.Felt, constructorCalldata []*felt.Felt, details types.ExecuteDetails) (rpc.BroadcastedDeployAccountTransactionV3, error) {
+	hash, err := account.deployAccountV3Hash(classHash, salt, constructorCalldata, details)
+	if err != nil {
+		return rpc.BroadcastedDeployAccountTransactionV3{}, err
+	}
+	signature, err := account.signV3(ctx, hash, SignRequest{
+		Type:                rpc.TransactionType_DeployAccount,
+		Version:             rpc.TransactionV3,
+		Calldata:            constructorCalldata,
+		ClassHash:           classHash,
+		ContractAddressSalt: salt,
+	}, details)
+	if err != nil {
+		return rpc.BroadcastedDeployAccountTransactionV3{}, err
+	}
+	common, err := rpcV3CommonProperties("DEPLOY_ACCOUNT", rpc.TransactionV3, signature, details)
+	if err != nil {
+		return rpc.BroadcastedDeployAccountTransactionV3{}, err
+	}
+	return rpc.BroadcastedDeployAccountTransactionV3{
+		BroadcastedTxnV3CommonProperties: common,
+		ContractAddressSalt:              salt,
+		ConstructorCalldata:              constructorCalldata,
+		ClassHash:                        classHash,
+	}, nil
+}
@@ -0,0 +1,249 @@
+package starknetgo
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"os"
+	"testing"
+
+	"github.com/NethermindEth/juno/core/crypto"
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/sjxqqq/starknet-go/rpc"
+	"github.com/sjxqqq/starknet-go/types"
+	"github.com/sjxqqq/starknet-go/utils"
+)
+
+// testResourceBounds pays up to 100000 L1 gas at 10^14 fri per unit.
+var testResourceBounds = types.ResourceBoundsMapping{
+	L1Gas: types.ResourceBounds{
+		MaxAmount:       100000,
+		MaxPricePerUnit: big.NewInt(100000000000000),
+	},
+}
+
+// testV3HashData returns the common fields of the v3 transaction hash from the
+// values sent to the mock node.
+func testV3HashData(t *testing.T, prefix string, address *felt.Felt, common rpc.BroadcastedTxnV3CommonProperties, daModes uint64) []*felt.Felt {
+	t.Helper()
+	return []*felt.Felt{
+		new(felt.Felt).SetBytes([]byte(prefix)),
+		new(felt.Felt).SetUint64(3),
+		address,
+		crypto.PoseidonArray(
+			utils.TestHexToFelt(t, string(common.Tip)),
+			utils.TestHexToFelt(t, "0x4c315f47415300000000000186a0000000000000000000005af3107a4000"),
+			utils.TestHexToFelt(t, "0x4c325f474153000000000000000000000000000000000000000000000000"),
+		),
+		crypto.PoseidonArray(common.PaymasterData...),
+		new(felt.Felt).SetBytes([]byte("SN_GOERLI")),
+		common.Nonce,
+		new(felt.Felt).SetUint64(daModes),
+	}
+}
+
+func TestResourceBoundsFelt(t *testing.T) {
+	type testSetType struct {
+		Name          string
+		Bounds        types.ResourceBounds
+		Expected      string
+		ExpectedError bool
+	}
+	testSet := map[string][]testSetType{
+		"mock": {
+			{
+				Name:     L1_GAS_NAME,
+				Bounds:   testResourceBounds.L1Gas,
+				Expected: "0x4c315f47415300000000000186a0000000000000000000005af3107a4000",
+			},
+			{
+				Name:     L2_GAS_NAME,
+				Bounds:   types.ResourceBounds{},
+				Expected: "0x4c325f474153000000000000000000000000000000000000000000000000",
+			},
+			{
+				Name:          L1_GAS_NAME,
+				Bounds:        types.ResourceBounds{MaxPricePerUnit: new(big.Int).Lsh(big.NewInt(1), 128)},
+				ExpectedError: true,
+			},
+		},
+	}[testEnv]
+
+	for _, test := range testSet {
+		packed, err := resourceBoundsFelt(test.Name, test.Bounds)
+		if test.ExpectedError {
+			if err == nil {
+				t.Fatal("price over 128 bits should fail")
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if packed.String() != test.Expected {
+			t.Fatalf("%s bounds should be %s, instead: %s", test.Name, test.Expected, packed)
+		}
+	}
+}
+
+func TestRPCAccount_ExecuteV3(t *testing.T) {
+	type testSetType struct {
+		Options                []AccountOptionFunc
+		Details                types.ExecuteDetails
+		Estimate               rpc.FeeEstimate
+		ExpectedL1Gas          rpc.ResourceBounds
+		ExpectedMaxFee         *big.Int
+		ExpectedReason         string
+		ExpectedFeeDAMode      rpc.DataAvailabilityMode
+		ExpectedDataAvailModes uint64
+	}
+	testSet := map[string][]testSetType{
+		"mock": {
+			{
+				Details: types.ExecuteDetails{
+					ResourceBounds:          &testResourceBounds,
+					Tip:                     2,
+					PaymasterData:           []*felt.Felt{utils.TestHexToFelt(t, "0x1")},
+					FeeDataAvailabilityMode: types.DAModeL2,
+				},
+				ExpectedL1Gas:          rpc.ResourceBounds{MaxAmount: "0x186a0", MaxPricePerUnit: "0x5af3107a4000"},
+				ExpectedMaxFee:         new(big.Int).Mul(big.NewInt(100000), big.NewInt(100000000000000)),
+				ExpectedReason:         "resource bounds set in the execute details",
+				ExpectedFeeDAMode:      rpc.DAModeL2,
+				ExpectedDataAvailModes: 1,
+			},
+			{
+				// the max fee of the default strategy, twice the estimate,
+				// is spent on the estimated gas
+				Estimate:          rpc.FeeEstimate{GasConsumed: "0x64", GasPrice: "0xa", OverallFee: "0x3e8"},
+				ExpectedL1Gas:     rpc.ResourceBounds{MaxAmount: "0x64", MaxPricePerUnit: "0x14"},
+				ExpectedMaxFee:    big.NewInt(2000),
+				ExpectedReason:    "estimated fee 0x3e8 x2.00",
+				ExpectedFeeDAMode: rpc.DAModeL1,
+			},
+			{
+				// the gas covers the overall fee, including the data gas
+				Options:           []AccountOptionFunc{AccountFeeStrategy(FixedFee(big.NewInt(3000)))},
+				Estimate:          rpc.FeeEstimate{GasConsumed: "0x5a", GasPrice: "0xa", OverallFee: "0x3e8"},
+				ExpectedL1Gas:     rpc.ResourceBounds{MaxAmount: "0x64", MaxPricePerUnit: "0x1e"},
+				ExpectedMaxFee:    big.NewInt(3000),
+				ExpectedReason:    "fixed max fee 0xbb8",
+				ExpectedFeeDAMode: rpc.DAModeL1,
+			},
+		},
+	}[testEnv]
+
+	call := types.FunctionCall{
+		ContractAddress:    utils.TestHexToFelt(t, mockAccountAddress),
+		EntryPointSelector: types.GetSelectorFromNameFelt("increment"),
+	}
+	for _, test := range testSet {
+		account, mock := newMockRPCAccount(t, append([]AccountOptionFunc{AccountVersion3}, test.Options...)...)
+		mock.nonce = 5
+		mock.estimate = test.Estimate
+		output, err := account.Execute(context.Background(), []types.FunctionCall{call}, test.Details)
+		if err != nil {
+			t.Fatal("execute should succeed, instead:", err)
+		}
+		if output.MaxFee.Cmp(test.ExpectedMaxFee) != 0 || output.FeeReason != test.ExpectedReason {
+			t.Fatalf("max fee should be %s (%s), instead: %s (%s)", test.ExpectedMaxFee, test.ExpectedReason, output.MaxFee, output.FeeReason)
+		}
+
+		var tx rpc.BroadcastedInvokeV3Transaction
+		mock.lastReceived(t, &tx)
+		if tx.Version != rpc.TransactionV3 || tx.Type != "INVOKE" || tx.Nonce.String() != "0x5" {
+			t.Fatalf("unexpected invoke version %s, type %s or nonce %s", tx.Version, tx.Type, tx.Nonce)
+		}
+		if tx.ResourceBounds.L1Gas != test.ExpectedL1Gas {
+			t.Fatalf("l1 gas bounds should be %+v, instead: %+v", test.ExpectedL1Gas, tx.ResourceBounds.L1Gas)
+		}
+		if tx.NonceDataAvailabilityMode != rpc.DAModeL1 || tx.FeeDataAvailabilityMode != test.ExpectedFeeDAMode {
+			t.Fatalf("unexpected data availability modes %s and %s", tx.NonceDataAvailabilityMode, tx.FeeDataAvailabilityMode)
+		}
+		if test.Details.ResourceBounds == nil {
+			continue
+		}
+		data := append(
+			testV3HashData(t, "invoke", account.AccountAddress, tx.BroadcastedTxnV3CommonProperties, test.ExpectedDataAvailModes),
+			crypto.PoseidonArray(tx.AccountDeploymentData...),
+			crypto.PoseidonArray(tx.Calldata...),
+		)
+		hash := crypto.PoseidonArray(data...)
+		verifyMockSignature(t, hash.BigInt(big.NewInt(0)), tx.Signature)
+	}
+}
+
+func TestRPCAccount_DeclareV3(t *testing.T) {
+	content, err := os.ReadFile("./rpc/tests/0x4e70b19333ae94bd958625f7b61ce9eec631653597e68645e13780061b2136c.json")
+	if err != nil {
+		t.Fatal("should read the class, instead:", err)
+	}
+	var class rpc.ContractClass
+	if err := json.Unmarshal(content, &class); err != nil {
+		t.Fatal("should unmarshal the class, instead:", err)
+	}
+	casm := rpc.CasmClass{Bytecode: []*felt.Felt{utils.TestHexToFelt(t, "0x1")}}
+
+	account, mock := newMockRPCAccount(t, AccountVersion3)
+	mock.nonce = 2
+	mock.classHash = "0x4e70b19333ae94bd958625f7b61ce9eec631653597e68645e13780061b2136c"
+	if _, err := account.DeclareV2(context.Background(), class, casm, types.ExecuteDetails{}); err == nil {
+		t.Fatal("declare v3 without resource bounds should fail")
+	}
+	resp, err := account.DeclareV2(context.Background(), class, casm, types.ExecuteDetails{ResourceBounds: &testResourceBounds})
+	if err != nil {
+		t.Fatal("declare should succeed, instead:", err)
+	}
+	if resp.Code != "TRANSACTION_RECEIVED" || resp.ClassHash != mock.classHash {
+		t.Fatalf("unexpected response %+v", resp)
+	}
+	var tx rpc.BroadcastedDeclareTransactionV3
+	mock.lastReceived(t, &tx)
+	if tx.Version != rpc.TransactionV3 || tx.Type != "DECLARE" || tx.Nonce.String() != "0x2" {
+		t.Fatalf("unexpected declare version %s, type %s or nonce %s", tx.Version, tx.Type, tx.Nonce)
+	}
+	data := append(
+		testV3HashData(t, "declare", account.AccountAddress, tx.BroadcastedTxnV3CommonProperties, 0),
+		crypto.PoseidonArray(tx.AccountDeploymentData...),
+		utils.TestHexToFelt(t, mock.classHash),
+		tx.CompiledClassHash,
+	)
+	hash := crypto.PoseidonArray(data...)
+	verifyMockSignature(t, hash.BigInt(big.NewInt(0)), tx.Signature)
+}
+
+func TestRPCAccount_DeployAccountV3(t *testing.T) {
+	classHash := utils.TestHexToFelt(t, "0x2794ce20e5f2ff0d40e632cb53845b9f4e526ebd8471983f7dbd355b721d5a")
+	salt := utils.TestHexToFelt(t, "0x4b2a5ce2f6c9d2d3ae4f3cd1f0b7b2b6a6bd0b0ae1ec8bb0a3c6b1cfa1a7b2c")
+	calldata := []*felt.Felt{salt}
+	address, err := PrecomputeAccountAddress(salt, classHash, calldata)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	account, mock := newMockRPCAccount(t, AccountVersion3)
+	account.AccountAddress = address
+	resp, err := account.DeployAccount(context.Background(), classHash, salt, calldata, types.ExecuteDetails{
+		ResourceBounds:            &testResourceBounds,
+		NonceDataAvailabilityMode: types.DAModeL2,
+	})
+	if err != nil {
+		t.Fatal("deploy account should succeed, instead:", err)
+	}
+	if resp.ContractAddress != address.String() {
+		t.Fatalf("unexpected response %+v", resp)
+	}
+	var tx rpc.BroadcastedDeployAccountTransactionV3
+	mock.lastReceived(t, &tx)
+	if tx.Version != rpc.TransactionV3 || tx.Type != "DEPLOY_ACCOUNT" || tx.Nonce.String() != "0x0" {
+		t.Fatalf("unexpected deploy account version %s, type %s or nonce %s", tx.Version, tx.Type, tx.Nonce)
+	}
+	data := append(
+		testV3HashData(t, "deploy_account", address, tx.BroadcastedTxnV3CommonProperties, 1<<32),
+		crypto.PoseidonArray(tx.ConstructorCalldata...),
+		tx.ClassHash,
+		tx.ContractAddressSalt,
+	)
+	hash := crypto.PoseidonArray(data...)
+	verifyMockSignature(t, hash.BigInt(big.NewInt(0)), tx.Signature)
+}
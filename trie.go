@@ -0,0 +1,235 @@
+package starknetgo
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/sjxqqq/starknet-go/rpc"
+)
+
+// TrieHeight is the height of the contracts, storage and classes tries of
+// Starknet, whose keys are below 2^251.
+const TrieHeight = 251
+
+var ErrInvalidProof = errors.New("invalid merkle proof")
+
+// TrieHash hashes the children of the nodes of a trie.
+type TrieHash func(x, y *felt.Felt) *felt.Felt
+
+// PedersenTrieHash is the Pedersen hash of the nodes of the contracts and
+// storage tries. PoseidonHash is the hash of the classes trie.
+func PedersenTrieHash(x, y *felt.Felt) *felt.Felt {
+	hash, err := Curve.PedersenHash([]*big.Int{x.BigInt(new(big.Int)), y.BigInt(new(big.Int))})
+	if err != nil {
+		// felts are always in the field
+		panic(err)
+	}
+	return new(felt.Felt).SetBytes(hash.Bytes())
+}
+
+// TrieNodeHash returns the hash of node: H(left, right) for a binary node and
+// H(child, path) + length for an edge node.
+func TrieNodeHash(node rpc.MerkleNode, hash TrieHash) (*felt.Felt, error) {
+	switch {
+	case node.Binary != nil:
+		return hash(node.Binary.Left, node.Binary.Right), nil
+	case node.Edge != nil:
+		length := new(felt.Felt).SetUint64(node.Edge.Length)
+		return length.Add(length, hash(node.Edge.Child, node.Edge.Path)), nil
+	}
+	return nil, fmt.Errorf("%w: empty node", ErrInvalidProof)
+}
+
+/*
+Trie is an in-memory binary Merkle-Patricia trie of height TrieHeight, as the
+tries of the Starknet state: paths without siblings are compressed in edge
+nodes and the leaves hold the values of the keys.
+
+(ref: https://docs.starknet.io/architecture-and-concepts/network-architecture/starknet-state/)
+*/
+type Trie struct {
+	hash   TrieHash
+	leaves map[felt.Felt]*felt.Felt
+}
+
+// NewTrie returns an empty trie hashing its nodes with hash.
+func NewTrie(hash TrieHash) *Trie {
+	return &Trie{
+		hash:   hash,
+		leaves: map[felt.Felt]*felt.Felt{},
+	}
+}
+
+// Put sets the value of key. A zero value removes the key from the trie.
+func (t *Trie) Put(key, value *felt.Felt) error {
+	if key.BigInt(new(big.Int)).BitLen() > TrieHeight {
+		return fmt.Errorf("key %s is out of the trie", key)
+	}
+	if value.IsZero() {
+		delete(t.leaves, *key)
+		return nil
+	}
+	t.leaves[*key] = new(felt.Felt).Set(value)
+	return nil
+}
+
+// Get returns the value of key, zero when the key is not in the trie.
+func (t *Trie) Get(key *felt.Felt) *felt.Felt {
+	if value, ok := t.leaves[*key]; ok {
+		return new(felt.Felt).Set(value)
+	}
+	return new(felt.Felt)
+}
+
+// Root returns the hash of the root of the trie, zero for an empty trie.
+func (t *Trie) Root() *felt.Felt {
+	return t.build(nil, nil)
+}
+
+// Proof returns the nodes from the root to the leaf of key, or to the node
+// proving key is not in the trie. They are verified by VerifyTrieProof.
+func (t *Trie) Proof(key *felt.Felt) []rpc.MerkleNode {
+	proof := []rpc.MerkleNode{}
+	t.build(key.BigInt(new(big.Int)), &proof)
+	return proof
+}
+
+// build returns the root of the trie, adding the nodes of the path of target
+// to proof when it is not nil.
+func (t *Trie) build(target *big.Int, proof *[]rpc.MerkleNode) *felt.Felt {
+	if len(t.leaves) == 0 {
+		return new(felt.Felt)
+	}
+	keys := make([]felt.Felt, 0, len(t.leaves))
+	for key := range t.leaves {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].Cmp(&keys[j]) < 0 })
+	bits := make([]*big.Int, len(keys))
+	values := make([]*felt.Felt, len(keys))
+	for i := range keys {
+		bits[i] = keys[i].BigInt(new(big.Int))
+		values[i] = t.leaves[keys[i]]
+	}
+	return t.node(bits, values, TrieHeight, target, proof)
+}
+
+// node returns the hash of the subtrie of the sorted keys sharing their bits
+// above height.
+func (t *Trie) node(keys []*big.Int, values []*felt.Felt, height uint, target *big.Int, proof *[]rpc.MerkleNode) *felt.Felt {
+	if height == 0 {
+		return values[0]
+	}
+	// the common bits of the first and last keys are common to all of them
+	first, last := keys[0], keys[len(keys)-1]
+	common := uint(0)
+	for common < height && first.Bit(int(height-1-common)) == last.Bit(int(height-1-common)) {
+		common++
+	}
+
+	position := -1
+	if proof != nil && target != nil {
+		position = len(*proof)
+		*proof = append(*proof, rpc.MerkleNode{})
+	}
+
+	if common > 0 {
+		path := new(big.Int).Rsh(first, height-common)
+		path.And(path, new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), common), big.NewInt(1)))
+		childTarget := target
+		if target != nil {
+			targetPath := new(big.Int).Rsh(target, height-common)
+			targetPath.And(targetPath, new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), common), big.NewInt(1)))
+			if targetPath.Cmp(path) != 0 {
+				// the edge proves target is not in the trie
+				childTarget = nil
+			}
+		}
+		edge := &rpc.EdgeNode{
+			Path:   new(felt.Felt).SetBytes(path.Bytes()),
+			Length: uint64(common),
+			Child:  t.node(keys, values, height-common, childTarget, proof),
+		}
+		if position >= 0 {
+			(*proof)[position] = rpc.MerkleNode{Edge: edge}
+		}
+		hash, _ := TrieNodeHash(rpc.MerkleNode{Edge: edge}, t.hash)
+		return hash
+	}
+
+	split := sort.Search(len(keys), func(i int) bool { return keys[i].Bit(int(height-1)) == 1 })
+	var leftTarget, rightTarget *big.Int
+	if target != nil {
+		if target.Bit(int(height-1)) == 0 {
+			leftTarget = target
+		} else {
+			rightTarget = target
+		}
+	}
+	binary := &rpc.BinaryNode{
+		Left:  t.node(keys[:split], values[:split], height-1, leftTarget, proof),
+		Right: t.node(keys[split:], values[split:], height-1, rightTarget, proof),
+	}
+	if position >= 0 {
+		(*proof)[position] = rpc.MerkleNode{Binary: binary}
+	}
+	return t.hash(binary.Left, binary.Right)
+}
+
+/*
+Verifies the proof of key in the trie of root and returns the value of key,
+zero when the proof shows key is not in the trie. The nodes can be in any
+order and include the nodes of other keys, as the nodes of
+starknet_getStorageProof.
+*/
+func VerifyTrieProof(root, key *felt.Felt, nodes []rpc.MerkleNode, hash TrieHash) (*felt.Felt, error) {
+	bits := key.BigInt(new(big.Int))
+	if bits.BitLen() > TrieHeight {
+		return nil, fmt.Errorf("key %s is out of the trie", key)
+	}
+	if root.IsZero() {
+		return new(felt.Felt), nil
+	}
+	byHash := make(map[felt.Felt]rpc.MerkleNode, len(nodes))
+	for _, node := range nodes {
+		nodeHash, err := TrieNodeHash(node, hash)
+		if err != nil {
+			return nil, err
+		}
+		byHash[*nodeHash] = node
+	}
+
+	current := root
+	height := uint64(TrieHeight)
+	for height > 0 {
+		node, ok := byHash[*current]
+		if !ok {
+			return nil, fmt.Errorf("%w: missing node %s", ErrInvalidProof, current)
+		}
+		if node.Binary != nil {
+			height--
+			if bits.Bit(int(height)) == 0 {
+				current = node.Binary.Left
+			} else {
+				current = node.Binary.Right
+			}
+			continue
+		}
+		edge := node.Edge
+		path := edge.Path.BigInt(new(big.Int))
+		if edge.Length == 0 || edge.Length > height || uint64(path.BitLen()) > edge.Length {
+			return nil, fmt.Errorf("%w: invalid edge at height %d", ErrInvalidProof, height)
+		}
+		height -= edge.Length
+		keyPath := new(big.Int).Rsh(bits, uint(height))
+		keyPath.And(keyPath, new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), uint(edge.Length)), big.NewInt(1)))
+		if keyPath.Cmp(path) != 0 {
+			return new(felt.Felt), nil
+		}
+		current = edge.Child
+	}
+	return new(felt.Felt).Set(current), nil
+}
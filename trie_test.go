@@ -0,0 +1,123 @@
+package starknetgo
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/NethermindEth/juno/core/felt"
+	"github.com/NethermindEth/juno/core/trie"
+	"github.com/sjxqqq/starknet-go/utils"
+)
+
+// TestTrie_Root checks the roots of the trie against the tries of juno.
+func TestTrie_Root(t *testing.T) {
+	type testSetType struct {
+		Keys []string
+	}
+	testSet := map[string][]testSetType{
+		"mock": {
+			{Keys: []string{}},
+			{Keys: []string{"0x1"}},
+			{Keys: []string{"0x0", "0x1"}},
+			{Keys: []string{"0x1", "0x2", "0x3", "0x7ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff"}},
+			{Keys: []string{"0x5", "0x500", "0x50000", "0x3a8c1e", "0x49d36570d4e46f48e99674bd3fcc84644ddd6b96f7c741b1562b82f9e004dc7"}},
+		},
+	}[testEnv]
+
+	for _, test := range testSet {
+		keys := utils.TestHexArrToFelt(t, test.Keys)
+		local := NewTrie(PedersenTrieHash)
+		var expected *felt.Felt
+		err := trie.RunOnTempTrie(TrieHeight, func(reference *trie.Trie) error {
+			for i, key := range keys {
+				value := new(felt.Felt).SetUint64(uint64(100 + i))
+				if err := local.Put(key, value); err != nil {
+					return err
+				}
+				if _, err := reference.Put(key, value); err != nil {
+					return err
+				}
+			}
+			root, err := reference.Root()
+			expected = root
+			return err
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if root := local.Root(); !root.Equal(expected) {
+			t.Fatalf("keys %v: expected root %s, got %s", test.Keys, expected, root)
+		}
+	}
+}
+
+// TestTrie_Proof checks the proofs of the keys in and out of the trie.
+func TestTrie_Proof(t *testing.T) {
+	type testSetType struct {
+		Hash          TrieHash
+		Key           string
+		ExpectedValue string
+	}
+	testSet := map[string][]testSetType{
+		"mock": {
+			{Hash: PedersenTrieHash, Key: "0x1", ExpectedValue: "0x64"},
+			{Hash: PedersenTrieHash, Key: "0x50000", ExpectedValue: "0x67"},
+			{Hash: PedersenTrieHash, Key: "0x4", ExpectedValue: "0x0"},
+			{Hash: PedersenTrieHash, Key: "0x7ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff", ExpectedValue: "0x0"},
+			{Hash: PoseidonHash, Key: "0x500", ExpectedValue: "0x66"},
+			{Hash: PoseidonHash, Key: "0x501", ExpectedValue: "0x0"},
+		},
+	}[testEnv]
+
+	for _, test := range testSet {
+		local := NewTrie(test.Hash)
+		for i, key := range []string{"0x1", "0x5", "0x500", "0x50000"} {
+			if err := local.Put(utils.TestHexToFelt(t, key), new(felt.Felt).SetUint64(uint64(100+i))); err != nil {
+				t.Fatal(err)
+			}
+		}
+		root := local.Root()
+		key := utils.TestHexToFelt(t, test.Key)
+		proof := local.Proof(key)
+		value, err := VerifyTrieProof(root, key, proof, test.Hash)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if value.String() != test.ExpectedValue {
+			t.Fatalf("key %s: expected %s, got %s", test.Key, test.ExpectedValue, value)
+		}
+
+		// a tampered proof no longer leads from the root
+		if proof[len(proof)-1].Binary != nil {
+			proof[len(proof)-1].Binary.Left = new(felt.Felt).SetUint64(0xbad)
+		} else {
+			proof[len(proof)-1].Edge.Child = new(felt.Felt).SetUint64(0xbad)
+		}
+		if _, err := VerifyTrieProof(root, key, proof, test.Hash); !errors.Is(err, ErrInvalidProof) {
+			t.Fatalf("key %s: expected an invalid proof, got %v", test.Key, err)
+		}
+	}
+}
+
+// TestTrie_Put checks the removal of keys and the keys out of the trie.
+func TestTrie_Put(t *testing.T) {
+	local := NewTrie(PedersenTrieHash)
+	key := utils.TestHexToFelt(t, "0x7")
+	if err := local.Put(key, new(felt.Felt).SetUint64(1)); err != nil {
+		t.Fatal(err)
+	}
+	if value := local.Get(key); !value.Equal(new(felt.Felt).SetUint64(1)) {
+		t.Fatalf("expected 1, got %s", value)
+	}
+	if err := local.Put(key, new(felt.Felt)); err != nil {
+		t.Fatal(err)
+	}
+	if root := local.Root(); !root.IsZero() {
+		t.Fatalf("expected an empty trie, got root %s", root)
+	}
+	outside := new(felt.Felt).SetBytes(new(big.Int).Lsh(big.NewInt(1), TrieHeight).Bytes())
+	if err := local.Put(outside, new(felt.Felt).SetUint64(1)); err == nil {
+		t.Fatal("expected an error for a key out of the trie")
+	}
+}
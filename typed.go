@@ -3,14 +3,65 @@ package starknetgo
 import (
 	"bytes"
 	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"math/big"
+	"reflect"
 	"regexp"
+	"sort"
+	"strings"
 
 	"github.com/NethermindEth/juno/core/felt"
 	"github.com/sjxqqq/starknet-go/types"
 )
 
+// TypedDataRevision is the revision of SNIP-12 of a TypedData.
+type TypedDataRevision int
+
+const (
+	// TypedDataRevisionLegacy is the revision 0, with a StarkNetDomain hashed
+	// with Pedersen.
+	TypedDataRevisionLegacy TypedDataRevision = 0
+	// TypedDataRevisionActive is the revision 1, with a StarknetDomain hashed
+	// with Poseidon.
+	TypedDataRevisionActive TypedDataRevision = 1
+)
+
+var (
+	// typedDataDomainTypes are the types of the domain of each revision.
+	typedDataDomainTypes = map[TypedDataRevision]string{
+		TypedDataRevisionLegacy: "StarkNetDomain",
+		TypedDataRevisionActive: "StarknetDomain",
+	}
+	// typedDataPresetTypes are the types of revision 1 available without
+	// being defined.
+	typedDataPresetTypes = map[string]TypeDef{
+		"u256": {Definitions: []Definition{
+			{Name: "low", Type: "u128"},
+			{Name: "high", Type: "u128"},
+		}},
+		"TokenAmount": {Definitions: []Definition{
+			{Name: "token_address", Type: "ContractAddress"},
+			{Name: "amount", Type: "u256"},
+		}},
+		"NftId": {Definitions: []Definition{
+			{Name: "collection_address", Type: "ContractAddress"},
+			{Name: "token_id", Type: "u256"},
+		}},
+	}
+	typedDataU128Max = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 128), big.NewInt(1))
+	typedDataI128Max = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 127), big.NewInt(1))
+	typedDataI128Min = new(big.Int).Neg(new(big.Int).Lsh(big.NewInt(1), 127))
+	// hexSelectorRegexp matches the selectors given as hex values instead of
+	// entry point names.
+	hexSelectorRegexp = regexp.MustCompile(`^0[xX][0-9a-fA-F]*$`)
+	// typedDataHexRegexp and typedDataDecimalRegexp match the strings of
+	// typed data read as numbers.
+	typedDataHexRegexp     = regexp.MustCompile(`^0[xX][0-9a-fA-F]+$`)
+	typedDataDecimalRegexp = regexp.MustCompile(`^-?[0-9]+$`)
+)
+
 type TypedData struct {
 	Types       map[string]TypeDef
 	PrimaryType string
@@ -19,9 +70,12 @@ type TypedData struct {
 }
 
 type Domain struct {
-	Name    string
-	Version string
-	ChainId string
+	Name    string `json:"name,omitempty"`
+	Version string `json:"version,omitempty"`
+	ChainId string `json:"chainId,omitempty"`
+	// Revision is "1" for the revision 1 of SNIP-12 and empty or "0" for the
+	// legacy revision.
+	Revision string `json:"revision,omitempty"`
 }
 
 type TypeDef struct {
@@ -30,14 +84,31 @@ type TypeDef struct {
 }
 
 type Definition struct {
-	Name string
-	Type string
+	Name string `json:"name"`
+	Type string `json:"type"`
+	// Contains is the type of the variants of an enum or of the leaves of a
+	// merkletree.
+	Contains string `json:"contains,omitempty"`
 }
 
 type TypedMessage interface {
 	FmtDefinitionEncoding(string) []*big.Int
 }
 
+// TypedDataMessage is a message of any type of a TypedData, as decoded from
+// JSON: structs and enums are maps of their fields or variants, arrays are
+// slices and the other values are numbers, strings or booleans.
+type TypedDataMessage map[string]interface{}
+
+// FmtDefinitionEncoding returns the value of field when it is a single
+// element.
+func (msg TypedDataMessage) FmtDefinitionEncoding(field string) (fmtEnc []*big.Int) {
+	if value, err := typedDataNumber(msg[field]); err == nil {
+		fmtEnc = append(fmtEnc, value)
+	}
+	return fmtEnc
+}
+
 /*
 encoding definition for standard Starknet Domain messages
 */
@@ -57,13 +128,25 @@ func (dm Domain) FmtDefinitionEncoding(field string) (fmtEnc []*big.Int) {
 		processStrToBig(dm.Version)
 	case "chainId":
 		processStrToBig(dm.ChainId)
+	case "revision":
+		processStrToBig(dm.Revision)
 	}
 	return fmtEnc
 }
 
+// message returns the fields of the domain.
+func (dm Domain) message() TypedDataMessage {
+	return TypedDataMessage{
+		"name":     dm.Name,
+		"version":  dm.Version,
+		"chainId":  dm.ChainId,
+		"revision": dm.Revision,
+	}
+}
+
 // strToFelt converts a string containing a decimal, hexadecimal or UTF8 charset into a Felt.
 func strToFelt(str string) *felt.Felt {
-	var f = &felt.Zero
+	var f = new(felt.Felt)
 	asciiRegexp := regexp.MustCompile(`^([[:graph:]]|[[:space:]]){1,31}$`)
 
 	if b, ok := new(big.Int).SetString(str, 0); ok {
@@ -99,11 +182,14 @@ func NewTypedData(types map[string]TypeDef, pType string, dom Domain) (td TypedD
 	if _, ok := td.Types[pType]; !ok {
 		return td, fmt.Errorf("invalid primary type: %s", pType)
 	}
+	if _, err := td.Revision(); err != nil {
+		return td, err
+	}
 
 	for k, v := range td.Types {
 		enc, err := td.GetTypeHash(k)
 		if err != nil {
-			return td, fmt.Errorf("error encoding type hash: %s %w", enc.String(), err)
+			return td, fmt.Errorf("error encoding type hash: %s %w", k, err)
 		}
 		v.Encoding = enc
 		td.Types[k] = v
@@ -111,11 +197,133 @@ func NewTypedData(types map[string]TypeDef, pType string, dom Domain) (td TypedD
 	return td, nil
 }
 
-// (ref: https://github.com/0xs34n/starknet.js/blob/767021a203ac0b9cdb282eb6d63b33bfd7614858/src/utils/typedData/index.ts#L166)
+type typedDataJSON struct {
+	Types       map[string][]Definition `json:"types"`
+	PrimaryType string                  `json:"primaryType"`
+	Domain      Domain                  `json:"domain"`
+	Message     TypedMessage            `json:"message,omitempty"`
+}
+
+// MarshalJSON encodes td in the JSON of starknet.js.
+func (td TypedData) MarshalJSON() ([]byte, error) {
+	raw := typedDataJSON{
+		Types:       make(map[string][]Definition, len(td.Types)),
+		PrimaryType: td.PrimaryType,
+		Domain:      td.Domain,
+		Message:     td.Message,
+	}
+	for name, typeDef := range td.Types {
+		raw.Types[name] = typeDef.Definitions
+	}
+	return json.Marshal(raw)
+}
+
+// UnmarshalJSON decodes the types, primaryType, domain and message of the
+// JSON of starknet.js. The message is a TypedDataMessage.
+func (td *TypedData) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Types       map[string][]Definition `json:"types"`
+		PrimaryType string                  `json:"primaryType"`
+		Domain      Domain                  `json:"domain"`
+		Message     json.RawMessage         `json:"message"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	typeDefs := make(map[string]TypeDef, len(raw.Types))
+	for name, definitions := range raw.Types {
+		typeDefs[name] = TypeDef{Definitions: definitions}
+	}
+	typedData, err := NewTypedData(typeDefs, raw.PrimaryType, raw.Domain)
+	if err != nil {
+		return err
+	}
+	if len(raw.Message) > 0 && string(raw.Message) != "null" {
+		decoder := json.NewDecoder(bytes.NewReader(raw.Message))
+		decoder.UseNumber()
+		var message TypedDataMessage
+		if err := decoder.Decode(&message); err != nil {
+			return err
+		}
+		typedData.Message = message
+	}
+	*td = typedData
+	return nil
+}
+
+// UnmarshalJSON decodes the fields of the domain from strings or numbers.
+func (dm *Domain) UnmarshalJSON(data []byte) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	fields := map[string]*string{
+		"name":     &dm.Name,
+		"version":  &dm.Version,
+		"chainId":  &dm.ChainId,
+		"revision": &dm.Revision,
+	}
+	for name, field := range fields {
+		value, ok := raw[name]
+		if !ok {
+			continue
+		}
+		var str string
+		if err := json.Unmarshal(value, &str); err == nil {
+			*field = str
+			continue
+		}
+		var number json.Number
+		if err := json.Unmarshal(value, &number); err != nil {
+			return fmt.Errorf("invalid domain %s: %s", name, value)
+		}
+		*field = number.String()
+	}
+	return nil
+}
+
+// Revision returns the SNIP-12 revision of td, from its domain type and the
+// revision of its domain.
+func (td TypedData) Revision() (TypedDataRevision, error) {
+	revision := strings.TrimSpace(td.Domain.Revision)
+	if _, ok := td.Types[typedDataDomainTypes[TypedDataRevisionActive]]; ok && revision == "1" {
+		return TypedDataRevisionActive, nil
+	}
+	if _, ok := td.Types[typedDataDomainTypes[TypedDataRevisionLegacy]]; ok && (revision == "" || revision == "0") {
+		return TypedDataRevisionLegacy, nil
+	}
+	return 0, fmt.Errorf("invalid typed data revision %q: the domain type must be StarkNetDomain for revision 0 and StarknetDomain for revision 1", td.Domain.Revision)
+}
+
+// DomainType returns the type of the domain of td.
+func (td TypedData) DomainType() (string, error) {
+	revision, err := td.Revision()
+	if err != nil {
+		return "", err
+	}
+	return typedDataDomainTypes[revision], nil
+}
+
+/*
+Returns the hash of msg signed by account. A nil msg is the Message of td, as
+decoded from JSON.
+
+(ref: https://github.com/0xs34n/starknet.js/blob/767021a203ac0b9cdb282eb6d63b33bfd7614858/src/utils/typedData/index.ts#L166)
+*/
 func (td TypedData) GetMessageHash(account *big.Int, msg TypedMessage, sc StarkCurve) (hash *big.Int, err error) {
-	elements := []*big.Int{types.UTF8StrToBig("Starknet Message")}
+	if msg == nil {
+		msg = td.Message
+	}
+	if msg == nil {
+		return hash, errors.New("typed data without message")
+	}
+	revision, err := td.Revision()
+	if err != nil {
+		return hash, err
+	}
+	elements := []*big.Int{types.UTF8StrToBig("StarkNet Message")}
 
-	domEnc, err := td.GetTypedMessageHash("StarknetDomain", td.Domain, sc)
+	domEnc, err := td.GetTypedMessageHash(typedDataDomainTypes[revision], td.Domain, sc)
 	if err != nil {
 		return hash, fmt.Errorf("could not hash domain: %w", err)
 	}
@@ -128,37 +336,67 @@ func (td TypedData) GetMessageHash(account *big.Int, msg TypedMessage, sc StarkC
 	}
 
 	elements = append(elements, msgEnc)
-	hash, err = sc.ComputeHashOnElements(elements)
-	return hash, err
+	return td.hashElements(revision, elements, sc)
 }
 
+// GetTypedMessageHash returns the struct hash of msg of type inType. The
+// domain and TypedDataMessage are encoded with all the types of their
+// revision, other messages encode their felt fields and the fields of their
+// structs in order.
 func (td TypedData) GetTypedMessageHash(inType string, msg TypedMessage, sc StarkCurve) (hash *big.Int, err error) {
+	switch msg := msg.(type) {
+	case Domain:
+		return td.GetStructHash(inType, msg.message(), sc)
+	case TypedDataMessage:
+		return td.GetStructHash(inType, msg, sc)
+	}
+	revision, err := td.Revision()
+	if err != nil {
+		return hash, err
+	}
 	prim := td.Types[inType]
-	elements := []*big.Int{prim.Encoding}
+	typeHash, err := td.GetTypeHash(inType)
+	if err != nil {
+		return hash, err
+	}
+	elements := []*big.Int{typeHash}
 
 	for _, def := range prim.Definitions {
-		if def.Type == "felt" {
+		encType, ok := td.typeDefinition(revision, def.Type)
+		if !ok {
 			fmtDefinitions := msg.FmtDefinitionEncoding(def.Name)
 			elements = append(elements, fmtDefinitions...)
 			continue
 		}
 
-		innerElements := []*big.Int{}
-		encType := td.Types[def.Type]
-		innerElements = append(innerElements, encType.Encoding)
-		fmtDefinitions := msg.FmtDefinitionEncoding(def.Name)
-		innerElements = append(innerElements, fmtDefinitions...)
-		innerElements = append(innerElements, big.NewInt(int64(len(innerElements))))
+		innerTypeHash, err := td.GetTypeHash(def.Type)
+		if err != nil {
+			return hash, err
+		}
+		innerElements := []*big.Int{innerTypeHash}
+		innerElements = append(innerElements, msg.FmtDefinitionEncoding(def.Name)...)
+		if len(innerElements) != len(encType.Definitions)+1 {
+			return hash, fmt.Errorf("expected %d elements for %s, got %d", len(encType.Definitions), def.Name, len(innerElements)-1)
+		}
 
-		innerHash, err := sc.HashElements(innerElements)
+		innerHash, err := td.hashElements(revision, innerElements, sc)
 		if err != nil {
 			return hash, fmt.Errorf("error hashing internal elements: %v %w", innerElements, err)
 		}
 		elements = append(elements, innerHash)
 	}
 
-	hash, err = sc.ComputeHashOnElements(elements)
-	return hash, err
+	return td.hashElements(revision, elements, sc)
+}
+
+// GetStructHash returns the hash of the type hash of inType followed by the
+// encoded values of the fields of data.
+func (td TypedData) GetStructHash(inType string, data TypedDataMessage, sc StarkCurve) (*big.Int, error) {
+	revision, err := td.Revision()
+	if err != nil {
+		return nil, err
+	}
+	return td.structHash(revision, td.Types, inType, data, sc)
 }
 
 func (td TypedData) GetTypeHash(inType string) (ret *big.Int, err error) {
@@ -170,36 +408,76 @@ func (td TypedData) GetTypeHash(inType string) (ret *big.Int, err error) {
 	return sel, nil
 }
 
+/*
+Encodes inType with the types it depends on, sorted by name after it. The
+names and types are quoted in revision 1, where the types of the enums are
+the type they contain.
+
+(ref: https://github.com/starknet-io/SNIPs/blob/main/SNIPS/snip-12.md)
+*/
 func (td TypedData) EncodeType(inType string) (enc string, err error) {
-	var typeDefs TypeDef
-	var ok bool
-	if typeDefs, ok = td.Types[inType]; !ok {
-		return enc, fmt.Errorf("can't parse type %s from types %v", inType, td.Types)
+	revision, err := td.Revision()
+	if err != nil {
+		return enc, err
 	}
-	var buf bytes.Buffer
-	customTypes := make(map[string]TypeDef)
-	buf.WriteString(inType)
-	buf.WriteString("(")
-	for i, def := range typeDefs.Definitions {
-		if def.Type != "felt" {
-			var customTypeDef TypeDef
-			if customTypeDef, ok = td.Types[def.Type]; !ok {
-				return enc, fmt.Errorf("can't parse type %s from types %v", def.Type, td.Types)
+	return encodeTypedDataType(td.allTypes(revision), inType, revision)
+}
+
+func encodeTypedDataType(allTypes map[string]TypeDef, inType string, revision TypedDataRevision) (string, error) {
+	if _, ok := allTypes[strings.TrimSuffix(inType, "*")]; !ok {
+		return "", fmt.Errorf("can't parse type %s from types %v", inType, allTypes)
+	}
+	dependencies := []string{}
+	seen := map[string]bool{}
+	var visit func(typeName, contains string) error
+	visit = func(typeName, contains string) error {
+		for _, dependency := range typedDataDependencyTypes(typeName, contains, revision) {
+			typeDef, ok := allTypes[dependency]
+			if !ok || seen[dependency] {
+				continue
+			}
+			seen[dependency] = true
+			dependencies = append(dependencies, dependency)
+			for _, def := range typeDef.Definitions {
+				if err := visit(def.Type, def.Contains); err != nil {
+					return err
+				}
 			}
-			customTypes[def.Type] = customTypeDef
-		}
-		buf.WriteString(fmt.Sprintf("%s:%s", def.Name, def.Type))
-		if i != (len(typeDefs.Definitions) - 1) {
-			buf.WriteString(",")
 		}
+		return nil
 	}
-	buf.WriteString(")")
+	if err := visit(inType, ""); err != nil {
+		return "", err
+	}
+	sort.Strings(dependencies[1:])
 
-	for customTypeName, customType := range customTypes {
-		buf.WriteString(fmt.Sprintf("%s(", customTypeName))
-		for i, def := range customType.Definitions {
-			buf.WriteString(fmt.Sprintf("%s:%s", def.Name, def.Type))
-			if i != (len(customType.Definitions) - 1) {
+	escape := func(s string) string {
+		if revision == TypedDataRevisionActive {
+			return `"` + s + `"`
+		}
+		return s
+	}
+	var buf bytes.Buffer
+	for _, dependency := range dependencies {
+		buf.WriteString(escape(dependency))
+		buf.WriteString("(")
+		for i, def := range allTypes[dependency].Definitions {
+			targetType := def.Type
+			if def.Type == "enum" && revision == TypedDataRevisionActive {
+				targetType = def.Contains
+			}
+			typeString := escape(targetType)
+			if isTypedDataTuple(targetType) {
+				elems := strings.Split(targetType[1:len(targetType)-1], ",")
+				for j, elem := range elems {
+					if elem != "" {
+						elems[j] = escape(elem)
+					}
+				}
+				typeString = "(" + strings.Join(elems, ",") + ")"
+			}
+			buf.WriteString(fmt.Sprintf("%s:%s", escape(def.Name), typeString))
+			if i != len(allTypes[dependency].Definitions)-1 {
 				buf.WriteString(",")
 			}
 		}
@@ -207,3 +485,419 @@ func (td TypedData) EncodeType(inType string) (enc string, err error) {
 	}
 	return buf.String(), nil
 }
+
+// typedDataDependencyTypes returns the types a field of type typeName
+// depends on.
+func typedDataDependencyTypes(typeName, contains string, revision TypedDataRevision) []string {
+	switch {
+	case strings.HasSuffix(typeName, "*"):
+		return []string{strings.TrimSuffix(typeName, "*")}
+	case revision == TypedDataRevisionActive && typeName == "enum":
+		return []string{contains}
+	case revision == TypedDataRevisionActive && isTypedDataTuple(typeName):
+		elems := strings.Split(typeName[1:len(typeName)-1], ",")
+		for i, elem := range elems {
+			elems[i] = strings.TrimSuffix(elem, "*")
+		}
+		return elems
+	}
+	return []string{typeName}
+}
+
+func isTypedDataTuple(typeName string) bool {
+	return len(typeName) >= 2 && typeName[0] == '(' && typeName[len(typeName)-1] == ')'
+}
+
+// allTypes returns the types of td with the preset types of revision.
+func (td TypedData) allTypes(revision TypedDataRevision) map[string]TypeDef {
+	if revision != TypedDataRevisionActive {
+		return td.Types
+	}
+	allTypes := make(map[string]TypeDef, len(td.Types)+len(typedDataPresetTypes))
+	for name, typeDef := range td.Types {
+		allTypes[name] = typeDef
+	}
+	for name, typeDef := range typedDataPresetTypes {
+		allTypes[name] = typeDef
+	}
+	return allTypes
+}
+
+// typeDefinition returns the struct type typeName of td.
+func (td TypedData) typeDefinition(revision TypedDataRevision, typeName string) (TypeDef, bool) {
+	typeDef, ok := td.allTypes(revision)[typeName]
+	return typeDef, ok
+}
+
+// hashElements hashes elements with the hash of revision.
+func (td TypedData) hashElements(revision TypedDataRevision, elements []*big.Int, sc StarkCurve) (*big.Int, error) {
+	if revision == TypedDataRevisionActive {
+		return sc.PoseidonHashMany(elements)
+	}
+	return sc.ComputeHashOnElements(append([]*big.Int{}, elements...))
+}
+
+// hashMerkleNodes hashes the sorted pair of nodes of a merkletree.
+func (td TypedData) hashMerkleNodes(revision TypedDataRevision, x, y *big.Int, sc StarkCurve) (*big.Int, error) {
+	if x.Cmp(y) > 0 {
+		x, y = y, x
+	}
+	if revision == TypedDataRevisionActive {
+		return sc.PoseidonHash(x, y)
+	}
+	return sc.PedersenHash([]*big.Int{x, y})
+}
+
+func (td TypedData) structHash(revision TypedDataRevision, typeDefs map[string]TypeDef, inType string, data TypedDataMessage, sc StarkCurve) (*big.Int, error) {
+	typeDef, ok := typeDefs[inType]
+	if !ok {
+		return nil, fmt.Errorf("can't parse type %s from types %v", inType, typeDefs)
+	}
+	typeEnc, err := encodeTypedDataType(td.allTypes(revision), inType, revision)
+	if err != nil {
+		return nil, err
+	}
+	elements := []*big.Int{types.GetSelectorFromName(typeEnc)}
+	for _, def := range typeDef.Definitions {
+		value, ok := data[def.Name]
+		if !ok || (value == nil && def.Type != "enum") {
+			return nil, fmt.Errorf("cannot encode data: missing data for '%s'", def.Name)
+		}
+		encoded, err := td.encodeValue(revision, typeDefs, def.Type, value, inType, def.Name, sc)
+		if err != nil {
+			return nil, fmt.Errorf("cannot encode '%s': %w", def.Name, err)
+		}
+		elements = append(elements, encoded)
+	}
+	return td.hashElements(revision, elements, sc)
+}
+
+/*
+Encodes value of type typeName, the field key of the struct parent, as
+starknet.js does.
+
+(ref: https://github.com/starknet-io/starknet.js/blob/develop/src/utils/typedData.ts)
+*/
+func (td TypedData) encodeValue(revision TypedDataRevision, typeDefs map[string]TypeDef, typeName string, value interface{}, parent, key string, sc StarkCurve) (*big.Int, error) {
+	if _, ok := typeDefs[typeName]; ok {
+		data, err := typedDataStruct(value)
+		if err != nil {
+			return nil, err
+		}
+		return td.structHash(revision, typeDefs, typeName, data, sc)
+	}
+	if _, ok := typedDataPresetTypes[typeName]; ok && revision == TypedDataRevisionActive {
+		data, err := typedDataStruct(value)
+		if err != nil {
+			return nil, err
+		}
+		return td.structHash(revision, typedDataPresetTypes, typeName, data, sc)
+	}
+	if strings.HasSuffix(typeName, "*") {
+		entries, err := typedDataList(value)
+		if err != nil {
+			return nil, err
+		}
+		hashes := make([]*big.Int, len(entries))
+		for i, entry := range entries {
+			if hashes[i], err = td.encodeValue(revision, typeDefs, strings.TrimSuffix(typeName, "*"), entry, "", "", sc); err != nil {
+				return nil, err
+			}
+		}
+		return td.hashElements(revision, hashes, sc)
+	}
+
+	switch typeName {
+	case "enum":
+		if revision == TypedDataRevisionActive {
+			return td.encodeEnum(revision, typeDefs, value, parent, key, sc)
+		}
+	case "merkletree":
+		return td.encodeMerkleTree(revision, typeDefs, value, parent, key, sc)
+	case "selector":
+		name, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("invalid selector %v", value)
+		}
+		// a hex selector is used as is, like prepareSelector of starknet.js
+		if hexSelectorRegexp.MatchString(name) {
+			selector, _ := new(big.Int).SetString("0"+name[2:], 16)
+			return selector, nil
+		}
+		return types.GetSelectorFromName(name), nil
+	case "string":
+		if revision == TypedDataRevisionActive {
+			str, ok := value.(string)
+			if !ok {
+				return nil, fmt.Errorf("invalid string %v", value)
+			}
+			return td.hashElements(revision, typedDataByteArray(str), sc)
+		}
+	case "i128":
+		if revision == TypedDataRevisionActive {
+			number, err := typedDataNumber(value)
+			if err != nil {
+				return nil, err
+			}
+			if number.Cmp(typedDataI128Min) < 0 || number.Cmp(typedDataI128Max) > 0 {
+				return nil, fmt.Errorf("%s is out of the range of i128", number)
+			}
+			if number.Sign() < 0 {
+				number.Add(number, sc.P)
+			}
+			return number, nil
+		}
+	case "timestamp", "u128":
+		if revision == TypedDataRevisionActive {
+			return typedDataNumberInRange(value, typeName, typedDataU128Max)
+		}
+	case "felt", "shortstring", "ClassHash", "ContractAddress":
+		if revision == TypedDataRevisionActive {
+			return typedDataNumberInRange(value, typeName, new(big.Int).Sub(sc.P, big.NewInt(1)))
+		}
+	case "bool":
+		if revision == TypedDataRevisionActive {
+			return typedDataNumberInRange(value, typeName, big.NewInt(1))
+		}
+	default:
+		if revision == TypedDataRevisionActive {
+			return nil, fmt.Errorf("unsupported type: %s", typeName)
+		}
+	}
+	return typedDataNumber(value)
+}
+
+// encodeEnum hashes the index of the variant of value followed by its
+// encoded values. Like starknet.js, a variant without value is hashed with a
+// 0 value.
+func (td TypedData) encodeEnum(revision TypedDataRevision, typeDefs map[string]TypeDef, value interface{}, parent, key string, sc StarkCurve) (*big.Int, error) {
+	variants, err := typedDataStruct(value)
+	if err != nil || len(variants) != 1 {
+		return nil, fmt.Errorf("invalid enum %v: expected a single variant", value)
+	}
+	contains := ""
+	for _, def := range typeDefs[parent].Definitions {
+		if def.Name == key {
+			contains = def.Contains
+		}
+	}
+	enumType, ok := typeDefs[contains]
+	if !ok {
+		return nil, fmt.Errorf("unknown enum type %q of %s", contains, key)
+	}
+	for variantKey, variantData := range variants {
+		for index, variant := range enumType.Definitions {
+			if variant.Name != variantKey {
+				continue
+			}
+			if !isTypedDataTuple(variant.Type) {
+				return nil, fmt.Errorf("invalid variant type %s of %s", variant.Type, variant.Name)
+			}
+			subtypes := strings.Split(variant.Type[1:len(variant.Type)-1], ",")
+			values := []interface{}{}
+			if variantData != nil {
+				if values, err = typedDataList(variantData); err != nil {
+					return nil, err
+				}
+			}
+			elements := []*big.Int{big.NewInt(int64(index))}
+			for i, subtype := range subtypes {
+				if subtype == "" {
+					elements = append(elements, big.NewInt(0))
+					continue
+				}
+				if i >= len(values) {
+					return nil, fmt.Errorf("missing value %d of variant %s", i, variant.Name)
+				}
+				encoded, err := td.encodeValue(revision, typeDefs, subtype, values[i], "", "", sc)
+				if err != nil {
+					return nil, err
+				}
+				elements = append(elements, encoded)
+			}
+			return td.hashElements(revision, elements, sc)
+		}
+		return nil, fmt.Errorf("unknown variant %s of enum %s", variantKey, contains)
+	}
+	return nil, errors.New("empty enum")
+}
+
+// encodeMerkleTree returns the root of the tree of the encoded leaves of
+// value, whose sorted pairs are hashed with the hash of revision.
+func (td TypedData) encodeMerkleTree(revision TypedDataRevision, typeDefs map[string]TypeDef, value interface{}, parent, key string, sc StarkCurve) (*big.Int, error) {
+	contains := "raw"
+	for _, def := range typeDefs[parent].Definitions {
+		if def.Name == key {
+			if def.Contains == "" || strings.HasSuffix(def.Contains, "*") {
+				return nil, fmt.Errorf("invalid merkletree leaves type %q of %s", def.Contains, key)
+			}
+			contains = def.Contains
+		}
+	}
+	entries, err := typedDataList(value)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, errors.New("merkletree without leaves")
+	}
+	level := make([]*big.Int, len(entries))
+	for i, entry := range entries {
+		if level[i], err = td.encodeValue(revision, typeDefs, contains, entry, "", "", sc); err != nil {
+			return nil, err
+		}
+	}
+	for len(level) > 1 {
+		next := make([]*big.Int, 0, (len(level)+1)/2)
+		for i := 0; i < len(level); i += 2 {
+			sibling := big.NewInt(0)
+			if i+1 < len(level) {
+				sibling = level[i+1]
+			}
+			hash, err := td.hashMerkleNodes(revision, level[i], sibling, sc)
+			if err != nil {
+				return nil, err
+			}
+			next = append(next, hash)
+		}
+		level = next
+	}
+	return level[0], nil
+}
+
+// typedDataByteArray returns the elements of the Cairo ByteArray of str: the
+// number of full 31 bytes words, the words, the pending word and its length.
+func typedDataByteArray(str string) []*big.Int {
+	data := []byte(str)
+	words := len(data) / 31
+	elements := []*big.Int{big.NewInt(int64(words))}
+	for i := 0; i < words; i++ {
+		elements = append(elements, new(big.Int).SetBytes(data[i*31:(i+1)*31]))
+	}
+	pending := data[words*31:]
+	return append(elements, new(big.Int).SetBytes(pending), big.NewInt(int64(len(pending))))
+}
+
+// typedDataNumber converts value to a number: strings are decimal or 0x
+// prefixed numbers, or short strings of up to 31 ASCII characters.
+func typedDataNumber(value interface{}) (*big.Int, error) {
+	switch value := value.(type) {
+	case *big.Int:
+		return new(big.Int).Set(value), nil
+	case big.Int:
+		return new(big.Int).Set(&value), nil
+	case *felt.Felt:
+		return value.BigInt(new(big.Int)), nil
+	case bool:
+		if value {
+			return big.NewInt(1), nil
+		}
+		return big.NewInt(0), nil
+	case int:
+		return big.NewInt(int64(value)), nil
+	case int64:
+		return big.NewInt(value), nil
+	case uint64:
+		return new(big.Int).SetUint64(value), nil
+	case float64:
+		number, accuracy := big.NewFloat(value).Int(nil)
+		if accuracy != big.Exact {
+			return nil, fmt.Errorf("invalid number %v", value)
+		}
+		return number, nil
+	case json.Number:
+		return typedDataNumber(string(value))
+	case string:
+		str := strings.TrimSpace(value)
+		if str == "" {
+			return big.NewInt(0), nil
+		}
+		// like starknet.js, only 0x prefixed hex and decimal numbers are
+		// numbers: "0123" is 123 and "0b1" a short string
+		if typedDataHexRegexp.MatchString(str) {
+			number, _ := new(big.Int).SetString(str[2:], 16)
+			return number, nil
+		}
+		if typedDataDecimalRegexp.MatchString(str) {
+			number, _ := new(big.Int).SetString(str, 10)
+			return number, nil
+		}
+		if len(value) > 31 {
+			return nil, fmt.Errorf("%q is longer than a short string", value)
+		}
+		for _, c := range []byte(value) {
+			if c > 127 {
+				return nil, fmt.Errorf("%q is not an ASCII short string", value)
+			}
+		}
+		return new(big.Int).SetBytes([]byte(value)), nil
+	}
+	return nil, fmt.Errorf("invalid number %v", value)
+}
+
+func typedDataNumberInRange(value interface{}, typeName string, max *big.Int) (*big.Int, error) {
+	number, err := typedDataNumber(value)
+	if err != nil {
+		return nil, err
+	}
+	if number.Sign() < 0 || number.Cmp(max) > 0 {
+		return nil, fmt.Errorf("%s is out of the range of %s", number, typeName)
+	}
+	return number, nil
+}
+
+// typedDataStruct returns the fields of value, a map of strings.
+func typedDataStruct(value interface{}) (TypedDataMessage, error) {
+	switch value := value.(type) {
+	case TypedDataMessage:
+		return value, nil
+	case map[string]interface{}:
+		return value, nil
+	}
+	rv := reflect.ValueOf(value)
+	if rv.Kind() != reflect.Map || rv.Type().Key().Kind() != reflect.String {
+		return nil, fmt.Errorf("invalid struct %v", value)
+	}
+	data := make(TypedDataMessage, rv.Len())
+	for _, k := range rv.MapKeys() {
+		data[k.String()] = rv.MapIndex(k).Interface()
+	}
+	return data, nil
+}
+
+// typedDataList returns the entries of value, a slice or an array.
+func typedDataList(value interface{}) ([]interface{}, error) {
+	if entries, ok := value.([]interface{}); ok {
+		return entries, nil
+	}
+	rv := reflect.ValueOf(value)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return nil, fmt.Errorf("invalid array %v", value)
+	}
+	entries := make([]interface{}, rv.Len())
+	for i := range entries {
+		entries[i] = rv.Index(i).Interface()
+	}
+	return entries, nil
+}
+
+/*
+Verifies the [r, s] signature of td by the Stark key publicKey of the account
+at accountAddress, without calling the account. The signatures of accounts
+checking other signatures, e.g. multisig accounts, are not supported.
+*/
+func VerifyTypedData(td *TypedData, accountAddress, publicKey *felt.Felt, signature []*felt.Felt) (bool, error) {
+	if len(signature) != 2 {
+		return false, fmt.Errorf("unsupported signature of %d elements", len(signature))
+	}
+	hash, err := td.GetMessageHash(accountAddress.BigInt(new(big.Int)), td.Message, Curve)
+	if err != nil {
+		return false, err
+	}
+	x := publicKey.BigInt(new(big.Int))
+	y := Curve.GetYCoordinate(x)
+	if y == nil {
+		return false, fmt.Errorf("invalid public key %s", publicKey)
+	}
+	return Curve.Verify(hash, signature[0].BigInt(new(big.Int)), signature[1].BigInt(new(big.Int)), x, y), nil
+}
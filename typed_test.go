@@ -1,10 +1,15 @@
 package starknetgo
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"math/big"
+	"os"
+	"strings"
 	"testing"
 
+	"github.com/NethermindEth/juno/core/felt"
 	"github.com/sjxqqq/starknet-go/types"
 )
 
@@ -34,15 +39,15 @@ func (mail Mail) FmtDefinitionEncoding(field string) (fmtEnc []*big.Int) {
 
 func MockTypedData() (ttd TypedData) {
 	exampleTypes := make(map[string]TypeDef)
-	domDefs := []Definition{{"name", "felt"}, {"version", "felt"}, {"chainId", "felt"}}
-	exampleTypes["StarknetDomain"] = TypeDef{Definitions: domDefs}
-	mailDefs := []Definition{{"from", "Person"}, {"to", "Person"}, {"contents", "felt"}}
+	domDefs := []Definition{{Name: "name", Type: "felt"}, {Name: "version", Type: "felt"}, {Name: "chainId", Type: "felt"}}
+	exampleTypes["StarkNetDomain"] = TypeDef{Definitions: domDefs}
+	mailDefs := []Definition{{Name: "from", Type: "Person"}, {Name: "to", Type: "Person"}, {Name: "contents", Type: "felt"}}
 	exampleTypes["Mail"] = TypeDef{Definitions: mailDefs}
-	persDefs := []Definition{{"name", "felt"}, {"wallet", "felt"}}
+	persDefs := []Definition{{Name: "name", Type: "felt"}, {Name: "wallet", Type: "felt"}}
 	exampleTypes["Person"] = TypeDef{Definitions: persDefs}
 
 	dm := Domain{
-		Name:    "Starknet Mail",
+		Name:    "StarkNet Mail",
 		Version: "1",
 		ChainId: "1",
 	}
@@ -100,7 +105,7 @@ func BenchmarkGetMessageHash(b *testing.B) {
 func TestGeneral_GetDomainHash(t *testing.T) {
 	ttd := MockTypedData()
 
-	hash, err := ttd.GetTypedMessageHash("StarknetDomain", ttd.Domain, Curve)
+	hash, err := ttd.GetTypedMessageHash("StarkNetDomain", ttd.Domain, Curve)
 	if err != nil {
 		t.Errorf("Could not hash message: %v\n", err)
 	}
@@ -141,7 +146,7 @@ func TestGeneral_GetTypedMessageHash(t *testing.T) {
 func TestGeneral_GetTypeHash(t *testing.T) {
 	tdd := MockTypedData()
 
-	hash, err := tdd.GetTypeHash("StarknetDomain")
+	hash, err := tdd.GetTypeHash("StarkNetDomain")
 	if err != nil {
 		t.Errorf("error enccoding type %v\n", err)
 	}
@@ -151,7 +156,7 @@ func TestGeneral_GetTypeHash(t *testing.T) {
 		t.Errorf("type hash: %v does not match expected %v\n", types.BigToHex(hash), exp)
 	}
 
-	enc := tdd.Types["StarknetDomain"]
+	enc := tdd.Types["StarkNetDomain"]
 	if types.BigToHex(enc.Encoding) != exp {
 		t.Errorf("type hash: %v does not match expected %v\n", types.BigToHex(hash), exp)
 	}
@@ -199,3 +204,780 @@ func TestGeneral_EncodeType(t *testing.T) {
 		t.Errorf("type encoding: %v does not match expected %v\n", enc, exp)
 	}
 }
+
+func mockTypedDataRevision1(t *testing.T, extra map[string]TypeDef, primaryType string) TypedData {
+	t.Helper()
+	exampleTypes := map[string]TypeDef{
+		"StarknetDomain": {Definitions: []Definition{
+			{Name: "name", Type: "shortstring"},
+			{Name: "version", Type: "shortstring"},
+			{Name: "chainId", Type: "shortstring"},
+			{Name: "revision", Type: "shortstring"},
+		}},
+	}
+	for name, typeDef := range extra {
+		exampleTypes[name] = typeDef
+	}
+	ttd, err := NewTypedData(exampleTypes, primaryType, Domain{
+		Name:     "StarkNet Mail",
+		Version:  "1",
+		ChainId:  "SN_MAIN",
+		Revision: "1",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ttd
+}
+
+func TestGeneral_TypedDataRevision(t *testing.T) {
+	type testSetType struct {
+		DomainType       string
+		Revision         string
+		ExpectedRevision TypedDataRevision
+		ExpectedError    bool
+	}
+	testSet := map[string][]testSetType{
+		"mock": {
+			{DomainType: "StarkNetDomain", Revision: "", ExpectedRevision: TypedDataRevisionLegacy},
+			{DomainType: "StarkNetDomain", Revision: "0", ExpectedRevision: TypedDataRevisionLegacy},
+			{DomainType: "StarknetDomain", Revision: "1", ExpectedRevision: TypedDataRevisionActive},
+			{DomainType: "StarknetDomain", Revision: "", ExpectedError: true},
+			{DomainType: "StarkNetDomain", Revision: "1", ExpectedError: true},
+		},
+	}[testEnv]
+
+	for _, test := range testSet {
+		ttd := TypedData{
+			Types:  map[string]TypeDef{test.DomainType: {}},
+			Domain: Domain{Revision: test.Revision},
+		}
+		revision, err := ttd.Revision()
+		if test.ExpectedError {
+			if err == nil {
+				t.Fatalf("%s with revision %q should fail", test.DomainType, test.Revision)
+			}
+			continue
+		}
+		if err != nil || revision != test.ExpectedRevision {
+			t.Fatalf("%s with revision %q: expected %d, got %d %v", test.DomainType, test.Revision, test.ExpectedRevision, revision, err)
+		}
+	}
+}
+
+func TestGeneral_EncodeTypeRevision1(t *testing.T) {
+	type testSetType struct {
+		Types        map[string]TypeDef
+		PrimaryType  string
+		ExpectedEnc  string
+		ExpectedHash string
+	}
+	testSet := map[string][]testSetType{
+		"mock": {
+			{
+				PrimaryType:  "StarknetDomain",
+				ExpectedEnc:  `"StarknetDomain"("name":"shortstring","version":"shortstring","chainId":"shortstring","revision":"shortstring")`,
+				ExpectedHash: "0x1ff2f602e42168014d405a94f75e8a93d640751d71d16311266e140d8b0a210",
+			},
+			{
+				Types: map[string]TypeDef{"Example": {Definitions: []Definition{
+					{Name: "n0", Type: "felt"},
+					{Name: "n1", Type: "bool"},
+					{Name: "n2", Type: "string"},
+					{Name: "n3", Type: "selector"},
+					{Name: "n4", Type: "u128"},
+					{Name: "n5", Type: "i128"},
+					{Name: "n6", Type: "ContractAddress"},
+					{Name: "n7", Type: "ClassHash"},
+					{Name: "n8", Type: "timestamp"},
+					{Name: "n9", Type: "shortstring"},
+				}}},
+				PrimaryType: "Example",
+				ExpectedEnc: `"Example"("n0":"felt","n1":"bool","n2":"string","n3":"selector","n4":"u128","n5":"i128","n6":"ContractAddress","n7":"ClassHash","n8":"timestamp","n9":"shortstring")`,
+			},
+			{
+				Types: map[string]TypeDef{"Example": {Definitions: []Definition{
+					{Name: "n0", Type: "TokenAmount"},
+					{Name: "n1", Type: "NftId"},
+				}}},
+				PrimaryType: "Example",
+				ExpectedEnc: `"Example"("n0":"TokenAmount","n1":"NftId")"NftId"("collection_address":"ContractAddress","token_id":"u256")"TokenAmount"("token_address":"ContractAddress","amount":"u256")"u256"("low":"u128","high":"u128")`,
+			},
+			{
+				Types: map[string]TypeDef{
+					"Example": {Definitions: []Definition{
+						{Name: "someEnum1", Type: "enum", Contains: "EnumA"},
+						{Name: "someEnum2", Type: "enum", Contains: "EnumB"},
+					}},
+					"EnumA": {Definitions: []Definition{
+						{Name: "Variant 1", Type: "()"},
+						{Name: "Variant 2", Type: "(u128,u128*)"},
+						{Name: "Variant 3", Type: "(u128)"},
+					}},
+					"EnumB": {Definitions: []Definition{
+						{Name: "Variant 1", Type: "()"},
+						{Name: "Variant 2", Type: "(u128)"},
+					}},
+				},
+				PrimaryType: "Example",
+				ExpectedEnc: `"Example"("someEnum1":"EnumA","someEnum2":"EnumB")"EnumA"("Variant 1":(),"Variant 2":("u128","u128*"),"Variant 3":("u128"))"EnumB"("Variant 1":(),"Variant 2":("u128"))`,
+			},
+			{
+				Types: map[string]TypeDef{
+					"Session": {Definitions: []Definition{
+						{Name: "key", Type: "felt"},
+						{Name: "expires", Type: "timestamp"},
+						{Name: "root", Type: "merkletree", Contains: "Policy"},
+					}},
+					"Policy": {Definitions: []Definition{
+						{Name: "contractAddress", Type: "ContractAddress"},
+						{Name: "selector", Type: "selector"},
+					}},
+				},
+				PrimaryType: "Session",
+				ExpectedEnc: `"Session"("key":"felt","expires":"timestamp","root":"merkletree")`,
+			},
+		},
+	}[testEnv]
+
+	for _, test := range testSet {
+		ttd := mockTypedDataRevision1(t, test.Types, test.PrimaryType)
+		enc, err := ttd.EncodeType(test.PrimaryType)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if enc != test.ExpectedEnc {
+			t.Fatalf("type encoding: %v does not match expected %v", enc, test.ExpectedEnc)
+		}
+		if test.ExpectedHash != "" && types.BigToHex(ttd.Types[test.PrimaryType].Encoding) != test.ExpectedHash {
+			t.Fatalf("type hash: %v does not match expected %v", types.BigToHex(ttd.Types[test.PrimaryType].Encoding), test.ExpectedHash)
+		}
+	}
+}
+
+func TestGeneral_GetStructHashRevision1(t *testing.T) {
+	ttd := mockTypedDataRevision1(t, map[string]TypeDef{
+		"Example": {Definitions: []Definition{
+			{Name: "amount", Type: "u256"},
+			{Name: "text", Type: "string"},
+			{Name: "delta", Type: "i128"},
+			{Name: "choice", Type: "enum", Contains: "Choice"},
+			{Name: "ids", Type: "felt*"},
+			{Name: "root", Type: "merkletree", Contains: "felt"},
+		}},
+		"Choice": {Definitions: []Definition{
+			{Name: "None", Type: "()"},
+			{Name: "Some", Type: "(u128,bool)"},
+		}},
+	}, "Example")
+
+	message := TypedDataMessage{
+		"amount": map[string]interface{}{"low": "0x1", "high": "2"},
+		"text":   "a string longer than thirty one characters",
+		"delta":  -1,
+		"choice": map[string]interface{}{"Some": []interface{}{"0x7", true}},
+		"ids":    []interface{}{"0x1", "0x2"},
+		"root":   []interface{}{"0x3", "0x1", "0x2"},
+	}
+	hash, err := ttd.GetStructHash("Example", message, Curve)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	poseidon := func(elems ...*big.Int) *big.Int {
+		hash, err := Curve.PoseidonHashMany(elems)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return hash
+	}
+	pair := func(x, y int64) *big.Int {
+		hash, err := Curve.PoseidonHash(big.NewInt(x), big.NewInt(y))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return hash
+	}
+	u256Hash := poseidon(types.GetSelectorFromName(`"u256"("low":"u128","high":"u128")`), big.NewInt(1), big.NewInt(2))
+	text := []byte("a string longer than thirty one characters")
+	textHash := poseidon(big.NewInt(1), new(big.Int).SetBytes(text[:31]), new(big.Int).SetBytes(text[31:]), big.NewInt(int64(len(text)-31)))
+	delta := new(big.Int).Sub(Curve.P, big.NewInt(1))
+	choiceHash := poseidon(big.NewInt(1), big.NewInt(7), big.NewInt(1))
+	idsHash := poseidon(big.NewInt(1), big.NewInt(2))
+	// the leaves 3, 1 and 2 are hashed in sorted pairs, the last one with 0
+	left, right := pair(1, 3), pair(0, 2)
+	if left.Cmp(right) > 0 {
+		left, right = right, left
+	}
+	root, err := Curve.PoseidonHash(left, right)
+	if err != nil {
+		t.Fatal(err)
+	}
+	typeHash := types.GetSelectorFromName(`"Example"("amount":"u256","text":"string","delta":"i128","choice":"Choice","ids":"felt*","root":"merkletree")"Choice"("None":(),"Some":("u128","bool"))"u256"("low":"u128","high":"u128")`)
+	expected := poseidon(typeHash, u256Hash, textHash, delta, choiceHash, idsHash, root)
+	if hash.Cmp(expected) != 0 {
+		t.Fatalf("struct hash: %v does not match expected %v", types.BigToHex(hash), types.BigToHex(expected))
+	}
+
+	if _, err := ttd.GetMessageHash(big.NewInt(0x123), message, Curve); err != nil {
+		t.Fatal(err)
+	}
+
+	invalid := map[string]interface{}{
+		"delta":  "0x80000000000000000000000000000000",
+		"amount": map[string]interface{}{"low": "0x100000000000000000000000000000000", "high": "0"},
+		"choice": map[string]interface{}{"Other": []interface{}{}},
+	}
+	for field, value := range invalid {
+		tampered := TypedDataMessage{}
+		for k, v := range message {
+			tampered[k] = v
+		}
+		tampered[field] = value
+		if _, err := ttd.GetStructHash("Example", tampered, Curve); err == nil {
+			t.Fatalf("an invalid %s should fail", field)
+		}
+	}
+}
+
+// TestGeneral_GetMessageHashFixtures checks the message hashes of the typed
+// data fixtures of starknet.js, as copied by starknet.go, with the hashes
+// starknet.js computes for them.
+func TestGeneral_GetMessageHashFixtures(t *testing.T) {
+	type testSetType struct {
+		Name                string
+		ExpectedMessageHash string
+	}
+	testSet := map[string][]testSetType{
+		"mock": {
+			{Name: "example_baseTypes", ExpectedMessageHash: "0xdb7829db8909c0c5496f5952bcfc4fc894341ce01842537fc4f448743480b6"},
+			{Name: "example_presetTypes", ExpectedMessageHash: "0x185b339d5c566a883561a88fb36da301051e2c0225deb325c91bb7aa2f3473a"},
+			{Name: "example_enum", ExpectedMessageHash: "0x6e61abaf480b1370bbf231f54e298c5f4872f40a6d2dd409ff30accee5bbd1e"},
+			{Name: "example_enumNested", ExpectedMessageHash: "0x691fc54567306a8ea5431130f1b98299e74a748ac391540a86736f20ef5f2b7"},
+			{Name: "example_array", ExpectedMessageHash: "0x88edea26d6177a8bc545b2e73c960ab7ddd67b46237b386b514e50315ce0f4"},
+			{Name: "mail_StructArray", ExpectedMessageHash: "0x5914ed2764eca2e6a41eb037feefd3d2e33d9af6225a9e7fe31ac943ff712c"},
+			{Name: "v1Nested", ExpectedMessageHash: "0x69b57cf0cd7c151c51f9616cc58a1f0a877fec28c8c15ff7537cf777c54a30d"},
+			{Name: "allInOne", ExpectedMessageHash: "0x8fa4e453de78c2762493760efd449a38eb46f85b2e02b116b77b3daa9075c8"},
+		},
+	}[testEnv]
+
+	account := types.HexToBN("0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826")
+	for _, test := range testSet {
+		content, err := os.ReadFile("./tests/typedData/" + test.Name + ".json")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var ttd TypedData
+		if err := json.Unmarshal(content, &ttd); err != nil {
+			t.Fatalf("%s: %v", test.Name, err)
+		}
+		hash, err := ttd.GetMessageHash(account, nil, Curve)
+		if err != nil {
+			t.Fatalf("%s: %v", test.Name, err)
+		}
+		if types.BigToHex(hash) != test.ExpectedMessageHash {
+			t.Fatalf("%s: message hash %v does not match expected %v", test.Name, types.BigToHex(hash), test.ExpectedMessageHash)
+		}
+	}
+}
+
+func TestGeneral_TypedDataNumber(t *testing.T) {
+	type testSetType struct {
+		Value    string
+		Expected *big.Int
+	}
+	testSet := map[string][]testSetType{
+		"mock": {
+			{Value: "0x3e8", Expected: big.NewInt(1000)},
+			{Value: "0X3E8", Expected: big.NewInt(1000)},
+			{Value: "1000", Expected: big.NewInt(1000)},
+			{Value: "0123", Expected: big.NewInt(123)},
+			{Value: "-10", Expected: big.NewInt(-10)},
+			{Value: " 42 ", Expected: big.NewInt(42)},
+			{Value: "", Expected: big.NewInt(0)},
+			// not numbers for starknet.js, so short strings
+			{Value: "0b1", Expected: types.UTF8StrToBig("0b1")},
+			{Value: "0o7", Expected: types.UTF8StrToBig("0o7")},
+			{Value: "1_000", Expected: types.UTF8StrToBig("1_000")},
+			{Value: "0x", Expected: types.UTF8StrToBig("0x")},
+			{Value: "transfer", Expected: types.UTF8StrToBig("transfer")},
+		},
+	}[testEnv]
+
+	for _, test := range testSet {
+		number, err := typedDataNumber(test.Value)
+		if err != nil {
+			t.Fatalf("%q: %v", test.Value, err)
+		}
+		if number.Cmp(test.Expected) != 0 {
+			t.Fatalf("%q should be %s, instead: %s", test.Value, test.Expected, number)
+		}
+	}
+}
+
+// TestGeneral_GetMessageHashRevision1 checks the message hashes of revision 1
+// against hashes derived by hand from SNIP-12: the type hashes come from the
+// literal encoded types and every value is encoded independently of typed.go.
+func TestGeneral_GetMessageHashRevision1(t *testing.T) {
+	poseidon := func(elems ...*big.Int) *big.Int {
+		hash, err := Curve.PoseidonHashMany(elems)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return hash
+	}
+	// pair hashes the sorted nodes of a merkletree
+	pair := func(x, y *big.Int) *big.Int {
+		if x.Cmp(y) > 0 {
+			x, y = y, x
+		}
+		hash, err := Curve.PoseidonHash(x, y)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return hash
+	}
+	sel := types.GetSelectorFromName
+	short := types.UTF8StrToBig
+	num := big.NewInt
+
+	domainHash := poseidon(
+		sel(`"StarknetDomain"("name":"shortstring","version":"shortstring","chainId":"shortstring","revision":"shortstring")`),
+		short("StarkNet Mail"), num(1), short("SN_MAIN"), num(1),
+	)
+	account := types.HexToBN("0x123")
+
+	text := []byte("a string longer than thirty one characters")
+	textHash := poseidon(num(1), new(big.Int).SetBytes(text[:31]), new(big.Int).SetBytes(text[31:]), num(int64(len(text)-31)))
+
+	u256Enc := `"u256"("low":"u128","high":"u128")`
+	tokenAmountEnc := `"TokenAmount"("token_address":"ContractAddress","amount":"u256")`
+	nftIdEnc := `"NftId"("collection_address":"ContractAddress","token_id":"u256")`
+	personEnc := `"Person"("name":"shortstring","wallet":"ContractAddress")`
+	policyEnc := `"Policy"("contractAddress":"ContractAddress","selector":"selector")`
+	personHash := func(name string, wallet int64) *big.Int {
+		return poseidon(sel(personEnc), short(name), num(wallet))
+	}
+	policyHash := func(address int64, selector *big.Int) *big.Int {
+		return poseidon(sel(policyEnc), num(address), selector)
+	}
+	policies := []*big.Int{
+		policyHash(1, sel("transfer")),
+		policyHash(2, sel("approve")),
+		policyHash(3, num(0x1234)),
+	}
+
+	type testSetType struct {
+		Name        string
+		Types       string
+		Message     string
+		ExpectedEnc string
+		// ExpectedValues are the encoded values of the fields of the message
+		ExpectedValues []*big.Int
+	}
+	testSet := map[string][]testSetType{
+		"mock": {
+			{
+				Name: "base types",
+				Types: `"Example": [
+					{"name": "n0", "type": "felt"},
+					{"name": "n1", "type": "bool"},
+					{"name": "n2", "type": "string"},
+					{"name": "n3", "type": "selector"},
+					{"name": "n4", "type": "u128"},
+					{"name": "n5", "type": "i128"},
+					{"name": "n6", "type": "ContractAddress"},
+					{"name": "n7", "type": "ClassHash"},
+					{"name": "n8", "type": "timestamp"},
+					{"name": "n9", "type": "shortstring"}
+				]`,
+				Message: `{
+					"n0": "0x3e8",
+					"n1": true,
+					"n2": "a string longer than thirty one characters",
+					"n3": "transfer",
+					"n4": "0x3e8",
+					"n5": "-170141183460469231731687303715884105727",
+					"n6": "0x3e8",
+					"n7": "0x3e8",
+					"n8": 1000,
+					"n9": "transfer"
+				}`,
+				ExpectedEnc: `"Example"("n0":"felt","n1":"bool","n2":"string","n3":"selector","n4":"u128","n5":"i128","n6":"ContractAddress","n7":"ClassHash","n8":"timestamp","n9":"shortstring")`,
+				ExpectedValues: []*big.Int{
+					num(1000), num(1), textHash, sel("transfer"), num(1000),
+					new(big.Int).Sub(Curve.P, typedDataI128Max), num(1000), num(1000), num(1000), short("transfer"),
+				},
+			},
+			{
+				Name: "presets",
+				Types: `"Example": [
+					{"name": "n0", "type": "TokenAmount"},
+					{"name": "n1", "type": "NftId"},
+					{"name": "n2", "type": "u256"}
+				]`,
+				Message: `{
+					"n0": {"token_address": "0x123", "amount": {"low": "0x3e8", "high": "0"}},
+					"n1": {"collection_address": "0x456", "token_id": {"low": "0x1", "high": "0x2"}},
+					"n2": {"low": 5, "high": 0}
+				}`,
+				ExpectedEnc: `"Example"("n0":"TokenAmount","n1":"NftId","n2":"u256")` + nftIdEnc + tokenAmountEnc + u256Enc,
+				ExpectedValues: []*big.Int{
+					poseidon(sel(tokenAmountEnc+u256Enc), num(0x123), poseidon(sel(u256Enc), num(1000), num(0))),
+					poseidon(sel(nftIdEnc+u256Enc), num(0x456), poseidon(sel(u256Enc), num(1), num(2))),
+					poseidon(sel(u256Enc), num(5), num(0)),
+				},
+			},
+			{
+				Name: "enum",
+				Types: `"Example": [
+					{"name": "someEnum1", "type": "enum", "contains": "EnumA"},
+					{"name": "someEnum2", "type": "enum", "contains": "EnumB"}
+				],
+				"EnumA": [
+					{"name": "Variant 1", "type": "()"},
+					{"name": "Variant 2", "type": "(u128,u128*)"},
+					{"name": "Variant 3", "type": "(u128)"}
+				],
+				"EnumB": [
+					{"name": "Variant 1", "type": "()"},
+					{"name": "Variant 2", "type": "(u128)"}
+				]`,
+				Message: `{
+					"someEnum1": {"Variant 2": [2, [0, 1]]},
+					"someEnum2": {"Variant 1": []}
+				}`,
+				ExpectedEnc: `"Example"("someEnum1":"EnumA","someEnum2":"EnumB")"EnumA"("Variant 1":(),"Variant 2":("u128","u128*"),"Variant 3":("u128"))"EnumB"("Variant 1":(),"Variant 2":("u128"))`,
+				ExpectedValues: []*big.Int{
+					poseidon(num(1), num(2), poseidon(num(0), num(1))),
+					poseidon(num(0), num(0)),
+				},
+			},
+			{
+				Name: "arrays",
+				Types: `"Example": [
+					{"name": "people", "type": "Person*"},
+					{"name": "ids", "type": "felt*"},
+					{"name": "notes", "type": "string*"}
+				],
+				"Person": [
+					{"name": "name", "type": "shortstring"},
+					{"name": "wallet", "type": "ContractAddress"}
+				]`,
+				Message: `{
+					"people": [{"name": "Alice", "wallet": "0x1"}, {"name": "Bob", "wallet": "0x2"}],
+					"ids": ["0x1", "0x2", "0x3"],
+					"notes": ["hi", ""]
+				}`,
+				ExpectedEnc: `"Example"("people":"Person*","ids":"felt*","notes":"string*")` + personEnc,
+				ExpectedValues: []*big.Int{
+					poseidon(personHash("Alice", 1), personHash("Bob", 2)),
+					poseidon(num(1), num(2), num(3)),
+					poseidon(poseidon(num(0), short("hi"), num(2)), poseidon(num(0), num(0), num(0))),
+				},
+			},
+			{
+				Name: "merkletree",
+				Types: `"Session": [
+					{"name": "key", "type": "felt"},
+					{"name": "expires", "type": "timestamp"},
+					{"name": "root", "type": "merkletree", "contains": "Policy"}
+				],
+				"Policy": [
+					{"name": "contractAddress", "type": "ContractAddress"},
+					{"name": "selector", "type": "selector"}
+				]`,
+				Message: `{
+					"key": "0x7",
+					"expires": 1700000000,
+					"root": [
+						{"contractAddress": "0x1", "selector": "transfer"},
+						{"contractAddress": "0x2", "selector": "approve"},
+						{"contractAddress": "0x3", "selector": "0x1234"}
+					]
+				}`,
+				ExpectedEnc: `"Session"("key":"felt","expires":"timestamp","root":"merkletree")`,
+				ExpectedValues: []*big.Int{
+					num(7), num(1700000000),
+					// the odd leaf is hashed with 0
+					pair(pair(policies[0], policies[1]), pair(policies[2], num(0))),
+				},
+			},
+		},
+	}[testEnv]
+
+	for _, test := range testSet {
+		primaryType := strings.SplitN(strings.TrimSpace(test.Types), `"`, 3)[1]
+		content := fmt.Sprintf(`{
+			"types": {
+				"StarknetDomain": [
+					{"name": "name", "type": "shortstring"},
+					{"name": "version", "type": "shortstring"},
+					{"name": "chainId", "type": "shortstring"},
+					{"name": "revision", "type": "shortstring"}
+				],
+				%s
+			},
+			"primaryType": %q,
+			"domain": {"name": "StarkNet Mail", "version": "1", "chainId": "SN_MAIN", "revision": "1"},
+			"message": %s
+		}`, test.Types, primaryType, test.Message)
+		var ttd TypedData
+		if err := json.Unmarshal([]byte(content), &ttd); err != nil {
+			t.Fatalf("%s: %v", test.Name, err)
+		}
+
+		enc, err := ttd.EncodeType(primaryType)
+		if err != nil {
+			t.Fatalf("%s: %v", test.Name, err)
+		}
+		if enc != test.ExpectedEnc {
+			t.Fatalf("%s: type encoding %v does not match expected %v", test.Name, enc, test.ExpectedEnc)
+		}
+
+		expectedStructHash := poseidon(append([]*big.Int{sel(test.ExpectedEnc)}, test.ExpectedValues...)...)
+		structHash, err := ttd.GetStructHash(primaryType, ttd.Message.(TypedDataMessage), Curve)
+		if err != nil {
+			t.Fatalf("%s: %v", test.Name, err)
+		}
+		if structHash.Cmp(expectedStructHash) != 0 {
+			t.Fatalf("%s: struct hash %v does not match expected %v", test.Name, types.BigToHex(structHash), types.BigToHex(expectedStructHash))
+		}
+
+		expected := poseidon(short("StarkNet Message"), domainHash, account, expectedStructHash)
+		hash, err := ttd.GetMessageHash(account, nil, Curve)
+		if err != nil {
+			t.Fatalf("%s: %v", test.Name, err)
+		}
+		if hash.Cmp(expected) != 0 {
+			t.Fatalf("%s: message hash %v does not match expected %v", test.Name, types.BigToHex(hash), types.BigToHex(expected))
+		}
+	}
+}
+
+func TestGeneral_SelectorRevision1(t *testing.T) {
+	ttd := mockTypedDataRevision1(t, map[string]TypeDef{
+		"Call": {Definitions: []Definition{{Name: "selector", Type: "selector"}}},
+	}, "Call")
+
+	transfer := types.GetSelectorFromName("transfer")
+	type testSetType struct {
+		Selector         string
+		ExpectedSelector *big.Int
+	}
+	testSet := map[string][]testSetType{
+		"mock": {
+			{Selector: "transfer", ExpectedSelector: transfer},
+			{Selector: types.BigToHex(transfer), ExpectedSelector: transfer},
+			{Selector: "0X1", ExpectedSelector: big.NewInt(1)},
+			{Selector: "0xtransfer", ExpectedSelector: types.GetSelectorFromName("0xtransfer")},
+		},
+	}[testEnv]
+
+	for _, test := range testSet {
+		hash, err := ttd.GetStructHash("Call", TypedDataMessage{"selector": test.Selector}, Curve)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected, err := Curve.PoseidonHashMany([]*big.Int{ttd.Types["Call"].Encoding, test.ExpectedSelector})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if hash.Cmp(expected) != 0 {
+			t.Fatalf("struct hash of selector %s: %v does not match expected %v", test.Selector, types.BigToHex(hash), types.BigToHex(expected))
+		}
+	}
+}
+
+func TestGeneral_GetStructHashRevision0(t *testing.T) {
+	ttd := MockTypedData()
+
+	mail := TypedDataMessage{
+		"from": map[string]interface{}{
+			"name":   "Cow",
+			"wallet": "0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826",
+		},
+		"to": map[string]interface{}{
+			"name":   "Bob",
+			"wallet": "0xbBbBBBBbbBBBbbbBbbBbbbbBBbBbbbbBbBbbBBbB",
+		},
+		"contents": "Hello, Bob!",
+	}
+
+	hash, err := ttd.GetStructHash("Mail", mail, Curve)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := "0x4758f1ed5e7503120c228cbcaba626f61514559e9ef5ed653b0b885e0f38aec"
+	if types.BigToHex(hash) != exp {
+		t.Errorf("struct hash: %v does not match expected %v\n", types.BigToHex(hash), exp)
+	}
+
+	hash, err = ttd.GetMessageHash(types.HexToBN("0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826"), mail, Curve)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp = "0x6fcff244f63e38b9d88b9e3378d44757710d1b244282b435cb472053c8d78d0"
+	if types.BigToHex(hash) != exp {
+		t.Errorf("message hash: %v does not match expected %v\n", types.BigToHex(hash), exp)
+	}
+}
+
+const mockTypedDataJSON = `{
+	"types": {
+		"StarkNetDomain": [
+			{"name": "name", "type": "felt"},
+			{"name": "version", "type": "felt"},
+			{"name": "chainId", "type": "felt"}
+		],
+		"Person": [
+			{"name": "name", "type": "felt"},
+			{"name": "wallet", "type": "felt"}
+		],
+		"Mail": [
+			{"name": "from", "type": "Person"},
+			{"name": "to", "type": "Person"},
+			{"name": "contents", "type": "felt"}
+		]
+	},
+	"primaryType": "Mail",
+	"domain": {"name": "StarkNet Mail", "version": "1", "chainId": 1},
+	"message": {
+		"from": {"name": "Cow", "wallet": "0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826"},
+		"to": {"name": "Bob", "wallet": "0xbBbBBBBbbBBBbbbBbbBbbbbBBbBbbbbBbBbbBBbB"},
+		"contents": "Hello, Bob!"
+	}
+}`
+
+func TestGeneral_TypedDataJSON(t *testing.T) {
+	var ttd TypedData
+	if err := json.Unmarshal([]byte(mockTypedDataJSON), &ttd); err != nil {
+		t.Fatal(err)
+	}
+	if ttd.Domain.ChainId != "1" {
+		t.Errorf("chain id: %v does not match expected 1\n", ttd.Domain.ChainId)
+	}
+
+	exp := "0x6fcff244f63e38b9d88b9e3378d44757710d1b244282b435cb472053c8d78d0"
+	hash, err := ttd.GetMessageHash(types.HexToBN("0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826"), nil, Curve)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if types.BigToHex(hash) != exp {
+		t.Errorf("message hash: %v does not match expected %v\n", types.BigToHex(hash), exp)
+	}
+
+	content, err := json.Marshal(ttd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded TypedData
+	if err := json.Unmarshal(content, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	hash, err = decoded.GetMessageHash(types.HexToBN("0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826"), nil, Curve)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if types.BigToHex(hash) != exp {
+		t.Errorf("message hash after round trip: %v does not match expected %v\n", types.BigToHex(hash), exp)
+	}
+
+	for _, content := range []string{
+		`{"types": {}, "primaryType": "Mail", "domain": {"name": "StarkNet Mail"}}`,
+		`{"types": {"StarkNetDomain": []}, "primaryType": "Mail", "domain": {"revision": 2}}`,
+		`{"types": {"StarkNetDomain": []}, "primaryType": "Mail", "domain": {"name": ["StarkNet Mail"]}}`,
+	} {
+		if err := json.Unmarshal([]byte(content), &decoded); err == nil {
+			t.Errorf("decoding %s should fail", content)
+		}
+	}
+}
+
+func TestGeneral_TypedDataJSONRevision1(t *testing.T) {
+	content := `{
+		"types": {
+			"StarknetDomain": [
+				{"name": "name", "type": "shortstring"},
+				{"name": "version", "type": "shortstring"},
+				{"name": "chainId", "type": "shortstring"},
+				{"name": "revision", "type": "shortstring"}
+			],
+			"Login": [
+				{"name": "Nonce", "type": "u128"},
+				{"name": "Expires", "type": "timestamp"}
+			]
+		},
+		"primaryType": "Login",
+		"domain": {"name": "dapp", "version": "1", "chainId": "SN_MAIN", "revision": "1"},
+		"message": {"Nonce": 42, "Expires": "1700000000"}
+	}`
+	var ttd TypedData
+	if err := json.Unmarshal([]byte(content), &ttd); err != nil {
+		t.Fatal(err)
+	}
+	revision, err := ttd.Revision()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if revision != TypedDataRevisionActive {
+		t.Fatalf("revision: %v does not match expected %v", revision, TypedDataRevisionActive)
+	}
+	hash, err := ttd.GetMessageHash(big.NewInt(1), nil, Curve)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the message of the JSON hashes like the one built in Go
+	expected, err := ttd.GetMessageHash(big.NewInt(1), TypedDataMessage{
+		"Nonce":   big.NewInt(42),
+		"Expires": big.NewInt(1700000000),
+	}, Curve)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hash.Cmp(expected) != 0 {
+		t.Errorf("message hash: %v does not match expected %v\n", types.BigToHex(hash), types.BigToHex(expected))
+	}
+}
+
+func TestAccount_SignTypedData(t *testing.T) {
+	account, _ := newMockRPCAccount(t)
+	var ttd TypedData
+	if err := json.Unmarshal([]byte(mockTypedDataJSON), &ttd); err != nil {
+		t.Fatal(err)
+	}
+	signature, err := account.SignTypedData(context.Background(), &ttd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	x, _, err := Curve.PrivateToPoint(types.HexToBN(mockAccountPrivateKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicKey := new(felt.Felt).SetBytes(x.Bytes())
+
+	valid, err := VerifyTypedData(&ttd, account.AccountAddress, publicKey, signature)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !valid {
+		t.Fatal("signature should be valid")
+	}
+
+	tampered := ttd
+	tampered.Message = TypedDataMessage{
+		"from":     ttd.Message.(TypedDataMessage)["from"],
+		"to":       ttd.Message.(TypedDataMessage)["to"],
+		"contents": "Hello, Eve!",
+	}
+	if valid, err := VerifyTypedData(&tampered, account.AccountAddress, publicKey, signature); err != nil || valid {
+		t.Fatalf("signature of another message should be invalid, instead: %v, %v", valid, err)
+	}
+	if valid, err := VerifyTypedData(&ttd, new(felt.Felt).SetUint64(1), publicKey, signature); err != nil || valid {
+		t.Fatalf("signature for another account should be invalid, instead: %v, %v", valid, err)
+	}
+	if _, err := VerifyTypedData(&ttd, account.AccountAddress, publicKey, signature[:1]); err == nil {
+		t.Fatal("signature of 1 element should fail")
+	}
+}
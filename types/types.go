@@ -12,6 +12,10 @@ type NumAsHex string
 
 type AddInvokeTransactionOutput struct {
 	TransactionHash *felt.Felt `json:"transaction_hash"`
+	// MaxFee the max fee the transaction was sent with
+	MaxFee *big.Int `json:"max_fee,omitempty"`
+	// FeeReason explains how the max fee was chosen
+	FeeReason string `json:"fee_reason,omitempty"`
 }
 
 type AddDeclareResponse struct {
@@ -94,6 +98,50 @@ type FeeEstimate struct {
 type ExecuteDetails struct {
 	MaxFee *big.Int
 	Nonce  *big.Int
+
+	// The fields below are only used by version 3 transactions, which pay the
+	// fee in STRK within ResourceBounds instead of MaxFee.
+	ResourceBounds            *ResourceBoundsMapping
+	Tip                       uint64
+	PaymasterData             []*felt.Felt
+	AccountDeploymentData     []*felt.Felt
+	NonceDataAvailabilityMode DataAvailabilityMode
+	FeeDataAvailabilityMode   DataAvailabilityMode
+}
+
+// ResourceBounds is the maximum amount of a resource a transaction can use and
+// the maximum price it pays per unit.
+type ResourceBounds struct {
+	MaxAmount       uint64
+	MaxPricePerUnit *big.Int
+}
+
+// ResourceBoundsMapping holds the bounds of every resource of a version 3
+// transaction.
+type ResourceBoundsMapping struct {
+	L1Gas ResourceBounds
+	L2Gas ResourceBounds
+}
+
+// DataAvailabilityMode is the storage domain of the account nonce or balance.
+type DataAvailabilityMode uint32
+
+const (
+	DAModeL1 DataAvailabilityMode = iota
+	DAModeL2
+)
+
+// DeployOptions configures a deployment through the Universal Deployer
+// Contract (UDC) or a deployer contract with the same interface.
+type DeployOptions struct {
+	// Salt of the contract address. A random salt is used when nil.
+	Salt *felt.Felt
+	// Unique makes the contract address depend on the deploying account.
+	Unique bool
+	// ConstructorCalldata is passed as is to the contract constructor.
+	ConstructorCalldata []*felt.Felt
+	// DeployerAddress overrides the address of the UDC.
+	DeployerAddress *felt.Felt
 }
 
 type TransactionState string
@@ -4,6 +4,8 @@ import (
 	"crypto/rand"
 	"fmt"
 	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc/stark-curve/fr"
 )
 
 // obtain random primary key on stark curve
@@ -33,6 +35,13 @@ func (sc StarkCurve) PrivateToPoint(privKey *big.Int) (x, y *big.Int, err error)
 	if privKey.Cmp(big.NewInt(0)) != 1 || privKey.Cmp(sc.N) != -1 {
 		return x, y, fmt.Errorf("private key not in curve range")
 	}
+	if sc.usesECBackend() {
+		var k fr.Element
+		k.SetBigInt(privKey)
+		point := scalarBaseMult(&k)
+		x, y = point.affine()
+		return x, y, nil
+	}
 	x, y = sc.EcMult(privKey, sc.EcGenX, sc.EcGenY)
 	return x, y, nil
 }